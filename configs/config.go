@@ -9,6 +9,8 @@ package configs
 import (
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/spf13/viper"
+
+	"zewise.space/backend/utils/signers"
 )
 
 // Config 配置文件对象
@@ -65,6 +67,62 @@ type Config struct {
 		Port int `toml:"port"`
 	} `toml:"search_service"`
 
+	// 认证设置
+	Auth struct {
+		// 登录日志保留天数
+		LoginLogRetentionDays int `toml:"login_log_retention_days" mapstructure:"login_log_retention_days"`
+		// argon2id 内存开销（KiB）
+		Argon2Memory uint32 `toml:"argon2_memory" mapstructure:"argon2_memory"`
+		// argon2id 迭代次数
+		Argon2Iterations uint32 `toml:"argon2_iterations" mapstructure:"argon2_iterations"`
+		// argon2id 并行度
+		Argon2Parallelism uint8 `toml:"argon2_parallelism" mapstructure:"argon2_parallelism"`
+	} `toml:"auth"`
+
+	// 注册设置
+	Registration struct {
+		// 注册模式 open, invite, closed
+		Mode string `toml:"mode"`
+		// 可生成邀请码的最低用户等级 拥有邀请码管理权限的用户不受此限制
+		InviteMinLevel uint64 `toml:"invite_min_level" mapstructure:"invite_min_level"`
+	} `toml:"registration"`
+
+	// 账号注销设置
+	AccountDeletion struct {
+		// 注销冷静期天数 冷静期内登录即撤销注销
+		GraceDays int `toml:"grace_days" mapstructure:"grace_days"`
+		// 清除账号时博文与评论的处理方式 anonymize: 保留内容并去除个人信息, remove: 删除内容与媒体文件
+		ContentPolicy string `toml:"content_policy" mapstructure:"content_policy"`
+	} `toml:"account_deletion" mapstructure:"account_deletion"`
+
+	// 管理设置
+	Admin struct {
+		// 启动时授予管理员角色的用户ID 用于创建首个管理员
+		BootstrapUserIDs []string `toml:"bootstrap_user_ids" mapstructure:"bootstrap_user_ids"`
+	} `toml:"admin"`
+
+	// 令牌签名设置 轮换时新增密钥并切换 active_kid 旧密钥保留至其签发的令牌全部过期
+	Token struct {
+		// 当前用于签名的密钥 ID
+		ActiveKID string `toml:"active_kid" mapstructure:"active_kid"`
+		// 签名密钥列表
+		Keys []signers.KeyConfig `toml:"keys"`
+	} `toml:"token"`
+
+	// 邮件设置 密码通过环境变量 MAIL_PASSWORD 提供
+	Mail struct {
+		// 发送方式 smtp, fake
+		Driver string `toml:"driver"`
+		// SMTP 服务器地址
+		Host string `toml:"host"`
+		// SMTP 服务器端口
+		Port int `toml:"port"`
+		// SMTP 用户名
+		Username string `toml:"username"`
+		// 发件人地址
+		From string `toml:"from"`
+	} `toml:"mail"`
+
 	// 压缩设置
 	Compress struct {
 		// 压缩等级
@@ -0,0 +1,39 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明账号注销相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// ACCOUNT_DELETION_POLICY_ANONYMIZE 清除账号时保留博文与评论 仅去除其中的个人信息
+	ACCOUNT_DELETION_POLICY_ANONYMIZE = "anonymize"
+
+	// ACCOUNT_DELETION_POLICY_REMOVE 清除账号时一并删除博文、评论与媒体文件
+	ACCOUNT_DELETION_POLICY_REMOVE = "remove"
+
+	// ACCOUNT_DELETION_GRACE_DAYS 未配置时注销冷静期的默认天数
+	ACCOUNT_DELETION_GRACE_DAYS = 30
+
+	// ACCOUNT_PURGE_INTERVAL 清除任务的执行间隔
+	ACCOUNT_PURGE_INTERVAL = 60 * 60
+
+	// ACCOUNT_PURGE_LOCK_DURATION 清除任务锁的有效期
+	ACCOUNT_PURGE_LOCK_DURATION = 30 * 60
+
+	// ACCOUNT_PURGE_LOCK_TOKEN_LENGTH 清除任务锁持有者令牌长度
+	ACCOUNT_PURGE_LOCK_TOKEN_LENGTH = 32
+
+	// ACCOUNT_PURGE_BATCH_SIZE 单次清除任务处理的最大账号数量
+	ACCOUNT_PURGE_BATCH_SIZE = 100
+
+	// ACCOUNT_PURGE_RETRY_INTERVAL 账号清除失败后首次重试的间隔 此后每次失败间隔翻倍
+	ACCOUNT_PURGE_RETRY_INTERVAL = 60 * 60
+
+	// ACCOUNT_PURGE_RETRY_MAX_INTERVAL 账号清除失败后重试的最大间隔
+	ACCOUNT_PURGE_RETRY_MAX_INTERVAL = 7 * 24 * 60 * 60
+
+	// DELETED_USER_NAME 匿名化后的评论与回复中显示的用户名
+	DELETED_USER_NAME = "已注销用户"
+)
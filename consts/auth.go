@@ -0,0 +1,36 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于定义认证相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// LOGIN_LOG_RETENTION_DAYS 未配置时登录日志的默认保留天数
+	LOGIN_LOG_RETENTION_DAYS = 90
+
+	// LOGIN_FAILURE_WINDOW 登录失败计数的统计窗口
+	LOGIN_FAILURE_WINDOW = 15 * 60
+
+	// LOGIN_DELAY_THRESHOLD 账号连续失败达到该次数后 每次失败需等待一段时间才能再次尝试
+	LOGIN_DELAY_THRESHOLD = 3
+
+	// LOGIN_DELAY_MAX 单次失败后的最长等待时间
+	LOGIN_DELAY_MAX = 30
+
+	// ACCOUNT_LOCK_THRESHOLD 账号连续失败达到该次数后临时锁定
+	ACCOUNT_LOCK_THRESHOLD = 10
+
+	// ACCOUNT_LOCK_DURATION 账号锁定时长
+	ACCOUNT_LOCK_DURATION = 30 * 60
+
+	// IP_LOCK_THRESHOLD 同一 IP 失败达到该次数后临时禁止其登录
+	IP_LOCK_THRESHOLD = 50
+
+	// IP_LOCK_DURATION IP 锁定时长
+	IP_LOCK_DURATION = 30 * 60
+
+	// ACCOUNT_UNLOCK_TOKEN_LENGTH 账号解锁令牌长度
+	ACCOUNT_UNLOCK_TOKEN_LENGTH = 32
+)
@@ -0,0 +1,36 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明图片验证码相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// CAPTCHA_CHARSET 验证码字符集 去除了易混淆的字符
+	CAPTCHA_CHARSET = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+	// CAPTCHA_LENGTH 验证码字符数
+	CAPTCHA_LENGTH = 5
+
+	// CAPTCHA_ID_LENGTH 验证码ID长度
+	CAPTCHA_ID_LENGTH = 32
+
+	// CAPTCHA_EXPIRE_DURATION 验证码有效期
+	CAPTCHA_EXPIRE_DURATION = 300
+
+	// CAPTCHA_IMAGE_WIDTH 验证码图片宽度
+	CAPTCHA_IMAGE_WIDTH = 200
+
+	// CAPTCHA_IMAGE_HEIGHT 验证码图片高度
+	CAPTCHA_IMAGE_HEIGHT = 70
+
+	// CAPTCHA_IMAGE_QUALITY 验证码图片质量
+	CAPTCHA_IMAGE_QUALITY = 80
+
+	// CAPTCHA_LOGIN_FAILURE_THRESHOLD 账号登录失败达到该次数后 登录需通过验证码
+	CAPTCHA_LOGIN_FAILURE_THRESHOLD = 3
+
+	// CAPTCHA_IP_FAILURE_THRESHOLD 同一 IP 登录失败达到该次数后 该 IP 登录任意账号均需通过验证码
+	CAPTCHA_IP_FAILURE_THRESHOLD = 10
+)
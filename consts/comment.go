@@ -0,0 +1,12 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于定义评论相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// COMMENT_CONTENT_MAX_LENGTH 评论与回复内容最大长度
+	COMMENT_CONTENT_MAX_LENGTH = 1000
+)
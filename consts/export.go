@@ -0,0 +1,36 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明个人数据导出相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// DATA_EXPORT_STATUS_PENDING 导出归档生成中
+	DATA_EXPORT_STATUS_PENDING = "pending"
+
+	// DATA_EXPORT_STATUS_READY 导出归档已生成 可下载
+	DATA_EXPORT_STATUS_READY = "ready"
+
+	// DATA_EXPORT_STATUS_FAILED 导出归档生成失败
+	DATA_EXPORT_STATUS_FAILED = "failed"
+
+	// DATA_EXPORT_STATUS_EXPIRED 导出归档已过期 仅用于响应 不写入数据库
+	DATA_EXPORT_STATUS_EXPIRED = "expired"
+
+	// DATA_EXPORT_COOLDOWN 两次成功导出之间的最短间隔
+	DATA_EXPORT_COOLDOWN = 24 * 60 * 60
+
+	// DATA_EXPORT_BUILD_TIMEOUT 生成导出归档的最长时间 超时仍未完成视为失败
+	DATA_EXPORT_BUILD_TIMEOUT = 30 * 60
+
+	// DATA_EXPORT_RETENTION_DAYS 导出归档的保留天数
+	DATA_EXPORT_RETENTION_DAYS = 7
+
+	// DATA_EXPORT_LINK_EXPIRE_DURATION 下载链接的有效期
+	DATA_EXPORT_LINK_EXPIRE_DURATION = 60 * 60
+
+	// DATA_EXPORT_CONTENT_TYPE 导出归档的文件类型
+	DATA_EXPORT_CONTENT_TYPE = "application/zip"
+)
@@ -0,0 +1,41 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明注册与邀请码相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// REGISTRATION_MODE_OPEN 开放注册
+	REGISTRATION_MODE_OPEN = "open"
+
+	// REGISTRATION_MODE_INVITE 仅限邀请注册
+	REGISTRATION_MODE_INVITE = "invite"
+
+	// REGISTRATION_MODE_CLOSED 关闭注册
+	REGISTRATION_MODE_CLOSED = "closed"
+)
+
+const (
+	// INVITE_CODE_LENGTH 邀请码长度
+	INVITE_CODE_LENGTH = 12
+
+	// INVITE_MIN_LEVEL 默认可生成邀请码的最低用户等级
+	INVITE_MIN_LEVEL = 5
+
+	// INVITE_CODE_DEFAULT_MAX_USES 邀请码默认可使用次数
+	INVITE_CODE_DEFAULT_MAX_USES = 1
+
+	// INVITE_CODE_MAX_USES 普通用户生成的邀请码最大可使用次数
+	INVITE_CODE_MAX_USES = 10
+
+	// INVITE_CODE_DEFAULT_EXPIRE_DAYS 邀请码默认有效天数
+	INVITE_CODE_DEFAULT_EXPIRE_DAYS = 7
+
+	// INVITE_CODE_MAX_EXPIRE_DAYS 邀请码最大有效天数
+	INVITE_CODE_MAX_EXPIRE_DAYS = 90
+
+	// MAX_ACTIVE_INVITE_CODES_PER_USER 普通用户同时持有的有效邀请码数量上限
+	MAX_ACTIVE_INVITE_CODES_PER_USER = 5
+)
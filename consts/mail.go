@@ -0,0 +1,30 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明邮件相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// MAIL_VERIFY_TOKEN_LENGTH 邮箱验证令牌长度
+	MAIL_VERIFY_TOKEN_LENGTH = 32
+
+	// MAIL_VERIFY_TOKEN_EXPIRE_DURATION 邮箱验证令牌有效期
+	MAIL_VERIFY_TOKEN_EXPIRE_DURATION = 30 * 60
+
+	// EMAIL_CHANGE_TOKEN_LENGTH 修改邮箱确认令牌长度
+	EMAIL_CHANGE_TOKEN_LENGTH = 32
+
+	// EMAIL_CHANGE_TOKEN_EXPIRE_DURATION 修改邮箱确认令牌有效期
+	EMAIL_CHANGE_TOKEN_EXPIRE_DURATION = 30 * 60
+
+	// PASSWORD_RESET_TOKEN_LENGTH 密码重置令牌长度
+	PASSWORD_RESET_TOKEN_LENGTH = 32
+
+	// PASSWORD_RESET_TOKEN_EXPIRE_DURATION 密码重置令牌有效期
+	PASSWORD_RESET_TOKEN_EXPIRE_DURATION = 15 * 60
+
+	// MAIL_SEND_INTERVAL 同一用户两次发送邮件的最小间隔
+	MAIL_SEND_INTERVAL = 60
+)
@@ -0,0 +1,36 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于定义媒体文件相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// MEDIA_URL_PREFIX 媒体文件 URL 前缀
+	MEDIA_URL_PREFIX = "/resource/media/"
+
+	// MEDIA_THUMBNAIL_SUFFIX 缩略图文件名后缀
+	MEDIA_THUMBNAIL_SUFFIX = "_thumb"
+
+	// MEDIA_MAX_FILE_SIZE 媒体文件最大字节数
+	MEDIA_MAX_FILE_SIZE = 10 * 1024 * 1024 // 10 MB
+
+	// MEDIA_MAX_SIZE 媒体图片最大尺寸
+	MEDIA_MAX_SIZE = 8192
+
+	// MEDIA_DISPLAY_SIZE 展示图尺寸
+	MEDIA_DISPLAY_SIZE = 2048
+
+	// MEDIA_THUMBNAIL_SIZE 缩略图尺寸
+	MEDIA_THUMBNAIL_SIZE = 360
+
+	// MEDIA_QUALITY 展示图质量
+	MEDIA_QUALITY = 85
+
+	// MEDIA_THUMBNAIL_QUALITY 缩略图质量
+	MEDIA_THUMBNAIL_QUALITY = 70
+
+	// MAX_MEDIA_PER_POST 单篇博文最大媒体数量
+	MAX_MEDIA_PER_POST = 9
+)
@@ -0,0 +1,36 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明密码哈希相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// ARGON2_MEMORY argon2id 默认内存开销（KiB）
+	ARGON2_MEMORY = 64 * 1024
+
+	// ARGON2_ITERATIONS argon2id 默认迭代次数
+	ARGON2_ITERATIONS = 3
+
+	// ARGON2_PARALLELISM argon2id 默认并行度
+	ARGON2_PARALLELISM = 2
+
+	// ARGON2_SALT_LENGTH argon2id 盐长度（字节）
+	ARGON2_SALT_LENGTH = 16
+
+	// ARGON2_KEY_LENGTH argon2id 哈希长度（字节）
+	ARGON2_KEY_LENGTH = 32
+
+	// ARGON2_MAX_MEMORY argon2id 允许的最大内存开销（KiB）
+	ARGON2_MAX_MEMORY = 1024 * 1024
+
+	// ARGON2_MAX_ITERATIONS argon2id 允许的最大迭代次数
+	ARGON2_MAX_ITERATIONS = 16
+
+	// ARGON2_MAX_PARALLELISM argon2id 允许的最大并行度
+	ARGON2_MAX_PARALLELISM = 16
+
+	// ARGON2_MAX_KEY_LENGTH argon2id 允许的最大哈希长度（字节）
+	ARGON2_MAX_KEY_LENGTH = 128
+)
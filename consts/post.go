@@ -0,0 +1,21 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于定义博文相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// POST_TITLE_MAX_LENGTH 博文标题最大长度
+	POST_TITLE_MAX_LENGTH = 128
+
+	// POST_CONTENT_MAX_LENGTH 博文内容最大长度
+	POST_CONTENT_MAX_LENGTH = 20000
+
+	// PAGE_SIZE_DEFAULT 默认分页大小
+	PAGE_SIZE_DEFAULT = 20
+
+	// PAGE_SIZE_MAX 最大分页大小
+	PAGE_SIZE_MAX = 50
+)
@@ -0,0 +1,91 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明角色与权限常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// ROLE_USER 普通用户 即 UserInfo.Authority 的默认值
+	ROLE_USER uint64 = 0
+
+	// ROLE_MODERATOR 版主
+	ROLE_MODERATOR uint64 = 1
+
+	// ROLE_ADMIN 管理员
+	ROLE_ADMIN uint64 = 2
+)
+
+const (
+	// PERMISSION_USER_MODERATE 处理违规用户 包括查询 暂停使用与强制登出
+	PERMISSION_USER_MODERATE = "user:moderate"
+
+	// PERMISSION_USER_BAN 永久封禁与解除封禁用户
+	PERMISSION_USER_BAN = "user:ban"
+
+	// PERMISSION_ROLE_MANAGE 授予与撤销角色
+	PERMISSION_ROLE_MANAGE = "role:manage"
+
+	// PERMISSION_AUDIT_READ 查看审计日志
+	PERMISSION_AUDIT_READ = "audit:read"
+
+	// PERMISSION_INVITE_MANAGE 不受等级与数量限制地生成邀请码
+	PERMISSION_INVITE_MANAGE = "invite:manage"
+)
+
+// ROLE_NAMES 角色名称
+var ROLE_NAMES = map[uint64]string{
+	ROLE_USER:      "user",
+	ROLE_MODERATOR: "moderator",
+	ROLE_ADMIN:     "admin",
+}
+
+// ROLE_PERMISSIONS 各角色拥有的权限
+var ROLE_PERMISSIONS = map[uint64][]string{
+	ROLE_USER: {},
+	ROLE_MODERATOR: {
+		PERMISSION_USER_MODERATE,
+	},
+	ROLE_ADMIN: {
+		PERMISSION_USER_MODERATE,
+		PERMISSION_USER_BAN,
+		PERMISSION_ROLE_MANAGE,
+		PERMISSION_AUDIT_READ,
+		PERMISSION_INVITE_MANAGE,
+	},
+}
+
+const (
+	// AUDIT_ACTION_ROLE_GRANT 授予角色
+	AUDIT_ACTION_ROLE_GRANT = "role.grant"
+
+	// AUDIT_ACTION_ROLE_REVOKE 撤销角色
+	AUDIT_ACTION_ROLE_REVOKE = "role.revoke"
+
+	// AUDIT_ACTION_ROLE_BOOTSTRAP 启动时按配置授予初始管理员 操作者ID为空
+	AUDIT_ACTION_ROLE_BOOTSTRAP = "role.bootstrap"
+
+	// AUDIT_ACTION_USER_SUSPEND 暂停使用账号
+	AUDIT_ACTION_USER_SUSPEND = "user.suspend"
+
+	// AUDIT_ACTION_USER_BAN 封禁账号
+	AUDIT_ACTION_USER_BAN = "user.ban"
+
+	// AUDIT_ACTION_USER_UNSUSPEND 解除暂停使用或封禁
+	AUDIT_ACTION_USER_UNSUSPEND = "user.unsuspend"
+
+	// AUDIT_ACTION_USER_LOGOUT 强制登出
+	AUDIT_ACTION_USER_LOGOUT = "user.logout"
+)
+
+const (
+	// SUSPEND_MIN_DURATION 暂停使用最短时长（秒）
+	SUSPEND_MIN_DURATION = 60
+
+	// SUSPEND_MAX_DURATION 暂停使用最长时长（秒） 更长时间应使用封禁
+	SUSPEND_MAX_DURATION = 365 * 24 * 60 * 60
+)
+
+// AUDIT_REASON_MAX_LENGTH 审计原因最大长度
+const AUDIT_REASON_MAX_LENGTH = 256
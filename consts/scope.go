@@ -0,0 +1,45 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明个人访问令牌权限范围
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// SCOPE_PROFILE_READ 读取当前用户的资料与关注关系
+	SCOPE_PROFILE_READ = "profile:read"
+
+	// SCOPE_PROFILE_WRITE 修改用户资料与头像
+	SCOPE_PROFILE_WRITE = "profile:write"
+
+	// SCOPE_POST_READ 以当前用户身份读取博文与评论
+	SCOPE_POST_READ = "post:read"
+
+	// SCOPE_POST_WRITE 发布 修改 删除与转发博文
+	SCOPE_POST_WRITE = "post:write"
+
+	// SCOPE_COMMENT_WRITE 发表与删除评论和回复
+	SCOPE_COMMENT_WRITE = "comment:write"
+
+	// SCOPE_FOLLOW_WRITE 关注与取消关注用户
+	SCOPE_FOLLOW_WRITE = "follow:write"
+
+	// SCOPE_TIMELINE_READ 读取首页时间线
+	SCOPE_TIMELINE_READ = "timeline:read"
+
+	// SCOPE_MEDIA_WRITE 上传媒体文件
+	SCOPE_MEDIA_WRITE = "media:write"
+)
+
+// PERSONAL_ACCESS_TOKEN_SCOPES 个人访问令牌可申请的权限范围
+var PERSONAL_ACCESS_TOKEN_SCOPES = []string{
+	SCOPE_PROFILE_READ,
+	SCOPE_PROFILE_WRITE,
+	SCOPE_POST_READ,
+	SCOPE_POST_WRITE,
+	SCOPE_COMMENT_WRITE,
+	SCOPE_FOLLOW_WRITE,
+	SCOPE_TIMELINE_READ,
+	SCOPE_MEDIA_WRITE,
+}
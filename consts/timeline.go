@@ -0,0 +1,21 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于定义时间线相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// TIMELINE_MAX_LENGTH 单个用户时间线缓存的最大博文数量
+	TIMELINE_MAX_LENGTH = 800
+
+	// TIMELINE_FANOUT_THRESHOLD 粉丝数达到该值的用户发布博文时不再推送 由粉丝读取时间线时拉取
+	TIMELINE_FANOUT_THRESHOLD = 5000
+
+	// TIMELINE_BACKFILL_SIZE 关注用户时向时间线补充的博文数量
+	TIMELINE_BACKFILL_SIZE = 50
+
+	// TIMELINE_EXPIRE_DURATION 时间线缓存自最后一次读取起的有效期
+	TIMELINE_EXPIRE_DURATION = 7 * 24 * 60 * 60
+)
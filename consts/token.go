@@ -7,15 +7,42 @@ Copyright (c) [2024], Author(s):
 package consts
 
 const (
-	// TOKEN_EXPIRE_DURATION 有效期
-	TOKEN_EXPIRE_DURATION = 7 * 24 * 60 * 60
+	// ACCESS_TOKEN_EXPIRE_DURATION 访问令牌有效期
+	ACCESS_TOKEN_EXPIRE_DURATION = 15 * 60
 
-	// TOKEN_SECRET 令牌密钥
-	TOKEN_SECRET = "ZEWISE_BACKEND_EXAMPLE_SECRET"
+	// REFRESH_TOKEN_EXPIRE_DURATION 刷新令牌有效期
+	REFRESH_TOKEN_EXPIRE_DURATION = 30 * 24 * 60 * 60
+
+	// REFRESH_TOKEN_LENGTH 刷新令牌长度
+	REFRESH_TOKEN_LENGTH = 64
 
 	// TOKEN_ISSUER 令牌签发者
 	TOKEN_ISSUER = "space.zewise.auth"
 
 	// MAX_TOKENS_PER_USER 最大令牌数量
 	MAX_TOKENS_PER_USER = 5
+
+	// SESSION_TOUCH_INTERVAL 会话最后使用时间的最小更新间隔
+	SESSION_TOUCH_INTERVAL = 60
+
+	// PERSONAL_ACCESS_TOKEN_PREFIX 个人访问令牌前缀 用于区分个人访问令牌与 JWT
+	PERSONAL_ACCESS_TOKEN_PREFIX = "zwp_"
+
+	// PERSONAL_ACCESS_TOKEN_LENGTH 个人访问令牌随机部分长度
+	PERSONAL_ACCESS_TOKEN_LENGTH = 40
+
+	// PERSONAL_ACCESS_TOKEN_HINT_LENGTH 个人访问令牌展示的末尾字符数
+	PERSONAL_ACCESS_TOKEN_HINT_LENGTH = 4
+
+	// PERSONAL_ACCESS_TOKEN_NAME_MAX_LENGTH 个人访问令牌名称最大长度
+	PERSONAL_ACCESS_TOKEN_NAME_MAX_LENGTH = 64
+
+	// PERSONAL_ACCESS_TOKEN_DEFAULT_EXPIRE_DAYS 个人访问令牌默认有效天数
+	PERSONAL_ACCESS_TOKEN_DEFAULT_EXPIRE_DAYS = 30
+
+	// PERSONAL_ACCESS_TOKEN_MAX_EXPIRE_DAYS 个人访问令牌最大有效天数
+	PERSONAL_ACCESS_TOKEN_MAX_EXPIRE_DAYS = 365
+
+	// MAX_PERSONAL_ACCESS_TOKENS_PER_USER 每个用户最多持有的个人访问令牌数量
+	MAX_PERSONAL_ACCESS_TOKENS_PER_USER = 20
 )
@@ -0,0 +1,45 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明两步验证相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// TOTP_ISSUER 验证器应用中显示的签发者
+	TOTP_ISSUER = "ZeWise"
+
+	// TOTP_SECRET_SIZE TOTP 密钥字节数
+	TOTP_SECRET_SIZE = 20
+
+	// TOTP_DIGITS 验证码位数
+	TOTP_DIGITS = 6
+
+	// TOTP_PERIOD 验证码时间步长
+	TOTP_PERIOD = 30
+
+	// TOTP_SKEW 允许的时间步偏移量
+	TOTP_SKEW = 1
+
+	// TOTP_QR_CODE_SIZE 二维码图片边长
+	TOTP_QR_CODE_SIZE = 256
+
+	// TOTP_SETUP_EXPIRE_DURATION 待确认的 TOTP 密钥有效期
+	TOTP_SETUP_EXPIRE_DURATION = 600
+
+	// TOTP_CHALLENGE_TOKEN_LENGTH 两步验证挑战令牌长度
+	TOTP_CHALLENGE_TOKEN_LENGTH = 32
+
+	// TOTP_CHALLENGE_EXPIRE_DURATION 两步验证挑战令牌有效期
+	TOTP_CHALLENGE_EXPIRE_DURATION = 300
+
+	// TOTP_CHALLENGE_MAX_ATTEMPTS 单个挑战令牌允许的验证码错误次数
+	TOTP_CHALLENGE_MAX_ATTEMPTS = 5
+
+	// RECOVERY_CODE_COUNT 恢复码数量
+	RECOVERY_CODE_COUNT = 10
+
+	// RECOVERY_CODE_LENGTH 恢复码长度 不含分隔符
+	RECOVERY_CODE_LENGTH = 10
+)
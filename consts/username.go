@@ -0,0 +1,18 @@
+/*
+Package consts - ZeWise 常量包
+该文件用于声明修改用户名相关常量
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package consts
+
+const (
+	// USERNAME_CHANGE_COOLDOWN_DAYS 两次修改用户名之间的最短间隔天数
+	USERNAME_CHANGE_COOLDOWN_DAYS = 30
+
+	// USERNAME_REDIRECT_DAYS 修改后通过旧用户名仍可访问到该用户资料的天数
+	USERNAME_REDIRECT_DAYS = 90
+
+	// USERNAME_RESERVE_DAYS 修改后旧用户名保留不可被他人使用的天数 不应短于跳转天数
+	USERNAME_RESERVE_DAYS = 180
+)
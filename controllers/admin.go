@@ -0,0 +1,369 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明管理接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/services"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+// AdminController 管理控制器
+type AdminController struct {
+	service *services.Service // 服务对象
+}
+
+/*
+NewAdminController 新建管理控制器
+
+返回：
+  - *AdminController：管理控制器对象
+*/
+func (factory *Factory) NewAdminController() *AdminController {
+	return &AdminController{factory.service}
+}
+
+/*
+NewGrantRoleHandler 新建授予角色接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewGrantRoleHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取操作者ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		operatorID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.RoleGrantBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.UID == "" || reqBody.Role == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID与角色")),
+			)
+		}
+
+		// 授予角色
+		err = controller.service.AdminService.GrantRole(operatorID, reqBody.UID, reqBody.Role, reqBody.Reason, ctx.IP())
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewRevokeRoleHandler 新建撤销角色接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewRevokeRoleHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取操作者ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		operatorID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.RoleRevokeBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.UID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID")),
+			)
+		}
+
+		// 撤销角色
+		err = controller.service.AdminService.RevokeRole(operatorID, reqBody.UID, reqBody.Reason, ctx.IP())
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewAuditLogsHandler 新建获取审计日志接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewAuditLogsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取分页参数
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取审计日志
+		logs, err := controller.service.AdminService.GetAuditLogs(ctx.Query("uid"), cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewAuditLogListResponse(logs, limit)),
+		)
+	}
+}
+
+/*
+NewUserDetailHandler 新建查询用户接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewUserDetailHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取请求参数
+		userID := ctx.Query("id")
+		username := ctx.Query("username")
+		email := ctx.Query("email")
+		if userID == "" && username == "" && email == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID 用户名或邮箱")),
+			)
+		}
+
+		// 查询用户
+		userInfo, authInfo, err := controller.service.AdminService.GetUserDetail(userID, username, email)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewAdminUserResponse(userInfo, authInfo)),
+		)
+	}
+}
+
+/*
+NewSuspendUserHandler 新建暂停使用账号接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewSuspendUserHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取操作者ID与角色
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		operatorID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+		authority := ctx.Locals("authority").(uint64)
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.UserSuspendBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.UID == "" || reqBody.Reason == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID与原因")),
+			)
+		}
+
+		// 暂停使用账号
+		err = controller.service.AdminService.SuspendUser(operatorID, authority, reqBody.UID, reqBody.Duration, reqBody.Reason, ctx.IP())
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewBanUserHandler 新建封禁账号接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewBanUserHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取操作者ID与角色
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		operatorID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+		authority := ctx.Locals("authority").(uint64)
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.UserModerateBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.UID == "" || reqBody.Reason == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID与原因")),
+			)
+		}
+
+		// 封禁账号
+		err = controller.service.AdminService.BanUser(operatorID, authority, reqBody.UID, reqBody.Reason, ctx.IP())
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewUnsuspendUserHandler 新建解除账号停用接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewUnsuspendUserHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取操作者ID与角色
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		operatorID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+		authority := ctx.Locals("authority").(uint64)
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.UserModerateBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.UID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID")),
+			)
+		}
+
+		// 解除停用
+		err = controller.service.AdminService.UnsuspendUser(operatorID, authority, reqBody.UID, reqBody.Reason, ctx.IP())
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewForceLogoutHandler 新建强制登出接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AdminController) NewForceLogoutHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取操作者ID与角色
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		operatorID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+		authority := ctx.Locals("authority").(uint64)
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.UserModerateBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.UID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID")),
+			)
+		}
+
+		// 强制登出
+		err = controller.service.AdminService.ForceLogout(operatorID, authority, reqBody.UID, reqBody.Reason, ctx.IP())
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
@@ -55,22 +55,31 @@ func (controller *AuthController) NewLoginHandler() fiber.Handler {
 		}
 
 		// 登录
-		token, err := controller.service.AuthService.AuthLogin(
+		result, err := controller.service.AuthService.AuthLogin(
 			reqBody.Email,
 			reqBody.UserName,
 			reqBody.Password,
+			reqBody.CaptchaID,
+			reqBody.CaptchaAnswer,
 			ctx.IP(),
 			useragent.New(ctx.Get("User-Agent")),
 		)
 
 		if err != nil {
 			return ctx.Status(200).JSON(
-				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 需要两步验证
+		if result.ChallengeToken != "" {
+			return ctx.Status(200).JSON(
+				serializers.NewResponse(serializers.SUCCESS, "", serializers.NewTwoFactorChallengeResponse(result.ChallengeToken)),
 			)
 		}
 
 		return ctx.Status(200).JSON(
-			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewAuthLoginResponse(token)),
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewAuthLoginResponse(result.Token, result.RefreshToken)),
 		)
 	}
 }
@@ -83,14 +92,11 @@ NewLogoutHandler 新建退出接口处理函数
 */
 func (controller *AuthController) NewLogoutHandler() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		// 获取 Token
-		token, _ := parsers.ParseContextTokenString(ctx)
-
 		// 获取 TokenClaims
 		tokenClaims := ctx.Locals("claims").(parsers.BearerTokenClaims)
 
 		// 登出
-		err := controller.service.AuthService.AuthLogout(tokenClaims, token)
+		err := controller.service.AuthService.AuthLogout(tokenClaims)
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewErrorResponse(err),
@@ -111,31 +117,53 @@ NewRefreshTokenHandler 新建刷新 Token 接口处理函数
 */
 func (controller *AuthController) NewRefreshTokenHandler() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		// 获取 Token
-		token, err := parsers.ParseContextTokenString(ctx)
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.RefreshTokenBody](ctx)
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
 			)
 		}
+		if reqBody.RefreshToken == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "刷新令牌不能为空")),
+			)
+		}
 
-		// 解析 Token
-		claims, err := parsers.ParseToken(token)
+		// 刷新 Token
+		newToken, newRefreshToken, err := controller.service.AuthService.RefreshToken(reqBody.RefreshToken)
 		if err != nil {
 			return ctx.Status(200).JSON(
-				serializers.NewErrorResponse(types.NewError(types.ErrAuthFailed, "bearer token 无效")),
+				serializers.NewErrorResponse(err),
 			)
 		}
 
-		userObjectID, err := claims.GetUserObjectID()
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewAuthLoginResponse(newToken, newRefreshToken)),
+		)
+	}
+}
+
+/*
+NewSendVerifyMailHandler 新建发送邮箱验证邮件接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewSendVerifyMailHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
 			)
 		}
 
-		// 刷新 Token
-		newToken, err := controller.service.AuthService.RefreshToken(userObjectID, claims.UserName, token)
+		// 发送验证邮件
+		err = controller.service.AuthService.SendVerifyMail(userID)
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewErrorResponse(err),
@@ -144,7 +172,379 @@ func (controller *AuthController) NewRefreshTokenHandler() fiber.Handler {
 
 		// 返回结果
 		return ctx.Status(200).JSON(
-			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewAuthLoginResponse(newToken)),
+			serializers.NewResponse(serializers.SUCCESS, ""),
 		)
 	}
 }
+
+/*
+NewConfirmVerifyMailHandler 新建确认邮箱验证接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewConfirmVerifyMailHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.MailVerifyConfirmBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.Token == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供验证令牌")),
+			)
+		}
+
+		// 确认邮箱
+		err = controller.service.AuthService.ConfirmVerifyMail(reqBody.Token)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewRequestEmailChangeHandler 新建申请修改邮箱接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewRequestEmailChangeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.EmailChangeRequestBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 申请修改邮箱
+		err = controller.service.AuthService.RequestEmailChange(userID, reqBody.Password, reqBody.Email)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewConfirmEmailChangeHandler 新建确认修改邮箱接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewConfirmEmailChangeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.EmailChangeConfirmBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.Token == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供确认令牌")),
+			)
+		}
+
+		// 确认修改邮箱
+		err = controller.service.AuthService.ConfirmEmailChange(reqBody.Token)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewRequestPasswordResetHandler 新建申请密码重置接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewRequestPasswordResetHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.PasswordResetRequestBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.Email == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "邮箱不能为空")),
+			)
+		}
+
+		// 发送重置邮件
+		err = controller.service.AuthService.RequestPasswordReset(reqBody.Email)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewConfirmPasswordResetHandler 新建确认密码重置接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewConfirmPasswordResetHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.PasswordResetConfirmBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.Token == "" || reqBody.NewPassword == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "重置令牌和新密码不能为空")),
+			)
+		}
+
+		// 重置密码
+		err = controller.service.AuthService.ConfirmPasswordReset(reqBody.Token, reqBody.NewPassword)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewSessionListHandler 新建会话列表接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewSessionListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取 TokenClaims
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+
+		// 获取会话列表
+		sessions, err := controller.service.AuthService.ListSessions(claims.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewSessionListResponse(sessions, claims.SessionID)),
+		)
+	}
+}
+
+/*
+NewRevokeSessionHandler 新建吊销会话接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewRevokeSessionHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取 TokenClaims
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.SessionRevokeBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.ID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供会话ID")),
+			)
+		}
+
+		// 吊销会话
+		err = controller.service.AuthService.RevokeSession(claims.UID, reqBody.ID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewRevokeOtherSessionsHandler 新建吊销其余会话接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewRevokeOtherSessionsHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取 TokenClaims
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+
+		// 吊销其余会话
+		err := controller.service.AuthService.RevokeOtherSessions(claims.UID, claims.SessionID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewLoginHistoryHandler 新建登录历史接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewLoginHistoryHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 提取分页参数
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取登录历史
+		logs, err := controller.service.AuthService.GetLoginHistory(userID, cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewLoginHistoryResponse(logs, limit)),
+		)
+	}
+}
+
+/*
+NewUnlockAccountHandler 新建账号解锁接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewUnlockAccountHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.AccountUnlockBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.Token == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供解锁令牌")),
+			)
+		}
+
+		// 解锁账号
+		err = controller.service.AuthService.UnlockAccount(reqBody.Token)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewJWKSHandler 新建令牌验证公钥集合接口处理函数
+响应遵循 RFC 7517 格式 不使用统一响应包装 以便其他服务直接验证令牌
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewJWKSHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		jwks, err := controller.service.AuthService.GetJWKS()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return ctx.Status(200).JSON(jwks)
+	}
+}
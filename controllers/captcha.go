@@ -0,0 +1,36 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明图片验证码接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/utils/serializers"
+)
+
+/*
+NewCaptchaHandler 新建获取图片验证码接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *AuthController) NewCaptchaHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 生成验证码
+		captchaID, image, err := controller.service.AuthService.CreateCaptcha()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewCaptchaResponse(captchaID, image)),
+		)
+	}
+}
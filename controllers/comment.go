@@ -0,0 +1,265 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明评论接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/services"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+// CommentController 评论控制器
+type CommentController struct {
+	service *services.Service // 服务对象
+}
+
+/*
+NewCommentController 新建评论控制器
+
+返回：
+  - *CommentController：评论控制器对象
+*/
+func (factory *Factory) NewCommentController() *CommentController {
+	return &CommentController{factory.service}
+}
+
+/*
+NewCreateCommentHandler 新建发表评论接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *CommentController) NewCreateCommentHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.CommentCreateBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 发表评论
+		commentInfo, err := controller.service.CommentService.CreateComment(userID, reqBody)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewCommentResponse(commentInfo)),
+		)
+	}
+}
+
+/*
+NewCommentListHandler 新建博文评论列表接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *CommentController) NewCommentListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取请求参数
+		postID := ctx.Query("post_id")
+		if postID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供博文ID")),
+			)
+		}
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取评论列表
+		comments, err := controller.service.CommentService.GetPostComments(postID, getViewerID(ctx), cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewCommentListResponse(comments, limit)),
+		)
+	}
+}
+
+/*
+NewDeleteCommentHandler 新建删除评论接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *CommentController) NewDeleteCommentHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.CommentDeleteBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 删除评论
+		err = controller.service.CommentService.DeleteComment(userID, reqBody.ID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewCreateReplyHandler 新建发表回复接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *CommentController) NewCreateReplyHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.ReplyCreateBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 发表回复
+		replyInfo, err := controller.service.CommentService.CreateReply(userID, reqBody)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewReplyResponse(replyInfo)),
+		)
+	}
+}
+
+/*
+NewReplyThreadHandler 新建回复楼层接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *CommentController) NewReplyThreadHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取请求参数
+		commentID := ctx.Query("comment_id")
+		if commentID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供评论ID")),
+			)
+		}
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取回复楼层
+		roots, descendants, err := controller.service.CommentService.GetReplyThread(commentID, getViewerID(ctx), cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewReplyThreadResponse(roots, descendants, limit)),
+		)
+	}
+}
+
+/*
+NewDeleteReplyHandler 新建删除回复接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *CommentController) NewDeleteReplyHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.CommentDeleteBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 删除回复
+		err = controller.service.CommentService.DeleteReply(userID, reqBody.ID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明个人数据导出接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+/*
+NewRequestDataExportHandler 新建申请导出个人数据接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewRequestDataExportHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 申请导出
+		export, err := controller.service.ExportService.RequestDataExport(userID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewDataExportResponse(export, "")),
+		)
+	}
+}
+
+/*
+NewDataExportHandler 新建获取个人数据导出状态接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewDataExportHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 获取导出状态
+		export, downloadURL, err := controller.service.ExportService.GetDataExport(userID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewDataExportResponse(export, downloadURL)),
+		)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明关注关系接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/services"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+// FollowController 关注关系控制器
+type FollowController struct {
+	service *services.Service // 服务对象
+}
+
+/*
+NewFollowController 新建关注关系控制器
+
+返回：
+  - *FollowController：关注关系控制器对象
+*/
+func (factory *Factory) NewFollowController() *FollowController {
+	return &FollowController{factory.service}
+}
+
+/*
+NewFollowHandler 新建关注用户接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *FollowController) NewFollowHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.FollowBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 关注用户
+		err = controller.service.FollowService.Follow(userID, reqBody.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewUnfollowHandler 新建取消关注接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *FollowController) NewUnfollowHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.FollowBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 取消关注
+		err = controller.service.FollowService.Unfollow(userID, reqBody.UID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewFollowersHandler 新建粉丝列表接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *FollowController) NewFollowersHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取请求参数
+		userID := ctx.Query("uid")
+		if userID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID")),
+			)
+		}
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取粉丝列表
+		follows, users, mutual, err := controller.service.FollowService.GetFollowers(userID, cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewFollowListResponse(follows, users, mutual, limit)),
+		)
+	}
+}
+
+/*
+NewFollowingHandler 新建关注列表接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *FollowController) NewFollowingHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 提取请求参数
+		userID := ctx.Query("uid")
+		if userID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID")),
+			)
+		}
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取关注列表
+		follows, users, mutual, err := controller.service.FollowService.GetFollowing(userID, cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewFollowListResponse(follows, users, mutual, limit)),
+		)
+	}
+}
+
+/*
+NewRelationHandler 新建关注关系查询接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *FollowController) NewRelationHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 提取请求参数
+		targetID := ctx.Query("uid")
+		if targetID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供用户ID")),
+			)
+		}
+
+		// 查询关注关系
+		following, followedBy, err := controller.service.FollowService.GetRelation(userID, targetID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewRelationResponse(following, followedBy)),
+		)
+	}
+}
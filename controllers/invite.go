@@ -0,0 +1,172 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明邀请码接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+/*
+NewCreateInviteCodeHandler 新建生成邀请码接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewCreateInviteCodeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.InviteCodeCreateBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 生成邀请码
+		invite, err := controller.service.UserService.CreateInviteCode(userID, reqBody.MaxUses, reqBody.ExpiresInDays)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewInviteCodeResponse(invite)),
+		)
+	}
+}
+
+/*
+NewInviteCodeListHandler 新建获取邀请码列表接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewInviteCodeListHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 获取邀请码列表
+		invites, err := controller.service.UserService.ListInviteCodes(userID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewInviteCodeListResponse(invites)),
+		)
+	}
+}
+
+/*
+NewRevokeInviteCodeHandler 新建作废邀请码接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewRevokeInviteCodeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.InviteCodeRevokeBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.ID == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "需要提供邀请码ID")),
+			)
+		}
+
+		// 作废邀请码
+		err = controller.service.UserService.RevokeInviteCode(userID, reqBody.ID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewInviteesHandler 新建获取受邀用户列表接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewInviteesHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 提取分页参数
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取受邀用户列表
+		users, err := controller.service.UserService.GetInvitees(userID, cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewInviteeListResponse(users, limit)),
+		)
+	}
+}
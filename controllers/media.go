@@ -0,0 +1,83 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明媒体文件接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/services"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+// MediaController 媒体文件控制器
+type MediaController struct {
+	service *services.Service // 服务对象
+}
+
+/*
+NewMediaController 新建媒体文件控制器
+
+返回：
+  - *MediaController：媒体文件控制器对象
+*/
+func (factory *Factory) NewMediaController() *MediaController {
+	return &MediaController{factory.service}
+}
+
+/*
+NewUploadHandler 新建上传媒体文件接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *MediaController) NewUploadHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 获取表单文件
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "无法获取媒体文件")),
+			)
+		}
+		files := form.File["media"]
+		if len(files) == 0 || files[0] == nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "媒体文件不能为空")),
+			)
+		}
+		if len(files) > 1 {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "只能上传一个媒体文件")),
+			)
+		}
+		fileHeader := files[0]
+
+		// 上传媒体文件
+		mediaInfo, err := controller.service.MediaService.UploadImage(userID, fileHeader)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewMediaResponse(mediaInfo)),
+		)
+	}
+}
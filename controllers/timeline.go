@@ -0,0 +1,79 @@
+/*
+Package controllers - ZeWise 控制器
+该文件用于声明时间线接口控制器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package controllers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"zewise.space/backend/services"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+// TimelineController 时间线控制器
+type TimelineController struct {
+	service *services.Service // 服务对象
+}
+
+/*
+NewTimelineController 新建时间线控制器
+
+返回：
+  - *TimelineController：时间线控制器对象
+*/
+func (factory *Factory) NewTimelineController() *TimelineController {
+	return &TimelineController{factory.service}
+}
+
+/*
+NewHomeHandler 新建首页时间线接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *TimelineController) NewHomeHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 提取分页参数
+		cursor, limit, err := parsers.ParsePagination(ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 获取时间线
+		posts, nextCursor, err := controller.service.TimelineService.GetHomeTimeline(userID, cursor, limit)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 解析原博文
+		parents, err := controller.service.PostService.ResolveParentPosts(posts, userID)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewTimelineResponse(posts, parents, nextCursor)),
+		)
+	}
+}
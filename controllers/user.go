@@ -56,7 +56,9 @@ func (controller *UserController) NewRegisterHandler() fiber.Handler {
 		}
 
 		// 注册用户
-		err = controller.service.UserService.RegisterUser(reqBody.Username, reqBody.Email, reqBody.Password)
+		err = controller.service.UserService.RegisterUser(
+			reqBody.Username, reqBody.Email, reqBody.Password, reqBody.InviteCode, reqBody.CaptchaID, reqBody.CaptchaAnswer,
+		)
 		if err != nil {
 			return ctx.Status(200).JSON(
 				serializers.NewErrorResponse(err),
@@ -241,3 +243,88 @@ func (controller *UserController) NewUpdatePasswordHandler() fiber.Handler {
 		)
 	}
 }
+
+/*
+NewUpdateUsernameHandler 新建修改用户名接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewUpdateUsernameHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.UserUpdateUsernameBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+
+		// 修改用户名
+		err = controller.service.UserService.ChangeUsername(userID, reqBody.Username)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, ""),
+		)
+	}
+}
+
+/*
+NewDeleteAccountHandler 新建申请注销账号接口处理函数
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (controller *UserController) NewDeleteAccountHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 解析请求体
+		reqBody, err := parsers.ParseBody[parsers.AccountDeleteBody](ctx)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, err.Error())),
+			)
+		}
+		if reqBody.Password == "" {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "密码不能为空")),
+			)
+		}
+
+		// 申请注销
+		purgeAt, err := controller.service.UserService.RequestAccountDeletion(userID, reqBody.Password)
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 返回结果
+		return ctx.Status(200).JSON(
+			serializers.NewResponse(serializers.SUCCESS, "", serializers.NewAccountDeletionResponse(purgeAt)),
+		)
+	}
+}
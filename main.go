@@ -10,6 +10,8 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
@@ -29,7 +31,10 @@ import (
 	"zewise.space/backend/models"
 	"zewise.space/backend/services"
 	"zewise.space/backend/stores"
+	"zewise.space/backend/utils/encryptors"
 	"zewise.space/backend/utils/functools"
+	"zewise.space/backend/utils/mailers"
+	"zewise.space/backend/utils/signers"
 )
 
 var (
@@ -37,7 +42,9 @@ var (
 	redisClient       *redis.Client
 	mongoClient       *mongo.Client
 	minioClient       *minio.Client
+	mailer            mailers.Mailer
 	storage           *stores.Storage
+	service           *services.Service
 	controllerFactory *controllers.Factory
 	middlewareFactory *middlewares.Factory
 )
@@ -57,6 +64,33 @@ func init() {
 		log.Panic("加载环境变量失败")
 	}
 
+	// 初始化令牌签名密钥
+	keySet, err := signers.NewKeySet(config.Token.ActiveKID, config.Token.Keys)
+	if err != nil {
+		panic(err)
+	}
+	signers.Setup(keySet)
+
+	// 初始化密码哈希参数
+	passwordHashParams := encryptors.Argon2Params{
+		Memory:      consts.ARGON2_MEMORY,
+		Iterations:  consts.ARGON2_ITERATIONS,
+		Parallelism: consts.ARGON2_PARALLELISM,
+	}
+	if config.Auth.Argon2Memory > 0 {
+		passwordHashParams.Memory = config.Auth.Argon2Memory
+	}
+	if config.Auth.Argon2Iterations > 0 {
+		passwordHashParams.Iterations = config.Auth.Argon2Iterations
+	}
+	if config.Auth.Argon2Parallelism > 0 {
+		passwordHashParams.Parallelism = config.Auth.Argon2Parallelism
+	}
+	err = encryptors.SetPasswordHashParams(passwordHashParams)
+	if err != nil {
+		panic(err)
+	}
+
 	// 初始化 Redis
 	redisClient = redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", config.Redis.Host, config.Redis.Port),
@@ -80,6 +114,14 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	if config.Auth.LoginLogRetentionDays <= 0 {
+		config.Auth.LoginLogRetentionDays = consts.LOGIN_LOG_RETENTION_DAYS
+	}
+	err = models.SetupIndex(mongoClient.Database(config.MongoDB.DBName), config.Auth.LoginLogRetentionDays)
+	if err != nil {
+		panic(err)
+	}
+
 	// 初始化 MinIO
 	minioClient, err = minio.New(
 		functools.JoinStrings(config.MinIO.Host, ":", fmt.Sprint(config.MinIO.Port)),
@@ -91,18 +133,68 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
-	err = models.SetupBucket(minioClient)
+	err = models.SetupBucket(minioClient, consts.DATA_EXPORT_RETENTION_DAYS)
 	if err != nil {
 		panic(err)
 	}
 
+	// 校验注册设置
+	switch config.Registration.Mode {
+	case consts.REGISTRATION_MODE_OPEN, consts.REGISTRATION_MODE_INVITE, consts.REGISTRATION_MODE_CLOSED:
+	case "":
+		config.Registration.Mode = consts.REGISTRATION_MODE_OPEN
+	default:
+		panic("unknown registration mode: " + config.Registration.Mode)
+	}
+	if config.Registration.InviteMinLevel == 0 {
+		config.Registration.InviteMinLevel = consts.INVITE_MIN_LEVEL
+	}
+
+	// 校验账号注销设置
+	switch config.AccountDeletion.ContentPolicy {
+	case consts.ACCOUNT_DELETION_POLICY_ANONYMIZE, consts.ACCOUNT_DELETION_POLICY_REMOVE:
+	case "":
+		config.AccountDeletion.ContentPolicy = consts.ACCOUNT_DELETION_POLICY_ANONYMIZE
+	default:
+		panic("unknown account deletion content policy: " + config.AccountDeletion.ContentPolicy)
+	}
+	if config.AccountDeletion.GraceDays <= 0 {
+		config.AccountDeletion.GraceDays = consts.ACCOUNT_DELETION_GRACE_DAYS
+	}
+
+	// 初始化邮件发送器
+	switch config.Mail.Driver {
+	case "smtp":
+		mailer, err = mailers.NewSMTPMailer(
+			config.Mail.Host,
+			config.Mail.Port,
+			config.Mail.Username,
+			os.Getenv("MAIL_PASSWORD"),
+			config.Mail.From,
+		)
+		if err != nil {
+			panic(err)
+		}
+	case "fake":
+		mailer = mailers.NewFakeMailer()
+	default:
+		panic("unknown mail driver: " + config.Mail.Driver)
+	}
+
 	// 初始化存储
 	storage = stores.NewStore(redisClient, mongoClient, config.MongoDB.DBName, minioClient)
 
+	// 初始化服务
+	service = services.NewService(storage, mailer, services.RegistrationConfig{
+		Mode:           config.Registration.Mode,
+		InviteMinLevel: config.Registration.InviteMinLevel,
+	}, services.AccountDeletionConfig{
+		GraceDays:     config.AccountDeletion.GraceDays,
+		ContentPolicy: config.AccountDeletion.ContentPolicy,
+	})
+
 	// 初始化控制器工厂
-	controllerFactory = controllers.NewFactory(
-		services.NewService(storage),
-	)
+	controllerFactory = controllers.NewFactory(service)
 	// 初始化中间件工厂
 	middlewareFactory = middlewares.NewFactory(storage)
 }
@@ -138,19 +230,127 @@ func main() {
 	// Auth 路由
 	authController := controllerFactory.NewAuthController()
 	authGroup := api.Group("/auth")
-	authGroup.Post("/login", authController.NewLoginHandler())                                // 登录
-	authGroup.Post("/logout", auth.NewMiddleware(), authController.NewLogoutHandler())        // 登出
-	authGroup.Post("/refresh", auth.NewMiddleware(), authController.NewRefreshTokenHandler()) // 刷新令牌
-	// authGroup.Post("/verify/mail")
+	authGroup.Post("/login", authController.NewLoginHandler())                                                      // 登录
+	authGroup.Get("/captcha", authController.NewCaptchaHandler())                                                   // 获取图片验证码
+	authGroup.Post("/logout", auth.NewMiddleware(), authController.NewLogoutHandler())                              // 登出
+	authGroup.Post("/refresh", authController.NewRefreshTokenHandler())                                             // 刷新令牌
+	authGroup.Post("/verify/mail", auth.NewMiddleware(), authController.NewSendVerifyMailHandler())                 // 发送邮箱验证邮件
+	authGroup.Post("/verify/mail/confirm", authController.NewConfirmVerifyMailHandler())                            // 确认邮箱验证
+	authGroup.Post("/email/change", auth.NewMiddleware(), authController.NewRequestEmailChangeHandler())            // 申请修改邮箱
+	authGroup.Post("/email/change/confirm", authController.NewConfirmEmailChangeHandler())                          // 确认修改邮箱
+	authGroup.Post("/password/reset", authController.NewRequestPasswordResetHandler())                              // 申请密码重置
+	authGroup.Post("/password/reset/confirm", authController.NewConfirmPasswordResetHandler())                      // 确认密码重置
+	authGroup.Get("/sessions", auth.NewMiddleware(), authController.NewSessionListHandler())                        // 获取会话列表
+	authGroup.Post("/sessions/revoke", auth.NewMiddleware(), authController.NewRevokeSessionHandler())              // 吊销会话
+	authGroup.Post("/sessions/revoke/others", auth.NewMiddleware(), authController.NewRevokeOtherSessionsHandler()) // 吊销其余会话
+	authGroup.Get("/login-history", auth.NewMiddleware(), authController.NewLoginHistoryHandler())                  // 获取登录历史
+	authGroup.Post("/unlock", authController.NewUnlockAccountHandler())                                             // 解锁账号
+	authGroup.Post("/2fa/setup", auth.NewMiddleware(), authController.NewTOTPSetupHandler())                        // 开始设置两步验证
+	authGroup.Post("/2fa/confirm", auth.NewMiddleware(), authController.NewTOTPConfirmHandler())                    // 确认开启两步验证
+	authGroup.Post("/2fa/disable", auth.NewMiddleware(), authController.NewTOTPDisableHandler())                    // 关闭两步验证
+	authGroup.Post("/2fa/recovery-codes", auth.NewMiddleware(), authController.NewRecoveryCodesHandler())           // 重新生成恢复码
+	authGroup.Post("/2fa/verify", authController.NewTOTPVerifyHandler())                                            // 完成两步验证登录
+	authGroup.Get("/tokens", auth.NewMiddleware(), authController.NewPersonalAccessTokenListHandler())              // 获取个人访问令牌列表
+	authGroup.Post("/tokens/create", auth.NewMiddleware(), authController.NewCreatePersonalAccessTokenHandler())    // 创建个人访问令牌
+	authGroup.Post("/tokens/revoke", auth.NewMiddleware(), authController.NewRevokePersonalAccessTokenHandler())    // 吊销个人访问令牌
+	app.Get("/.well-known/jwks.json", authController.NewJWKSHandler())                                              // 令牌验证公钥集合
 
 	// User 路由
 	userController := controllerFactory.NewUserController()
 	user := api.Group("/user")
-	user.Get("/profile", userController.NewProfileHandler())                                       // 获取用户资料信息
-	user.Post("/register", userController.NewRegisterHandler())                                    // 注册
-	user.Post("/update/profile", auth.NewMiddleware(), userController.NewUpdateProfileHandler())   // 更新用户资料
-	user.Post("/update/avatar", auth.NewMiddleware(), userController.NewUpdateAvatarHandler())     // 更新用户头像
-	user.Post("/update/password", auth.NewMiddleware(), userController.NewUpdatePasswordHandler()) // 更新用户密码
+	user.Get("/profile", userController.NewProfileHandler())                                                               // 获取用户资料信息
+	user.Post("/register", userController.NewRegisterHandler())                                                            // 注册
+	user.Post("/update/profile", auth.NewMiddleware(consts.SCOPE_PROFILE_WRITE), userController.NewUpdateProfileHandler()) // 更新用户资料
+	user.Post("/update/avatar", auth.NewMiddleware(consts.SCOPE_PROFILE_WRITE), userController.NewUpdateAvatarHandler())   // 更新用户头像
+	user.Post("/update/password", auth.NewMiddleware(), userController.NewUpdatePasswordHandler())                         // 更新用户密码
+	user.Post("/update/username", auth.NewMiddleware(), userController.NewUpdateUsernameHandler())                         // 修改用户名
+	user.Post("/invite/create", auth.NewMiddleware(), userController.NewCreateInviteCodeHandler())                         // 生成邀请码
+	user.Get("/invite/list", auth.NewMiddleware(), userController.NewInviteCodeListHandler())                              // 获取邀请码列表
+	user.Post("/invite/revoke", auth.NewMiddleware(), userController.NewRevokeInviteCodeHandler())                         // 作废邀请码
+	user.Get("/invitees", auth.NewMiddleware(), userController.NewInviteesHandler())                                       // 获取受邀用户列表
+	user.Post("/delete", auth.NewMiddleware(), userController.NewDeleteAccountHandler())                                   // 申请注销账号
+	user.Post("/export", auth.NewMiddleware(), userController.NewRequestDataExportHandler())                               // 申请导出个人数据
+	user.Get("/export", auth.NewMiddleware(), userController.NewDataExportHandler())                                       // 获取个人数据导出状态
+
+	// Follow 路由
+	followController := controllerFactory.NewFollowController()
+	user.Post("/follow", auth.NewMiddleware(consts.SCOPE_FOLLOW_WRITE), followController.NewFollowHandler())     // 关注用户
+	user.Post("/unfollow", auth.NewMiddleware(consts.SCOPE_FOLLOW_WRITE), followController.NewUnfollowHandler()) // 取消关注
+	user.Get("/followers", followController.NewFollowersHandler())                                               // 获取粉丝列表
+	user.Get("/following", followController.NewFollowingHandler())                                               // 获取关注列表
+	user.Get("/relation", auth.NewMiddleware(consts.SCOPE_PROFILE_READ), followController.NewRelationHandler())  // 查询关注关系
+
+	// Post 路由
+	postController := controllerFactory.NewPostController()
+	post := api.Group("/post")
+	post.Get("/detail", auth.NewOptionalMiddleware(consts.SCOPE_POST_READ), postController.NewDetailHandler())   // 获取博文详情
+	post.Get("/list", auth.NewOptionalMiddleware(consts.SCOPE_POST_READ), postController.NewUserPostsHandler())  // 获取用户博文列表
+	post.Post("/create", auth.NewMiddleware(consts.SCOPE_POST_WRITE), postController.NewCreateHandler())         // 发布博文
+	post.Post("/update", auth.NewMiddleware(consts.SCOPE_POST_WRITE), postController.NewUpdateHandler())         // 更新博文
+	post.Post("/delete", auth.NewMiddleware(consts.SCOPE_POST_WRITE), postController.NewDeleteHandler())         // 删除博文
+	post.Get("/reposts", auth.NewOptionalMiddleware(consts.SCOPE_POST_READ), postController.NewRepostsHandler()) // 获取博文转发列表
+	post.Post("/repost", auth.NewMiddleware(consts.SCOPE_POST_WRITE), postController.NewRepostHandler())         // 转发博文
+	post.Post("/unrepost", auth.NewMiddleware(consts.SCOPE_POST_WRITE), postController.NewUndoRepostHandler())   // 取消转发
+	post.Post("/quote", auth.NewMiddleware(consts.SCOPE_POST_WRITE), postController.NewQuoteHandler())           // 引用转发
+
+	// Timeline 路由
+	timelineController := controllerFactory.NewTimelineController()
+	timeline := api.Group("/timeline")
+	timeline.Get("/home", auth.NewMiddleware(consts.SCOPE_TIMELINE_READ), timelineController.NewHomeHandler()) // 获取首页时间线
+
+	// Media 路由
+	mediaController := controllerFactory.NewMediaController()
+	media := api.Group("/media")
+	media.Post("/upload", auth.NewMiddleware(consts.SCOPE_MEDIA_WRITE), mediaController.NewUploadHandler()) // 上传媒体文件
+
+	// Comment 路由
+	commentController := controllerFactory.NewCommentController()
+	comment := api.Group("/comment")
+	comment.Get("/list", auth.NewOptionalMiddleware(consts.SCOPE_POST_READ), commentController.NewCommentListHandler())         // 获取博文评论列表
+	comment.Post("/create", auth.NewMiddleware(consts.SCOPE_COMMENT_WRITE), commentController.NewCreateCommentHandler())        // 发表评论
+	comment.Post("/delete", auth.NewMiddleware(consts.SCOPE_COMMENT_WRITE), commentController.NewDeleteCommentHandler())        // 删除评论
+	comment.Get("/reply/thread", auth.NewOptionalMiddleware(consts.SCOPE_POST_READ), commentController.NewReplyThreadHandler()) // 获取回复楼层
+	comment.Post("/reply/create", auth.NewMiddleware(consts.SCOPE_COMMENT_WRITE), commentController.NewCreateReplyHandler())    // 发表回复
+	comment.Post("/reply/delete", auth.NewMiddleware(consts.SCOPE_COMMENT_WRITE), commentController.NewDeleteReplyHandler())    // 删除回复
+
+	// Admin 路由
+	adminController := controllerFactory.NewAdminController()
+	admin := api.Group("/admin")
+	admin.Post("/role/grant", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_ROLE_MANAGE), adminController.NewGrantRoleHandler())           // 授予角色
+	admin.Post("/role/revoke", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_ROLE_MANAGE), adminController.NewRevokeRoleHandler())         // 撤销角色
+	admin.Get("/audit-logs", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_AUDIT_READ), adminController.NewAuditLogsHandler())             // 获取审计日志
+	admin.Get("/user", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_USER_MODERATE), adminController.NewUserDetailHandler())               // 查询用户
+	admin.Post("/user/suspend", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_USER_MODERATE), adminController.NewSuspendUserHandler())     // 暂停使用账号
+	admin.Post("/user/ban", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_USER_BAN), adminController.NewBanUserHandler())                  // 封禁账号
+	admin.Post("/user/unsuspend", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_USER_MODERATE), adminController.NewUnsuspendUserHandler()) // 解除账号停用
+	admin.Post("/user/logout", auth.NewMiddleware(), middlewareFactory.NewRequirePermissionMiddleware(consts.PERMISSION_USER_MODERATE), adminController.NewForceLogoutHandler())      // 强制登出
+
+	// 授予初始管理员并定期清除冷静期已结束的注销账号 预派生模式下仅由主进程执行
+	if !fiber.IsChild() {
+		err := service.AdminService.BootstrapAdmins(config.Admin.BootstrapUserIDs)
+		if err != nil {
+			panic(err)
+		}
+		go runAccountPurge()
+	}
 
 	panic(app.Listen(functools.JoinStrings(config.Server.Host, ":", fmt.Sprint(config.Server.Port))))
 }
+
+/*
+runAccountPurge 按固定间隔清除冷静期已结束的注销账号
+*/
+func runAccountPurge() {
+	ticker := time.NewTicker(consts.ACCOUNT_PURGE_INTERVAL * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purged, err := service.UserService.PurgeDueAccounts()
+		if err != nil {
+			log.Printf("清除注销账号失败: %v", err)
+		}
+		if purged > 0 {
+			log.Printf("已清除 %d 个注销账号", purged)
+		}
+	}
+}
@@ -7,20 +7,27 @@ Copyright (c) [2024], Author(s):
 package middlewares
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/mongo"
 
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
 	"zewise.space/backend/stores"
 	"zewise.space/backend/types"
+	"zewise.space/backend/utils/encryptors"
 	"zewise.space/backend/utils/parsers"
 	"zewise.space/backend/utils/serializers"
 )
 
 // TokenAuthMiddleware 认证中间件
 type TokenAuthMiddleware struct {
-	authStorage *stores.AuthStorage
+	storage *stores.Storage
 }
 
 /*
@@ -30,19 +37,20 @@ NewTokenAuthMiddleware 新建 Token 认证中间件
   - *TokenAuthMiddleware：Token 认证中间件对象
 */
 func (factory *Factory) NewTokenAuthMiddleware() *TokenAuthMiddleware {
-	return &TokenAuthMiddleware{factory.storage.AuthStorage}
+	return &TokenAuthMiddleware{factory.storage}
 }
 
 /*
 NewMiddleware Token 认证中间件
+未指定权限范围时仅接受会话令牌 指定权限范围时同时接受拥有全部权限范围的个人访问令牌
 
 参数：
-  - ctx：Fiber 上下文。
+  - scopes：个人访问令牌所需的权限范围
 
 返回：
-  - error：错误
+  - fiber.Handler：Fiber 处理函数
 */
-func (middleware *TokenAuthMiddleware) NewMiddleware() fiber.Handler {
+func (middleware *TokenAuthMiddleware) NewMiddleware(scopes ...string) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		// 从请求头中获取 Token
 		token, err := parsers.ParseContextTokenString(ctx)
@@ -53,38 +61,156 @@ func (middleware *TokenAuthMiddleware) NewMiddleware() fiber.Handler {
 		}
 
 		// 验证 Token
-		claims, err := parsers.ParseToken(token)
-
-		// 处理 Token 错误
-		// Token 过期
-		if errors.Is(err, jwt.ErrTokenExpired) {
+		claims, err := middleware.verifyToken(token, scopes)
+		if err != nil {
 			return ctx.Status(200).JSON(
-				serializers.NewErrorResponse(types.NewError(types.ErrAuthFailed, "bearer token 已过期")),
+				serializers.NewErrorResponse(err),
 			)
 		}
-		// Token 无效
+
+		// 将 claims 信息存入 ctx.Locals 中
+		ctx.Locals("claims", claims)
+
+		return ctx.Next()
+	}
+}
+
+/*
+NewOptionalMiddleware 可选 Token 认证中间件
+请求携带有效 Token 时将 claims 信息存入 ctx.Locals 中 否则以未登录身份继续处理
+
+参数：
+  - scopes：个人访问令牌所需的权限范围
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (middleware *TokenAuthMiddleware) NewOptionalMiddleware(scopes ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 从请求头中获取 Token
+		token, err := parsers.ParseContextTokenString(ctx)
 		if err != nil {
-			return ctx.Status(200).JSON(
-				serializers.NewErrorResponse(types.NewError(types.ErrAuthFailed, "bearer token 无效")),
-			)
+			return ctx.Next()
+		}
+
+		// 验证 Token
+		claims, err := middleware.verifyToken(token, scopes)
+		if err == nil {
+			ctx.Locals("claims", claims)
 		}
 
-		// 检验 Token 是否可用
-		isAvaliable, err := middleware.authStorage.CheckTokenAvailability(claims.UID, token)
+		return ctx.Next()
+	}
+}
+
+/*
+verifyToken 验证 Token 并检验其是否可用
+
+参数：
+  - token：Token 字符串
+  - scopes：个人访问令牌所需的权限范围
+
+返回：
+  - parsers.BearerTokenClaims：Token 声明
+  - error：错误
+*/
+func (middleware *TokenAuthMiddleware) verifyToken(token string, scopes []string) (parsers.BearerTokenClaims, error) {
+	// 个人访问令牌
+	if strings.HasPrefix(token, consts.PERSONAL_ACCESS_TOKEN_PREFIX) {
+		return middleware.verifyPersonalAccessToken(token, scopes)
+	}
+
+	claims, err := parsers.ParseToken(token)
+
+	// 处理 Token 错误
+	// Token 过期
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return claims, types.NewError(types.ErrAuthFailed, "bearer token 已过期")
+	}
+	// Token 无效
+	if err != nil {
+		return claims, types.NewError(types.ErrAuthFailed, "bearer token 无效")
+	}
+
+	// 检验 Token 是否为所属会话当前的令牌 同时更新会话最后使用时间
+	isAvaliable, err := middleware.storage.AuthStorage.ValidateSession(claims.UID, claims.SessionID, claims.ID)
+	if err != nil {
+		return claims, err
+	}
+	if !isAvaliable {
+		return claims, types.NewError(types.ErrAuthFailed, "bearer token 已失效")
+	}
+
+	return claims, nil
+}
+
+/*
+verifyPersonalAccessToken 验证个人访问令牌 并检验其是否拥有所需的权限范围
+
+参数：
+  - token：个人访问令牌
+  - scopes：所需的权限范围
+
+返回：
+  - parsers.BearerTokenClaims：由令牌信息构造的声明
+  - error：错误
+*/
+func (middleware *TokenAuthMiddleware) verifyPersonalAccessToken(token string, scopes []string) (parsers.BearerTokenClaims, error) {
+	claims := parsers.BearerTokenClaims{}
+
+	// 未声明权限范围的接口仅允许会话令牌访问
+	if len(scopes) == 0 {
+		return claims, types.NewError(types.ErrAuthFailed, "该接口不支持个人访问令牌")
+	}
+
+	// 创建数据库会话
+	session, err := middleware.storage.NewSession()
+	if err != nil {
+		return claims, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(context.Background())
+
+	// 获取令牌信息与所属用户
+	now := time.Now()
+	var tokenInfo models.PersonalAccessTokenInfo
+	var authInfo models.UserAuthInfo
+	err = mongo.WithSession(context.Background(), session, func(sessionContext mongo.SessionContext) error {
+		tokenInfo, err = middleware.storage.AuthStorage.GetPersonalAccessTokenByHash(sessionContext, encryptors.HashToken(token))
 		if err != nil {
-			return ctx.Status(200).JSON(
-				serializers.NewErrorResponse(err),
-			)
+			return err
 		}
-		if !isAvaliable {
-			return ctx.Status(200).JSON(
-				serializers.NewErrorResponse(types.NewError(types.ErrAuthFailed, "bearer token 已失效")),
-			)
+		if now.After(tokenInfo.ExpiresAt) {
+			return types.NewError(types.ErrAuthFailed, "bearer token 已过期")
 		}
 
-		// 将 claims 信息存入 ctx.Locals 中
-		ctx.Locals("claims", claims)
+		authInfo, err = middleware.storage.AuthStorage.GetUserAuthInfoByID(sessionContext, tokenInfo.UID)
+		if err != nil {
+			return err
+		}
 
-		return ctx.Next()
+		// 更新令牌最后使用时间
+		return middleware.storage.AuthStorage.TouchPersonalAccessToken(sessionContext, tokenInfo.ID, now)
+	})
+	if err != nil {
+		return claims, err
+	}
+
+	// 检查账号是否已停用
+	err = middleware.storage.AuthStorage.CheckAccountSuspension(tokenInfo.UID.Hex())
+	if err != nil {
+		return claims, err
 	}
+
+	claims.ID = tokenInfo.ID.Hex()
+	claims.UID = tokenInfo.UID.Hex()
+	claims.UserName = authInfo.UserName
+	claims.PersonalAccessToken = true
+	claims.Scopes = tokenInfo.Scopes
+
+	// 检验权限范围
+	if !claims.HasScopes(scopes...) {
+		return claims, types.NewError(types.ErrAuthFailed, "个人访问令牌缺少所需的权限范围："+strings.Join(scopes, " "))
+	}
+
+	return claims, nil
 }
@@ -0,0 +1,75 @@
+/*
+Package middlewares - ZeWise 后端服务器中间件。
+该文件用于定义权限校验中间件。
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package middlewares
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/serializers"
+)
+
+/*
+NewRequirePermissionMiddleware 要求用户角色拥有指定权限 需置于 Token 认证中间件之后
+每次请求都会查询用户当前角色 撤销角色后立即生效 校验通过后将角色存入 ctx.Locals 中
+
+参数：
+  - permission：所需权限
+
+返回：
+  - fiber.Handler：Fiber 处理函数
+*/
+func (factory *Factory) NewRequirePermissionMiddleware(permission string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		// 获取用户ID
+		claims := ctx.Locals("claims").(parsers.BearerTokenClaims)
+		userID, err := claims.GetUserObjectID()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrInvalidParams, "不合法的用户ID")),
+			)
+		}
+
+		// 获取用户角色
+		session, err := factory.storage.NewSession()
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrServerError, err.Error())),
+			)
+		}
+		defer session.EndSession(context.Background())
+
+		var authority uint64
+		err = mongo.WithSession(context.Background(), session, func(sessionContext mongo.SessionContext) error {
+			userInfo, err := factory.storage.UserStorage.GetUserDataByID(sessionContext, userID)
+			authority = userInfo.Authority
+			return err
+		})
+		if err != nil {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(err),
+			)
+		}
+
+		// 校验权限
+		if !slices.Contains(consts.ROLE_PERMISSIONS[authority], permission) {
+			return ctx.Status(200).JSON(
+				serializers.NewErrorResponse(types.NewError(types.ErrAuthFailed, "权限不足")),
+			)
+		}
+
+		ctx.Locals("authority", authority)
+
+		return ctx.Next()
+	}
+}
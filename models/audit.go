@@ -0,0 +1,28 @@
+/*
+Package models - ZeWise 数据模型
+该文件用于声明审计日志模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLog 管理操作审计日志
+type AuditLog struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`    // 主键
+	OperatorID primitive.ObjectID `bson:"operator_id"`      // 操作者ID
+	TargetID   primitive.ObjectID `bson:"target_id"`        // 被操作用户ID
+	Action     string             `bson:"action"`           // 操作类型
+	Before     string             `bson:"before,omitempty"` // 操作前的值
+	After      string             `bson:"after,omitempty"`  // 操作后的值
+	Reason     string             `bson:"reason,omitempty"` // 操作原因
+	IP         string             `bson:"ip"`               // 操作者 IP 地址
+	Time       time.Time          `bson:"time"`             // 操作时间
+}
+
+const AUDIT_LOG_COLLECTION = "audit_logs"
@@ -12,7 +12,86 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-const REDIS_AVAILABLE_USER_TOKEN_LIST = "AUTH:TOKENS"
+// REDIS_USER_SESSIONS 用户会话索引 有序集合 成员为会话 ID 分值为会话创建时间
+const REDIS_USER_SESSIONS = "AUTH:SESSIONS"
+
+// REDIS_SESSION 会话记录 哈希表 键为会话 ID 记录会话信息与当前有效的访问令牌 jti
+const REDIS_SESSION = "AUTH:SESSION"
+
+// SessionInfo 会话信息
+type SessionInfo struct {
+	ID          string    // 会话 ID 即刷新令牌族 ID
+	UID         string    // 用户 ID
+	JTI         string    // 当前有效的访问令牌 ID
+	IP          string    // 登录 IP 地址
+	Location    string    // 登录地理位置
+	Device      string    // 设备
+	Application string    // 应用
+	IssuedAt    time.Time // 会话创建时间
+	LastUsedAt  time.Time // 最后使用时间
+}
+
+// REDIS_REFRESH_TOKEN 刷新令牌 键为令牌摘要 值为 JSON 编码的刷新令牌信息
+const REDIS_REFRESH_TOKEN = "AUTH:REFRESH"
+
+// REDIS_USED_REFRESH_TOKEN 已轮换的刷新令牌 用于检测重放
+const REDIS_USED_REFRESH_TOKEN = "AUTH:REFRESH_USED"
+
+// REDIS_REFRESH_TOKEN_FAMILY 刷新令牌族 值为该族当前有效的刷新令牌摘要
+const REDIS_REFRESH_TOKEN_FAMILY = "AUTH:REFRESH_FAMILY"
+
+// RefreshTokenInfo 刷新令牌信息
+type RefreshTokenInfo struct {
+	UID       string `json:"uid"`        // 用户 ID
+	UserName  string `json:"username"`   // 用户名
+	FamilyID  string `json:"family_id"`  // 令牌族 ID 即会话 ID
+	AccessJTI string `json:"access_jti"` // 与之配对的访问令牌 ID
+}
+
+// REDIS_LOGIN_FAILURE_ACCOUNT 账号登录失败计数
+const REDIS_LOGIN_FAILURE_ACCOUNT = "AUTH:LOGIN_FAIL:ACCOUNT"
+
+// REDIS_LOGIN_FAILURE_IP IP 登录失败计数
+const REDIS_LOGIN_FAILURE_IP = "AUTH:LOGIN_FAIL:IP"
+
+// REDIS_LOGIN_DELAY 账号登录重试等待
+const REDIS_LOGIN_DELAY = "AUTH:LOGIN_DELAY"
+
+// REDIS_ACCOUNT_LOCK 账号锁定
+const REDIS_ACCOUNT_LOCK = "AUTH:LOCK:ACCOUNT"
+
+// REDIS_IP_LOCK IP 锁定
+const REDIS_IP_LOCK = "AUTH:LOCK:IP"
+
+// REDIS_ACCOUNT_UNLOCK_TOKEN 账号解锁令牌 值为用户ID
+const REDIS_ACCOUNT_UNLOCK_TOKEN = "AUTH:UNLOCK"
+
+// REDIS_TOTP_SETUP 待确认的 TOTP 密钥 键为用户ID
+const REDIS_TOTP_SETUP = "AUTH:TOTP_SETUP"
+
+// REDIS_TOTP_USED_STEP 已使用的 TOTP 时间步 用于防止验证码重放
+const REDIS_TOTP_USED_STEP = "AUTH:TOTP_USED"
+
+// REDIS_TOTP_CHALLENGE 两步验证挑战 哈希表 记录用户ID与错误次数
+const REDIS_TOTP_CHALLENGE = "AUTH:TOTP_CHALLENGE"
+
+// REDIS_ACCOUNT_SUSPENDED 账号停用标记 键为用户ID 值为提示信息 暂停使用时随截止时间过期
+const REDIS_ACCOUNT_SUSPENDED = "AUTH:SUSPENDED"
+
+// REDIS_CAPTCHA 图片验证码 键为验证码ID 值为答案
+const REDIS_CAPTCHA = "AUTH:CAPTCHA"
+
+// REDIS_MAIL_VERIFY_TOKEN 邮箱验证令牌 值为用户ID与待验证邮箱
+const REDIS_MAIL_VERIFY_TOKEN = "AUTH:MAIL_VERIFY"
+
+// REDIS_EMAIL_CHANGE_TOKEN 修改邮箱确认令牌 值为用户ID与新邮箱
+const REDIS_EMAIL_CHANGE_TOKEN = "AUTH:EMAIL_CHANGE"
+
+// REDIS_PASSWORD_RESET_TOKEN 密码重置令牌 值为用户ID与申请时的邮箱
+const REDIS_PASSWORD_RESET_TOKEN = "AUTH:PASSWORD_RESET"
+
+// REDIS_MAIL_SEND_LOCK 邮件发送频率限制
+const REDIS_MAIL_SEND_LOCK = "AUTH:MAIL_LOCK"
 
 // UserLoginLog 用户登录日志
 type UserLoginLog struct {
@@ -25,6 +104,7 @@ type UserLoginLog struct {
 	Application string             `bson:"application"`   // 登录应用
 	IfSucceed   bool               `bson:"if_succeed"`    // 是否成功
 	IfChecked   bool               `bson:"if_checked"`    // 是否验证
+	IfLocked    bool               `bson:"if_locked"`     // 是否因账号锁定被拒绝或触发锁定
 }
 
 const USER_LOGIN_LOGS_COLLECTION = "user_login_logs"
@@ -6,13 +6,23 @@ Copyright (c) [2024], Author(s):
 */
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // CommentInfo 评论信息模型
 type CommentInfo struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty"`       // 主键
-	UID      primitive.ObjectID `bson:"uid,omitempty"`       // 用户ID
-	Username string             `bson:"username,omitempty"`  // 用户名
-	Content  string             `bson:"content,omitempty"`   // 内容
-	IsPublic bool               `bson:"is_public,omitempty"` // 是否公开
+	ID         primitive.ObjectID `bson:"_id,omitempty"`        // 主键
+	PostID     primitive.ObjectID `bson:"post_id,omitempty"`    // 博文ID
+	UID        primitive.ObjectID `bson:"uid,omitempty"`        // 用户ID
+	Username   string             `bson:"username,omitempty"`   // 用户名
+	Content    string             `bson:"content,omitempty"`    // 内容
+	IsPublic   bool               `bson:"is_public,omitempty"`  // 是否公开
+	IsDeleted  bool               `bson:"is_deleted,omitempty"` // 是否已删除
+	ReplyCount int64              `bson:"reply_count"`          // 回复总数
+	CreatedAt  time.Time          `bson:"created_at,omitempty"` // 创建时间
 }
+
+const COMMENT_COLLECTION = "comments"
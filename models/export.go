@@ -0,0 +1,28 @@
+/*
+Package models - ZeWise 数据库模型
+该文件用于声明个人数据导出相关模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DataExport 个人数据导出记录模型
+type DataExport struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`          // 主键
+	UID         primitive.ObjectID `bson:"uid,omitempty"`          // 用户ID
+	Status      string             `bson:"status,omitempty"`       // 状态 pending, ready, failed
+	ObjectName  string             `bson:"object_name,omitempty"`  // 归档在存储桶中的对象名
+	Size        int64              `bson:"size,omitempty"`         // 归档大小
+	FailReason  string             `bson:"fail_reason,omitempty"`  // 失败原因 仅供排查 不返回给用户
+	CreatedAt   time.Time          `bson:"created_at,omitempty"`   // 申请时间
+	CompletedAt time.Time          `bson:"completed_at,omitempty"` // 生成完成时间
+	ExpiresAt   time.Time          `bson:"expires_at,omitempty"`   // 过期时间 到期后记录由 TTL 索引删除
+}
+
+const DATA_EXPORT_COLLECTION = "data_exports"
@@ -10,28 +10,45 @@ import (
 	"context"
 
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
 )
 
 const USER_AVATAR_BUCKET = "avatars"
 
+const POST_MEDIA_BUCKET = "media"
+
+// DATA_EXPORT_BUCKET 个人数据导出归档存储桶 不设置公开访问策略 仅能通过预签名链接下载
+const DATA_EXPORT_BUCKET = "exports"
+
 /*
 SetupBucket 初始化存储桶
 
 参数：
   - *client：MinIO 客户端
+  - exportRetentionDays：导出归档保留天数
 
 返回：
   - error：错误信息
 */
-func SetupBucket(client *minio.Client) error {
-	err := client.MakeBucket(context.TODO(), USER_AVATAR_BUCKET, minio.MakeBucketOptions{})
-	if err != nil {
-		exists, errBucketExists := client.BucketExists(context.Background(), USER_AVATAR_BUCKET)
-		if errBucketExists == nil && exists {
-			return nil
-		} else {
-			return err
+func SetupBucket(client *minio.Client, exportRetentionDays int) error {
+	for _, bucket := range []string{USER_AVATAR_BUCKET, POST_MEDIA_BUCKET, DATA_EXPORT_BUCKET} {
+		err := client.MakeBucket(context.TODO(), bucket, minio.MakeBucketOptions{})
+		if err != nil {
+			exists, errBucketExists := client.BucketExists(context.Background(), bucket)
+			if errBucketExists != nil || !exists {
+				return err
+			}
 		}
 	}
-	return nil
+
+	// 导出归档到期后由生命周期规则自动删除
+	config := lifecycle.NewConfiguration()
+	config.Rules = []lifecycle.Rule{
+		{
+			ID:         "expire-exports",
+			Status:     "Enabled",
+			Expiration: lifecycle.Expiration{Days: lifecycle.ExpirationDays(exportRetentionDays)},
+		},
+	}
+	return client.SetBucketLifecycle(context.TODO(), DATA_EXPORT_BUCKET, config)
 }
@@ -0,0 +1,23 @@
+/*
+Package models - ZeWise 数据库模型
+该文件用于声明关注关系相关模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FollowInfo 关注关系模型
+type FollowInfo struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`         // 主键
+	FollowerID primitive.ObjectID `bson:"follower_id,omitempty"` // 关注者ID
+	FolloweeID primitive.ObjectID `bson:"followee_id,omitempty"` // 被关注者ID
+	CreatedAt  time.Time          `bson:"created_at,omitempty"`  // 关注时间
+}
+
+const FOLLOW_COLLECTION = "follows"
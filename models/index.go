@@ -0,0 +1,164 @@
+/*
+Package models - ZeWise 数据库模型
+该文件用于声明数据库索引
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MONGO_INDEX_OPTIONS_CONFLICT 同名索引选项冲突的错误码
+const MONGO_INDEX_OPTIONS_CONFLICT = 85
+
+// collectionIndexes 各集合需要建立的索引
+var collectionIndexes = map[string][]mongo.IndexModel{
+	POST_COLLECTION: {
+		// 按用户查询博文列表
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "_id", Value: -1}}},
+		// 查询博文的转发列表
+		{Keys: bson.D{{Key: "parent_post_id", Value: 1}, {Key: "_id", Value: -1}}},
+		// 查询用户是否已转发
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "parent_post_id", Value: 1}}},
+	},
+	COMMENT_COLLECTION: {
+		// 按博文查询评论列表
+		{Keys: bson.D{{Key: "post_id", Value: 1}, {Key: "_id", Value: 1}}},
+		// 清除注销用户的评论
+		{Keys: bson.D{{Key: "uid", Value: 1}}},
+	},
+	FOLLOW_COLLECTION: {
+		// 关注关系唯一
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// 按被关注者查询粉丝列表
+		{Keys: bson.D{{Key: "followee_id", Value: 1}, {Key: "_id", Value: -1}}},
+		// 按关注者查询关注列表
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "_id", Value: -1}}},
+	},
+	MEDIA_COLLECTION: {
+		// 按上传者查询媒体文件
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "_id", Value: -1}}},
+	},
+	USER_LOGIN_LOGS_COLLECTION: {
+		// 按用户查询登录日志
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "_id", Value: -1}}},
+	},
+	PERSONAL_ACCESS_TOKEN_COLLECTION: {
+		// 按令牌摘要查询 令牌唯一
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// 按用户查询令牌列表
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "_id", Value: -1}}},
+	},
+	AUDIT_LOG_COLLECTION: {
+		// 按被操作用户查询审计日志
+		{Keys: bson.D{{Key: "target_id", Value: 1}, {Key: "_id", Value: -1}}},
+	},
+	INVITE_CODE_COLLECTION: {
+		// 邀请码唯一
+		{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// 按创建者查询邀请码
+		{Keys: bson.D{{Key: "creator_id", Value: 1}, {Key: "_id", Value: -1}}},
+	},
+	USER_INFO_COLLECTION: {
+		// 用户名唯一 避免并发注册或改名时重复
+		{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// 邮箱唯一 避免并发注册或修改邮箱时重复
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// 按邀请人查询被邀请用户
+		{Keys: bson.D{{Key: "invited_by", Value: 1}, {Key: "_id", Value: -1}}, Options: options.Index().SetSparse(true)},
+	},
+	USER_AUTH_INFO_COLLECTION: {
+		// 按用户名登录
+		{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// 按邮箱登录
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// 查询冷静期已结束的注销账号
+		{Keys: bson.D{{Key: "deletion_scheduled_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+	},
+	REPLY_COLLECTION: {
+		// 按评论查询顶层回复
+		{Keys: bson.D{{Key: "comment_id", Value: 1}, {Key: "parent_reply_id", Value: 1}, {Key: "_id", Value: 1}}},
+		// 按楼层查询子回复
+		{Keys: bson.D{{Key: "root_reply_id", Value: 1}, {Key: "_id", Value: 1}}},
+		// 清除注销用户的回复
+		{Keys: bson.D{{Key: "uid", Value: 1}}},
+	},
+	USERNAME_HISTORY_COLLECTION: {
+		// 按旧用户名查询跳转与保留
+		{Keys: bson.D{{Key: "old_name", Value: 1}, {Key: "_id", Value: -1}}},
+		// 清除注销用户的修改记录
+		{Keys: bson.D{{Key: "uid", Value: 1}}},
+	},
+	DATA_EXPORT_COLLECTION: {
+		// 按用户查询最近一次导出
+		{Keys: bson.D{{Key: "uid", Value: 1}, {Key: "_id", Value: -1}}},
+	},
+}
+
+/*
+SetupIndex 初始化数据库索引
+
+参数：
+  - database：MongoDB 数据库
+  - loginLogRetentionDays：登录日志保留天数
+
+返回：
+  - error：错误信息
+*/
+func SetupIndex(database *mongo.Database, loginLogRetentionDays int) error {
+	for collection, indexes := range collectionIndexes {
+		_, err := database.Collection(collection).Indexes().CreateMany(context.TODO(), indexes)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := setupTTLIndex(database, USER_LOGIN_LOGS_COLLECTION, "time", int32(loginLogRetentionDays*24*60*60))
+	if err != nil {
+		return err
+	}
+
+	// 导出记录按各自的过期时间删除
+	return setupTTLIndex(database, DATA_EXPORT_COLLECTION, "expires_at", 0)
+}
+
+/*
+setupTTLIndex 建立 TTL 索引 索引已存在且过期时间不同时更新其过期时间
+
+参数：
+  - database：MongoDB 数据库
+  - collection：集合名称
+  - field：时间字段
+  - expireAfterSeconds：过期时间
+
+返回：
+  - error：错误信息
+*/
+func setupTTLIndex(database *mongo.Database, collection string, field string, expireAfterSeconds int32) error {
+	keys := bson.D{{Key: field, Value: 1}}
+	_, err := database.Collection(collection).Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetExpireAfterSeconds(expireAfterSeconds),
+	})
+
+	// 索引选项冲突 说明保留时间已变更
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == MONGO_INDEX_OPTIONS_CONFLICT {
+		return database.RunCommand(context.TODO(), bson.D{
+			{Key: "collMod", Value: collection},
+			{Key: "index", Value: bson.D{
+				{Key: "keyPattern", Value: keys},
+				{Key: "expireAfterSeconds", Value: expireAfterSeconds},
+			}},
+		}).Err()
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+/*
+Package models - ZeWise 数据模型
+该文件用于声明邀请码模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InviteCode 邀请码
+type InviteCode struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`     // 主键
+	Code      string             `bson:"code"`              // 邀请码
+	CreatorID primitive.ObjectID `bson:"creator_id"`        // 创建者ID
+	MaxUses   int64              `bson:"max_uses"`          // 可使用次数
+	UsedCount int64              `bson:"used_count"`        // 已使用次数
+	Revoked   bool               `bson:"revoked,omitempty"` // 是否已作废
+	CreatedAt time.Time          `bson:"created_at"`        // 创建时间
+	ExpiresAt time.Time          `bson:"expires_at"`        // 过期时间
+}
+
+const INVITE_CODE_COLLECTION = "invite_codes"
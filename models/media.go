@@ -0,0 +1,32 @@
+/*
+Package models - ZeWise 数据库模型
+该文件用于声明媒体文件相关模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MediaInfo 媒体文件信息模型
+type MediaInfo struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`                // 主键
+	UID              primitive.ObjectID `bson:"uid,omitempty"`                // 上传者ID
+	MimeType         string             `bson:"mime_type,omitempty"`          // 存储的文件类型
+	OriginalMimeType string             `bson:"original_mime_type,omitempty"` // 上传时的文件类型
+	OriginalWidth    int                `bson:"original_width,omitempty"`     // 原始宽度
+	OriginalHeight   int                `bson:"original_height,omitempty"`    // 原始高度
+	Width            int                `bson:"width,omitempty"`              // 展示图宽度
+	Height           int                `bson:"height,omitempty"`             // 展示图高度
+	Size             int64              `bson:"size,omitempty"`               // 展示图大小
+	ThumbnailWidth   int                `bson:"thumbnail_width,omitempty"`    // 缩略图宽度
+	ThumbnailHeight  int                `bson:"thumbnail_height,omitempty"`   // 缩略图高度
+	ThumbnailSize    int64              `bson:"thumbnail_size,omitempty"`     // 缩略图大小
+	CreatedAt        time.Time          `bson:"created_at,omitempty"`         // 上传时间
+}
+
+const MEDIA_COLLECTION = "media"
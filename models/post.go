@@ -6,7 +6,11 @@ Copyright (c) [2024], Author(s):
 */
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // PostInfo 博文信息模型
 type PostInfo struct {
@@ -18,4 +22,10 @@ type PostInfo struct {
 	Content      string               `bson:"content,omitempty"`        // 内容
 	MediaIDs     []primitive.ObjectID `bson:"media_ids,omitempty"`      // 媒体ID
 	IsPublic     bool                 `bson:"is_public,omitempty"`      // 是否公开
+	IsDeleted    bool                 `bson:"is_deleted,omitempty"`     // 是否已删除
+	RepostCount  int64                `bson:"repost_count"`             // 转发数 包含引用转发
+	CreatedAt    time.Time            `bson:"created_at,omitempty"`     // 创建时间
+	UpdatedAt    time.Time            `bson:"updated_at,omitempty"`     // 更新时间
 }
+
+const POST_COLLECTION = "posts"
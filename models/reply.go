@@ -6,13 +6,25 @@ Copyright (c) [2024], Author(s):
 */
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 // ReplyInfo 评论信息模型
 type ReplyInfo struct {
 	ID            primitive.ObjectID `bson:"_id,omitempty"`             // 主键
-	ParentReplyID primitive.ObjectID `bson:"parent_reply_id,omitempty"` // 父回复ID
+	CommentID     primitive.ObjectID `bson:"comment_id,omitempty"`      // 所属评论ID
+	RootReplyID   primitive.ObjectID `bson:"root_reply_id,omitempty"`   // 所属楼层的顶层回复ID
+	ParentReplyID primitive.ObjectID `bson:"parent_reply_id,omitempty"` // 父回复ID 直接回复评论时为空
 	UID           primitive.ObjectID `bson:"uid,omitempty"`             // 用户ID
+	Username      string             `bson:"username,omitempty"`        // 用户名
 	Content       string             `bson:"content,omitempty"`         // 内容
 	IsPublic      bool               `bson:"is_public,omitempty"`       // 是否公开
+	IsDeleted     bool               `bson:"is_deleted,omitempty"`      // 是否已删除
+	ReplyCount    int64              `bson:"reply_count"`               // 楼层内回复数 仅顶层回复有效
+	CreatedAt     time.Time          `bson:"created_at,omitempty"`      // 创建时间
 }
+
+const REPLY_COLLECTION = "replies"
@@ -0,0 +1,13 @@
+/*
+Package models - ZeWise 数据模型
+该文件用于声明时间线相关模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+// REDIS_HOME_TIMELINE 首页时间线有序集合 成员为博文ID 分值均为 0 以按字典序即发布顺序排列
+const REDIS_HOME_TIMELINE = "TIMELINE:HOME"
+
+// REDIS_HOME_TIMELINE_BUILT 首页时间线已重建标记 仅重建时写入 推送只写入已重建的时间线
+const REDIS_HOME_TIMELINE_BUILT = "TIMELINE:HOME_BUILT"
@@ -0,0 +1,28 @@
+/*
+Package models - ZeWise 数据模型
+该文件用于声明个人访问令牌模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PersonalAccessTokenInfo 个人访问令牌信息 仅保存令牌摘要
+type PersonalAccessTokenInfo struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`          // 主键
+	UID        primitive.ObjectID `bson:"uid"`                    // 所属用户ID
+	Name       string             `bson:"name"`                   // 令牌名称
+	TokenHash  string             `bson:"token_hash"`             // 令牌摘要
+	Hint       string             `bson:"hint"`                   // 令牌末尾字符 便于用户辨认
+	Scopes     []string           `bson:"scopes"`                 // 权限范围
+	CreatedAt  time.Time          `bson:"created_at"`             // 创建时间
+	ExpiresAt  time.Time          `bson:"expires_at"`             // 过期时间
+	LastUsedAt time.Time          `bson:"last_used_at,omitempty"` // 最后使用时间
+}
+
+const PERSONAL_ACCESS_TOKEN_COLLECTION = "personal_access_tokens"
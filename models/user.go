@@ -14,16 +14,24 @@ import (
 
 // UserInfo 用户信息模型
 type UserInfo struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`       // 主键
-	UserName  string             `bson:"username,omitempty"`  // 用户名
-	NickName  string             `bson:"nickname,omitempty"`  // 昵称
-	Email     string             `bson:"email,omitempty"`     // 邮箱
-	Avatar    string             `bson:"avatar,omitempty"`    // 头像
-	Sign      string             `bson:"sign,omitempty"`      // 签名
-	Birth     time.Time          `bson:"birth,omitempty"`     // 生日
-	Gender    string             `bson:"gender,omitempty"`    // 性别
-	Authority uint64             `bson:"authority,omitempty"` // 权限等级
-	Level     uint64             `bson:"level,omitempty"`     // 等级
+	ID             primitive.ObjectID `bson:"_id,omitempty"`             // 主键
+	UserName       string             `bson:"username,omitempty"`        // 用户名
+	NickName       string             `bson:"nickname,omitempty"`        // 昵称
+	Email          string             `bson:"email,omitempty"`           // 邮箱
+	Avatar         string             `bson:"avatar,omitempty"`          // 头像
+	Sign           string             `bson:"sign,omitempty"`            // 签名
+	Birth          time.Time          `bson:"birth,omitempty"`           // 生日
+	Gender         string             `bson:"gender,omitempty"`          // 性别
+	Authority      uint64             `bson:"authority,omitempty"`       // 权限等级
+	Level          uint64             `bson:"level,omitempty"`           // 等级
+	FollowerCount  int64              `bson:"follower_count,omitempty"`  // 粉丝数
+	FollowingCount int64              `bson:"following_count,omitempty"` // 关注数
+	EmailVerified  bool               `bson:"email_verified,omitempty"`  // 邮箱是否已验证
+	InvitedBy      primitive.ObjectID `bson:"invited_by,omitempty"`      // 邀请人ID
+	InviteCodeID   primitive.ObjectID `bson:"invite_code_id,omitempty"`  // 注册时使用的邀请码ID
+	Deactivated    bool               `bson:"deactivated,omitempty"`     // 是否已申请注销 冷静期内资料不再公开
+
+	UsernameChangedAt time.Time `bson:"username_changed_at,omitempty"` // 最近一次修改用户名的时间
 }
 
 const USER_INFO_COLLECTION = "user_info"
@@ -33,8 +41,23 @@ type UserAuthInfo struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty"`      // 主键
 	UserName     string             `bson:"username,omitempty"` // 用户名
 	Email        string             `bson:"email,omitempty"`    // 邮箱
-	Salt         string             `bson:"salt,omitempty"`     // 盐
-	PasswordHash string             `bson:"psw_hash,omitempty"` // 密码哈希值
+	Salt         string             `bson:"salt,omitempty"`     // 盐 仅旧版 bcrypt 哈希使用
+	PasswordHash string             `bson:"psw_hash,omitempty"` // PHC 格式的密码哈希值
+
+	TOTPSecret    string   `bson:"totp_secret,omitempty"`    // TOTP 密钥
+	TOTPEnabled   bool     `bson:"totp_enabled,omitempty"`   // 是否开启两步验证
+	RecoveryCodes []string `bson:"recovery_codes,omitempty"` // 恢复码摘要
+
+	Banned         bool      `bson:"banned,omitempty"`          // 是否被永久封禁
+	SuspendedUntil time.Time `bson:"suspended_until,omitempty"` // 暂停使用截止时间
+	SuspendReason  string    `bson:"suspend_reason,omitempty"`  // 封禁或暂停使用原因
+
+	DeletionScheduledAt time.Time `bson:"deletion_scheduled_at,omitempty"` // 计划清除账号数据的时间 冷静期内登录即撤销
+	PurgeFailures       int       `bson:"purge_failures,omitempty"`        // 清除账号数据连续失败的次数
+	PurgeRetryAt        time.Time `bson:"purge_retry_at,omitempty"`        // 清除失败后下次重试的时间
 }
 
 const USER_AUTH_INFO_COLLECTION = "user_auth_info"
+
+// REDIS_ACCOUNT_PURGE_LOCK 注销账号清除任务锁 避免多个实例同时执行 值为持有者令牌
+const REDIS_ACCOUNT_PURGE_LOCK = "USER:PURGE_LOCK"
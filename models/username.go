@@ -0,0 +1,26 @@
+/*
+Package models - ZeWise 数据库模型
+该文件用于声明用户名修改记录模型
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UsernameHistory 用户名修改记录模型
+type UsernameHistory struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`            // 主键
+	UID           primitive.ObjectID `bson:"uid,omitempty"`            // 用户ID
+	OldName       string             `bson:"old_name,omitempty"`       // 旧用户名
+	NewName       string             `bson:"new_name,omitempty"`       // 新用户名
+	ChangedAt     time.Time          `bson:"changed_at,omitempty"`     // 修改时间
+	RedirectUntil time.Time          `bson:"redirect_until,omitempty"` // 旧用户名跳转至该用户的截止时间
+	ReservedUntil time.Time          `bson:"reserved_until,omitempty"` // 旧用户名保留的截止时间
+}
+
+const USERNAME_HISTORY_COLLECTION = "username_history"
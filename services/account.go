@@ -0,0 +1,306 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明账号注销相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/encryptors"
+	"zewise.space/backend/utils/functools"
+	"zewise.space/backend/utils/generators"
+	"zewise.space/backend/utils/imagetools"
+)
+
+// AccountDeletionConfig 账号注销设置
+type AccountDeletionConfig struct {
+	GraceDays     int    // 冷静期天数
+	ContentPolicy string // 清除账号时博文与评论的处理方式 anonymize, remove
+}
+
+/*
+RequestAccountDeletion 申请注销账号 账号立即停用并吊销全部会话与个人访问令牌 冷静期内重新登录即撤销申请
+
+参数：
+  - userID：用户ID
+  - password：密码
+
+返回：
+  - time.Time：计划清除账号数据的时间
+  - error：错误信息
+*/
+func (service *UserService) RequestAccountDeletion(userID primitive.ObjectID, password string) (time.Time, error) {
+	purgeAt := time.Now().AddDate(0, 0, service.Deletion.GraceDays)
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return time.Time{}, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取用户认证信息
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !authInfo.DeletionScheduledAt.IsZero() {
+			return nil, types.NewError(types.ErrInvalidParams, "已申请注销账号")
+		}
+
+		// 校验密码
+		_, err = encryptors.VerifyPassword(authInfo.PasswordHash, password, authInfo.Salt)
+		if err != nil {
+			return nil, types.NewError(types.ErrInvalidParams, "密码错误")
+		}
+
+		// 停用账号并吊销个人访问令牌
+		err = service.Storage.UserStorage.ScheduleAccountDeletion(sessionContext, userID, purgeAt)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.Storage.AuthStorage.DeleteAllPersonalAccessTokens(sessionContext, userID)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// 吊销全部会话
+	err = service.Storage.AuthStorage.RemoveAllSessions(userID.Hex())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return purgeAt, nil
+}
+
+/*
+PurgeDueAccounts 清除冷静期已结束的注销账号 多个实例同时调用时仅有一个实例执行
+
+返回：
+  - int：已清除的账号数量
+  - error：错误信息
+*/
+func (service *UserService) PurgeDueAccounts() (int, error) {
+	// 获取任务锁
+	lockToken, err := generators.GenerateSalt(consts.ACCOUNT_PURGE_LOCK_TOKEN_LENGTH)
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+	ok, err := service.Storage.UserStorage.AcquirePurgeLock(lockToken)
+	if err != nil || !ok {
+		return 0, err
+	}
+	defer service.Storage.UserStorage.ReleasePurgeLock(lockToken)
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 获取待清除的账号
+	var userIDs []primitive.ObjectID
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		userIDs, err = service.Storage.UserStorage.GetAccountsDueForPurge(sessionContext, time.Now(), consts.ACCOUNT_PURGE_BATCH_SIZE)
+		return nil, err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 逐个清除 单个账号失败不影响其余账号 失败的账号推迟重试
+	purged := 0
+	var errs []error
+	for _, userID := range userIDs {
+		ok, err := service.purgeAccount(userID)
+		if ok {
+			purged++
+		}
+		if err != nil {
+			errs = append(errs, err)
+			_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+				return nil, service.Storage.UserStorage.RecordPurgeFailure(sessionContext, userID, time.Now())
+			})
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+/*
+purgeAccount 清除账号的全部数据 并按设置匿名化或删除其博文与评论
+
+参数：
+  - userID：用户ID
+
+返回：
+  - bool：是否已清除 账号已撤销注销时为 false
+  - error：错误信息
+*/
+func (service *UserService) purgeAccount(userID primitive.ObjectID) (bool, error) {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var purged bool
+	var userInfo models.UserInfo
+	var media []models.MediaInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		purged = false
+		media = nil
+
+		// 确认账号仍处于待清除状态 以免清除期间撤销的账号被误删
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		if authInfo.DeletionScheduledAt.IsZero() || authInfo.DeletionScheduledAt.After(time.Now()) {
+			return nil, nil
+		}
+		userInfo, err = service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 解除关注关系
+		followerIDs, err := service.Storage.FollowStorage.GetFollowerIDs(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		followingIDs, err := service.Storage.FollowStorage.GetFollowingIDs(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		err = service.Storage.FollowStorage.DeleteUserFollows(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		err = service.Storage.UserStorage.DecreaseFollowCounts(sessionContext, followerIDs, followingIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// 处理博文、评论与回复
+		switch service.Deletion.ContentPolicy {
+		case consts.ACCOUNT_DELETION_POLICY_REMOVE:
+			err = service.Storage.PostStorage.RemoveUserPosts(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+			err = service.Storage.CommentStorage.RemoveUserComments(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+			err = service.Storage.ReplyStorage.RemoveUserReplies(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+			media, err = service.Storage.MediaStorage.GetMediaByUser(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+			err = service.Storage.MediaStorage.DeleteMediaByUser(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			err = service.Storage.PostStorage.AnonymizeUserPosts(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+			err = service.Storage.CommentStorage.AnonymizeUserComments(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+			err = service.Storage.ReplyStorage.AnonymizeUserReplies(sessionContext, userID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// 删除令牌、登录日志、导出记录与账号
+		err = service.Storage.AuthStorage.DeleteAllPersonalAccessTokens(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		err = service.Storage.AuthStorage.DeleteLoginLogs(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		err = service.Storage.ExportStorage.DeleteDataExports(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		err = service.Storage.UserStorage.DeleteUserAccount(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		purged = true
+		return nil, nil
+	})
+	if err != nil || !purged {
+		return false, err
+	}
+
+	// 账号已删除 以下清理失败不影响结果 仅汇总返回
+	var errs []error
+
+	// 删除头像、媒体文件与导出归档
+	errs = append(errs, service.Storage.ExportStorage.DeleteExportFiles(ctx, functools.JoinStrings(userID.Hex(), "/")))
+	if userInfo.Avatar != "vanilla" {
+		avatarEncoder := imagetools.NewWebpImageEncoder(consts.AVATAR_QUALITY)
+		err = service.Storage.UserStorage.DeleteAvatarFile(ctx, functools.JoinStrings(userInfo.Avatar, ".", avatarEncoder.GetFormatFileSuffix()))
+		if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+			errs = append(errs, err)
+		}
+	}
+	mediaEncoder := imagetools.NewWebpImageEncoder(consts.MEDIA_QUALITY)
+	for _, mediaInfo := range media {
+		for _, fileName := range []string{
+			functools.JoinStrings(mediaInfo.ID.Hex(), ".", mediaEncoder.GetFormatFileSuffix()),
+			functools.JoinStrings(mediaInfo.ID.Hex(), consts.MEDIA_THUMBNAIL_SUFFIX, ".", mediaEncoder.GetFormatFileSuffix()),
+		} {
+			err = service.Storage.MediaStorage.DeleteMediaFile(ctx, fileName)
+			if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// 清除 Redis 中的会话、时间线与登录状态
+	errs = append(errs,
+		service.Storage.AuthStorage.RemoveAllSessions(userID.Hex()),
+		service.Storage.TimelineStorage.DeleteTimeline(ctx, userID),
+		service.Storage.AuthStorage.DeleteSuspensionMarker(userID.Hex()),
+		service.Storage.AuthStorage.ClearLoginFailures(userID.Hex()),
+	)
+
+	return true, errors.Join(errs...)
+}
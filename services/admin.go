@@ -0,0 +1,559 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明管理相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+)
+
+// AdminService 管理服务
+type AdminService struct {
+	Storage *stores.Storage
+}
+
+/*
+GrantRole 授予用户角色 并记录审计日志
+
+参数：
+  - operatorID：操作者ID
+  - targetID：被操作用户ID
+  - roleName：角色名称
+  - reason：操作原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) GrantRole(operatorID primitive.ObjectID, targetID string, roleName string, reason string, ip string) error {
+	// 解析角色
+	role, ok := parseRoleName(roleName)
+	if !ok {
+		return types.NewError(types.ErrInvalidParams, "未知的角色："+roleName)
+	}
+
+	return service.updateRole(operatorID, targetID, role, consts.AUDIT_ACTION_ROLE_GRANT, reason, ip)
+}
+
+/*
+RevokeRole 撤销用户角色 将其恢复为普通用户 并记录审计日志
+
+参数：
+  - operatorID：操作者ID
+  - targetID：被操作用户ID
+  - reason：操作原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) RevokeRole(operatorID primitive.ObjectID, targetID string, reason string, ip string) error {
+	return service.updateRole(operatorID, targetID, consts.ROLE_USER, consts.AUDIT_ACTION_ROLE_REVOKE, reason, ip)
+}
+
+/*
+BootstrapAdmins 将配置中指定的用户设为管理员 用于创建首个管理员 已是管理员的用户将被跳过
+
+参数：
+  - userIDs：用户ID列表
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) BootstrapAdmins(userIDs []string) error {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	for _, userID := range userIDs {
+		targetObjID, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return types.NewError(types.ErrInvalidParams, "不合法的用户ID："+userID)
+		}
+
+		// 开启事务
+		_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+			userInfo, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, targetObjID)
+			if err != nil {
+				return nil, err
+			}
+			if userInfo.Authority == consts.ROLE_ADMIN {
+				return nil, nil
+			}
+
+			// 更新角色
+			err = service.Storage.UserStorage.UpdateUserAuthority(sessionContext, targetObjID, consts.ROLE_ADMIN)
+			if err != nil {
+				return nil, err
+			}
+
+			// 记录审计日志
+			return nil, service.Storage.AuditStorage.RecordAuditLog(sessionContext, models.AuditLog{
+				TargetID: targetObjID,
+				Action:   consts.AUDIT_ACTION_ROLE_BOOTSTRAP,
+				Before:   consts.ROLE_NAMES[userInfo.Authority],
+				After:    consts.ROLE_NAMES[consts.ROLE_ADMIN],
+				Reason:   "由配置 admin.bootstrap_user_ids 授予",
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+GetAuditLogs 分页获取审计日志
+
+参数：
+  - targetID：被操作用户ID 为空时获取全部日志
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.AuditLog：审计日志列表
+  - error：错误信息
+*/
+func (service *AdminService) GetAuditLogs(targetID string, cursor primitive.ObjectID, limit int64) ([]models.AuditLog, error) {
+	// 转换用户ID
+	var targetObjID primitive.ObjectID
+	if targetID != "" {
+		var err error
+		targetObjID, err = primitive.ObjectIDFromHex(targetID)
+		if err != nil {
+			return nil, types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+		}
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var logs []models.AuditLog
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		logs, err = service.Storage.AuditStorage.GetAuditLogs(sessionContext, targetObjID, cursor, limit)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+/*
+updateRole 更新用户角色 并记录审计日志
+
+参数：
+  - operatorID：操作者ID
+  - targetID：被操作用户ID
+  - role：新角色
+  - action：审计操作类型
+  - reason：操作原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) updateRole(operatorID primitive.ObjectID, targetID string, role uint64, action string, reason string, ip string) error {
+	// 校验参数
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+	}
+	if targetObjID == operatorID {
+		return types.NewError(types.ErrInvalidParams, "不能修改自己的角色")
+	}
+	if utf8.RuneCountInString(reason) > consts.AUDIT_REASON_MAX_LENGTH {
+		return types.NewError(types.ErrInvalidParams, "操作原因过长")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取用户当前角色
+		userInfo, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, targetObjID)
+		if err != nil {
+			return nil, err
+		}
+		if userInfo.Authority == role {
+			return nil, types.NewError(types.ErrInvalidParams, "用户已是该角色")
+		}
+
+		// 更新角色
+		err = service.Storage.UserStorage.UpdateUserAuthority(sessionContext, targetObjID, role)
+		if err != nil {
+			return nil, err
+		}
+
+		// 记录审计日志
+		return nil, service.Storage.AuditStorage.RecordAuditLog(sessionContext, models.AuditLog{
+			OperatorID: operatorID,
+			TargetID:   targetObjID,
+			Action:     action,
+			Before:     consts.ROLE_NAMES[userInfo.Authority],
+			After:      consts.ROLE_NAMES[role],
+			Reason:     reason,
+			IP:         ip,
+		})
+	})
+
+	return err
+}
+
+/*
+GetUserDetail 查询用户资料与认证状态 按用户ID 用户名 邮箱的顺序使用首个非空条件
+
+参数：
+  - userID：用户ID
+  - username：用户名
+  - email：邮箱
+
+返回：
+  - models.UserInfo：用户信息
+  - models.UserAuthInfo：用户认证信息
+  - error：错误信息
+*/
+func (service *AdminService) GetUserDetail(userID string, username string, email string) (models.UserInfo, models.UserAuthInfo, error) {
+	var userInfo models.UserInfo
+	var authInfo models.UserAuthInfo
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return userInfo, authInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取用户信息
+		switch {
+		case userID != "":
+			objID, parseErr := primitive.ObjectIDFromHex(userID)
+			if parseErr != nil {
+				return nil, types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+			}
+			userInfo, err = service.Storage.UserStorage.GetUserDataByID(sessionContext, objID)
+		case username != "":
+			userInfo, err = service.Storage.UserStorage.GetUserDataByUsername(sessionContext, username)
+		default:
+			userInfo, err = service.Storage.UserStorage.GetUserDataByEmail(sessionContext, email)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// 获取用户认证信息
+		authInfo, err = service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userInfo.ID)
+		return nil, err
+	})
+	if err != nil {
+		return userInfo, authInfo, err
+	}
+
+	return userInfo, authInfo, nil
+}
+
+/*
+SuspendUser 暂停使用账号 并吊销其全部令牌
+
+参数：
+  - operatorID：操作者ID
+  - operatorAuthority：操作者角色
+  - targetID：被操作用户ID
+  - duration：暂停使用时长（秒）
+  - reason：原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) SuspendUser(operatorID primitive.ObjectID, operatorAuthority uint64, targetID string, duration int64, reason string, ip string) error {
+	if duration < consts.SUSPEND_MIN_DURATION || duration > consts.SUSPEND_MAX_DURATION {
+		return types.NewError(types.ErrInvalidParams, "暂停使用时长不合法")
+	}
+	until := time.Now().Add(time.Duration(duration) * time.Second)
+
+	return service.suspendUser(operatorID, operatorAuthority, targetID, until, false, reason, ip)
+}
+
+/*
+BanUser 永久封禁账号 并吊销其全部令牌
+
+参数：
+  - operatorID：操作者ID
+  - operatorAuthority：操作者角色
+  - targetID：被操作用户ID
+  - reason：原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) BanUser(operatorID primitive.ObjectID, operatorAuthority uint64, targetID string, reason string, ip string) error {
+	return service.suspendUser(operatorID, operatorAuthority, targetID, time.Time{}, true, reason, ip)
+}
+
+/*
+UnsuspendUser 解除账号的暂停使用或封禁
+
+参数：
+  - operatorID：操作者ID
+  - operatorAuthority：操作者角色
+  - targetID：被操作用户ID
+  - reason：原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) UnsuspendUser(operatorID primitive.ObjectID, operatorAuthority uint64, targetID string, reason string, ip string) error {
+	targetObjID, err := service.moderateUser(operatorID, operatorAuthority, targetID, reason, func(sessionContext mongo.SessionContext, authInfo models.UserAuthInfo) (models.AuditLog, error) {
+		if checkAccountSuspension(authInfo) == nil {
+			return models.AuditLog{}, types.NewError(types.ErrInvalidParams, "账号未被停用")
+		}
+		if authInfo.Banned && !slices.Contains(consts.ROLE_PERMISSIONS[operatorAuthority], consts.PERMISSION_USER_BAN) {
+			return models.AuditLog{}, types.NewError(types.ErrAuthFailed, "权限不足 无法解除封禁")
+		}
+
+		err := service.Storage.AuthStorage.UnsuspendUser(sessionContext, authInfo.ID)
+		return models.AuditLog{
+			Action: consts.AUDIT_ACTION_USER_UNSUSPEND,
+			Before: suspensionState(authInfo.Banned, authInfo.SuspendedUntil),
+			IP:     ip,
+		}, err
+	})
+	if err != nil {
+		return err
+	}
+
+	return service.Storage.AuthStorage.DeleteSuspensionMarker(targetObjID.Hex())
+}
+
+/*
+ForceLogout 强制登出用户 吊销其全部会话与个人访问令牌
+
+参数：
+  - operatorID：操作者ID
+  - operatorAuthority：操作者角色
+  - targetID：被操作用户ID
+  - reason：原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) ForceLogout(operatorID primitive.ObjectID, operatorAuthority uint64, targetID string, reason string, ip string) error {
+	targetObjID, err := service.moderateUser(operatorID, operatorAuthority, targetID, reason, func(sessionContext mongo.SessionContext, authInfo models.UserAuthInfo) (models.AuditLog, error) {
+		err := service.Storage.AuthStorage.DeleteAllPersonalAccessTokens(sessionContext, authInfo.ID)
+		return models.AuditLog{Action: consts.AUDIT_ACTION_USER_LOGOUT, IP: ip}, err
+	})
+	if err != nil {
+		return err
+	}
+
+	return service.Storage.AuthStorage.RemoveAllSessions(targetObjID.Hex())
+}
+
+/*
+suspendUser 暂停使用或封禁账号 设置停用标记并吊销其全部令牌
+
+参数：
+  - operatorID：操作者ID
+  - operatorAuthority：操作者角色
+  - targetID：被操作用户ID
+  - until：暂停使用截止时间 封禁时忽略
+  - banned：是否永久封禁
+  - reason：原因
+  - ip：操作者 IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (service *AdminService) suspendUser(operatorID primitive.ObjectID, operatorAuthority uint64, targetID string, until time.Time, banned bool, reason string, ip string) error {
+	action := consts.AUDIT_ACTION_USER_SUSPEND
+	if banned {
+		action = consts.AUDIT_ACTION_USER_BAN
+	}
+
+	targetObjID, err := service.moderateUser(operatorID, operatorAuthority, targetID, reason, func(sessionContext mongo.SessionContext, authInfo models.UserAuthInfo) (models.AuditLog, error) {
+		err := service.Storage.AuthStorage.SuspendUser(sessionContext, authInfo.ID, until, banned, reason)
+		if err != nil {
+			return models.AuditLog{}, err
+		}
+
+		err = service.Storage.AuthStorage.DeleteAllPersonalAccessTokens(sessionContext, authInfo.ID)
+		return models.AuditLog{
+			Action: action,
+			Before: suspensionState(authInfo.Banned, authInfo.SuspendedUntil),
+			After:  suspensionState(banned, until),
+			IP:     ip,
+		}, err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 设置停用标记 已签发的访问令牌随即失效
+	var expiration time.Duration
+	if !banned {
+		expiration = time.Until(until)
+	}
+	err = service.Storage.AuthStorage.SetSuspensionMarker(targetObjID.Hex(), suspensionMessage(banned, until, reason), expiration)
+	if err != nil {
+		return err
+	}
+
+	// 吊销全部会话与刷新令牌
+	return service.Storage.AuthStorage.RemoveAllSessions(targetObjID.Hex())
+}
+
+/*
+moderateUser 在事务中处理违规用户 并记录审计日志
+操作者不能处理自己或角色不低于自己的用户
+
+参数：
+  - operatorID：操作者ID
+  - operatorAuthority：操作者角色
+  - targetID：被操作用户ID
+  - reason：原因
+  - apply：处理函数 返回待记录的审计日志
+
+返回：
+  - primitive.ObjectID：被操作用户ID
+  - error：错误信息
+*/
+func (service *AdminService) moderateUser(
+	operatorID primitive.ObjectID,
+	operatorAuthority uint64,
+	targetID string,
+	reason string,
+	apply func(sessionContext mongo.SessionContext, authInfo models.UserAuthInfo) (models.AuditLog, error),
+) (primitive.ObjectID, error) {
+	// 校验参数
+	targetObjID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return targetObjID, types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+	}
+	if targetObjID == operatorID {
+		return targetObjID, types.NewError(types.ErrInvalidParams, "不能处理自己的账号")
+	}
+	if utf8.RuneCountInString(reason) > consts.AUDIT_REASON_MAX_LENGTH {
+		return targetObjID, types.NewError(types.ErrInvalidParams, "操作原因过长")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return targetObjID, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验被操作用户的角色
+		userInfo, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, targetObjID)
+		if err != nil {
+			return nil, err
+		}
+		if userInfo.Authority >= operatorAuthority {
+			return nil, types.NewError(types.ErrAuthFailed, "不能处理角色不低于自己的用户")
+		}
+
+		// 获取用户认证信息
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, targetObjID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 执行处理
+		auditLog, err := apply(sessionContext, authInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		// 记录审计日志
+		auditLog.OperatorID = operatorID
+		auditLog.TargetID = targetObjID
+		auditLog.Reason = reason
+		return nil, service.Storage.AuditStorage.RecordAuditLog(sessionContext, auditLog)
+	})
+
+	return targetObjID, err
+}
+
+/*
+suspensionState 生成用于审计日志的账号停用状态
+
+参数：
+  - banned：是否永久封禁
+  - until：暂停使用截止时间
+
+返回：
+  - string：账号停用状态
+*/
+func suspensionState(banned bool, until time.Time) string {
+	if banned {
+		return "banned"
+	}
+	if time.Now().Before(until) {
+		return "suspended until " + until.Format(time.RFC3339)
+	}
+
+	return "active"
+}
+
+/*
+parseRoleName 由角色名称获取角色
+
+参数：
+  - roleName：角色名称
+
+返回：
+  - uint64：角色
+  - bool：角色是否存在
+*/
+func parseRoleName(roleName string) (uint64, bool) {
+	for role, name := range consts.ROLE_NAMES {
+		if name == roleName {
+			return role, true
+		}
+	}
+
+	return 0, false
+}
@@ -8,8 +8,14 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
 	"os"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/mssola/useragent"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -20,35 +26,63 @@ import (
 	"zewise.space/backend/utils/encryptors"
 	"zewise.space/backend/utils/functools"
 	"zewise.space/backend/utils/generators"
+	"zewise.space/backend/utils/mailers"
 	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/signers"
 	"zewise.space/backend/utils/thirdparty"
+	"zewise.space/backend/utils/validers"
 )
 
 // AuthService 认证服务
 type AuthService struct {
 	Storage *stores.Storage
+	Mailer  mailers.Mailer
+}
+
+// LoginResult 登录结果 开启两步验证的用户仅返回挑战令牌
+type LoginResult struct {
+	Token          string // 访问令牌
+	RefreshToken   string // 刷新令牌
+	ChallengeToken string // 两步验证挑战令牌
 }
 
 /*
-AuthLogin 用户登录
+AuthLogin 用户登录 开启两步验证时返回挑战令牌 验证通过后才签发令牌
 
 参数：
   - email：邮箱
   - username：用户名
   - password：密码
+  - captchaID：图片验证码ID 账号或 IP 登录失败次数过多时需要提供
+  - captchaAnswer：图片验证码答案
+  - ip：IP 地址
+  - userAgent：用户代理
 
 返回：
-  - token：JWT 令牌
+  - LoginResult：登录结果
   - error：错误信息
 */
-func (service *AuthService) AuthLogin(email string, username string, password string, ip string, userAgent *useragent.UserAgent) (string, error) {
-	var token = ""
+func (service *AuthService) AuthLogin(email string, username string, password string, captchaID string, captchaAnswer string, ip string, userAgent *useragent.UserAgent) (LoginResult, error) {
+	var result LoginResult
+	var failedUser models.UserAuthInfo    // 密码错误的用户
+	var rejectedUserID primitive.ObjectID // 因锁定被拒绝的用户
+	var unknownUser bool                  // 账号是否不存在
+	var captchaVerified bool              // 验证码是否已通过 验证码仅可使用一次 事务重试时不再重复校验
+
+	// 检查 IP 是否被锁定
+	ipLock, err := service.Storage.AuthStorage.GetIPLock(ip)
+	if err != nil {
+		return result, err
+	}
+	if ipLock > 0 {
+		return result, types.NewError(types.ErrAuthFailed, fmt.Sprintf("该 IP 登录失败次数过多 请于 %d 分钟后重试", int(math.Ceil(ipLock.Minutes()))))
+	}
 
 	// 创建数据库会话
 	ctx := context.Background()
 	session, err := service.Storage.NewSession()
 	if err != nil {
-		return "", types.NewError(types.ErrServerError, err.Error())
+		return result, types.NewError(types.ErrServerError, err.Error())
 	}
 	defer session.EndSession(ctx)
 
@@ -61,106 +95,778 @@ func (service *AuthService) AuthLogin(email string, username string, password st
 		} else {
 			userAuthInfo, err = service.Storage.AuthStorage.GetUserAuthInfoByUsername(sessionCtx, username)
 		}
+		unknownUser = errors.Is(err, types.ErrInvalidParams)
 		if err != nil {
 			return nil, err
 		}
 
-		// 校验密码
-		err = encryptors.CompareHashPassword(userAuthInfo.PasswordHash, password, userAuthInfo.Salt)
+		// 检查账号是否被锁定
+		accountLock, err := service.Storage.AuthStorage.GetAccountLock(userAuthInfo.ID.Hex())
 		if err != nil {
-			return nil, types.NewError(types.ErrInvalidParams, "邮箱或密码错误")
+			return nil, err
+		}
+		if accountLock > 0 {
+			rejectedUserID = userAuthInfo.ID
+			return nil, types.NewError(types.ErrAuthFailed, fmt.Sprintf(
+				"账号已被临时锁定 请于 %d 分钟后重试或使用邮件中的解锁令牌解锁", int(math.Ceil(accountLock.Minutes())),
+			))
 		}
 
-		// 生成 JWT 令牌
-		token, _, err = generators.GenerateToken(userAuthInfo.ID, userAuthInfo.UserName)
+		// 检查是否处于重试等待中
+		delay, err := service.Storage.AuthStorage.GetLoginDelay(userAuthInfo.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if delay > 0 {
+			return nil, types.NewError(types.ErrAuthFailed, fmt.Sprintf(
+				"登录失败次数过多 请于 %d 秒后重试", int(math.Ceil(delay.Seconds())),
+			))
+		}
+
+		// 失败次数过多时需通过图片验证码
+		if !captchaVerified {
+			accountFailures, ipFailures, err := service.Storage.AuthStorage.GetLoginFailures(userAuthInfo.ID.Hex(), ip)
+			if err != nil {
+				return nil, err
+			}
+			if accountFailures >= consts.CAPTCHA_LOGIN_FAILURE_THRESHOLD || ipFailures >= consts.CAPTCHA_IP_FAILURE_THRESHOLD {
+				err = verifyCaptcha(service.Storage, captchaID, captchaAnswer)
+				if err != nil {
+					return nil, err
+				}
+				captchaVerified = true
+			}
+		}
+
+		// 校验密码
+		needsRehash, err := encryptors.VerifyPassword(userAuthInfo.PasswordHash, password, userAuthInfo.Salt)
+		if errors.Is(err, encryptors.ErrPasswordMismatch) {
+			failedUser = userAuthInfo
+			return nil, types.NewError(types.ErrInvalidParams, "邮箱或密码错误")
+		}
 		if err != nil {
 			return nil, types.NewError(types.ErrServerError, err.Error())
 		}
 
-		// 获取令牌数量
-		tokens, err := service.Storage.AuthStorage.GetAvailableToken(userAuthInfo.ID.Hex())
+		// 检查账号是否已停用
+		err = checkAccountSuspension(userAuthInfo)
 		if err != nil {
 			return nil, err
 		}
 
-		// 超过最大令牌数量
-		if len(tokens) >= consts.MAX_TOKENS_PER_USER {
-			err = service.Storage.AuthStorage.RemoveEarliestToken(userAuthInfo.ID.Hex(), 1)
+		// 使用当前算法与参数重新哈希旧密码
+		if needsRehash {
+			hashedPassword, err := encryptors.HashPassword(password)
+			if err != nil {
+				return nil, types.NewError(types.ErrServerError, err.Error())
+			}
+			err = service.Storage.UserStorage.UpdateUserPassword(sessionCtx, userAuthInfo.ID, hashedPassword)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// 开启两步验证时创建挑战 暂不签发令牌
+		if userAuthInfo.TOTPEnabled {
+			result.ChallengeToken, err = generators.GenerateSalt(consts.TOTP_CHALLENGE_TOKEN_LENGTH)
+			if err != nil {
+				return nil, types.NewError(types.ErrServerError, err.Error())
+			}
+			err = service.Storage.AuthStorage.SaveLoginChallenge(result.ChallengeToken, userAuthInfo.ID.Hex())
 			if err != nil {
 				return nil, err
 			}
+		} else {
+			result.Token, result.RefreshToken, err = service.issueSession(sessionCtx, userAuthInfo, ip, userAgent)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// 清除失败计数
+		err = service.Storage.AuthStorage.ClearLoginFailures(userAuthInfo.ID.Hex())
+		return nil, err
+	})
+
+	// 登录失败时在事务外记录 以免随事务回滚
+	if !failedUser.ID.IsZero() {
+		locked, recordErr := service.handleLoginFailure(failedUser, ip)
+		if recordErr != nil {
+			return result, recordErr
+		}
+		recordErr = service.recordFailedLogin(session, failedUser.ID, ip, userAgent, locked)
+		if recordErr != nil {
+			return result, recordErr
+		}
+		if locked {
+			err = types.NewError(types.ErrAuthFailed, "登录失败次数过多 账号已被临时锁定 解锁令牌已发送至邮箱")
 		}
+	}
+	if unknownUser {
+		ipFailures, recordErr := service.Storage.AuthStorage.RecordIPFailure(ip)
+		if recordErr != nil {
+			return result, recordErr
+		}
+		recordErr = service.lockIPOnFailures(ip, ipFailures)
+		if recordErr != nil {
+			return result, recordErr
+		}
+	}
+	if !rejectedUserID.IsZero() {
+		recordErr := service.recordFailedLogin(session, rejectedUserID, ip, userAgent, true)
+		if recordErr != nil {
+			return result, recordErr
+		}
+	}
+
+	var typedErr types.Error
+	if errors.As(err, &typedErr) {
+		return result, err
+	}
+	if err != nil {
+		return result, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result, nil
+}
+
+/*
+checkAccountSuspension 检查账号是否被暂停使用或封禁
+
+参数：
+  - userAuthInfo：用户认证信息
+
+返回：
+  - error：账号已停用时返回 types.ErrAccountSuspended 错误
+*/
+func checkAccountSuspension(userAuthInfo models.UserAuthInfo) error {
+	if !userAuthInfo.Banned && !time.Now().Before(userAuthInfo.SuspendedUntil) {
+		return nil
+	}
+
+	return types.NewError(types.ErrAccountSuspended, suspensionMessage(userAuthInfo.Banned, userAuthInfo.SuspendedUntil, userAuthInfo.SuspendReason))
+}
 
-		// 保存令牌
-		err = service.Storage.AuthStorage.SaveToken(userAuthInfo.ID.Hex(), token)
+/*
+suspensionMessage 生成账号停用提示信息
+
+参数：
+  - banned：是否永久封禁
+  - until：暂停使用截止时间
+  - reason：原因
+
+返回：
+  - string：提示信息
+*/
+func suspensionMessage(banned bool, until time.Time, reason string) string {
+	message := "账号已被封禁"
+	if !banned {
+		message = "账号已被暂停使用至 " + until.Format(time.DateTime)
+	}
+	if reason != "" {
+		message = message + "：" + reason
+	}
+
+	return message
+}
+
+/*
+issueSession 为已通过认证的用户创建会话 并签发访问令牌与刷新令牌
+
+参数：
+  - sessionCtx：数据库会话上下文
+  - userAuthInfo：用户认证信息
+  - ip：IP 地址
+  - userAgent：用户代理
+
+返回：
+  - string：访问令牌
+  - string：刷新令牌
+  - error：错误信息
+*/
+func (service *AuthService) issueSession(sessionCtx mongo.SessionContext, userAuthInfo models.UserAuthInfo, ip string, userAgent *useragent.UserAgent) (string, string, error) {
+	// 注销冷静期内登录即撤销注销申请
+	if !userAuthInfo.DeletionScheduledAt.IsZero() {
+		err := service.Storage.UserStorage.CancelAccountDeletion(sessionCtx, userAuthInfo.ID)
 		if err != nil {
-			return nil, err
+			return "", "", err
+		}
+	}
+
+	// 生成访问令牌 并以新的令牌族作为会话
+	familyID := uuid.New().String()
+	token, claims, err := generators.GenerateToken(userAuthInfo.ID, userAuthInfo.UserName, familyID)
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 生成刷新令牌
+	refreshToken, err := generators.GenerateRefreshToken()
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 获取登录客户端信息
+	location, device, application := resolveLoginClient(ip, userAgent)
+
+	// 写入登录日志
+	err = service.Storage.AuthStorage.RecordLoginEvent(
+		sessionCtx,
+		userAuthInfo.ID,
+		ip,
+		location,
+		device,
+		application,
+		true,
+		false,
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 创建会话 超过最大会话数量时吊销最早的会话
+	_, err = service.Storage.AuthStorage.CreateSession(models.SessionInfo{
+		ID:          familyID,
+		UID:         userAuthInfo.ID.Hex(),
+		JTI:         claims.ID,
+		IP:          ip,
+		Location:    location,
+		Device:      device,
+		Application: application,
+		IssuedAt:    claims.IssuedAt.Time,
+		LastUsedAt:  claims.IssuedAt.Time,
+	}, consts.MAX_TOKENS_PER_USER)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 保存刷新令牌
+	err = service.Storage.AuthStorage.SaveRefreshToken(encryptors.HashToken(refreshToken), models.RefreshTokenInfo{
+		UID:       userAuthInfo.ID.Hex(),
+		UserName:  userAuthInfo.UserName,
+		FamilyID:  familyID,
+		AccessJTI: claims.ID,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+/*
+handleLoginFailure 累加登录失败次数 并按失败次数设置重试等待或锁定账号 锁定时向用户发送解锁邮件
+
+参数：
+  - userAuthInfo：用户认证信息
+  - ip：IP 地址
+
+返回：
+  - bool：账号是否因本次失败被锁定
+  - error：错误信息
+*/
+func (service *AuthService) handleLoginFailure(userAuthInfo models.UserAuthInfo, ip string) (bool, error) {
+	userID := userAuthInfo.ID.Hex()
+
+	// 累加失败次数
+	accountFailures, ipFailures, err := service.Storage.AuthStorage.RecordLoginFailure(userID, ip)
+	if err != nil {
+		return false, err
+	}
+
+	err = service.lockIPOnFailures(ip, ipFailures)
+	if err != nil {
+		return false, err
+	}
+
+	// 账号失败次数过多时锁定账号
+	if accountFailures >= consts.ACCOUNT_LOCK_THRESHOLD {
+		err = service.Storage.AuthStorage.LockAccount(userID)
+		if err != nil {
+			return true, err
+		}
+
+		// 发送解锁邮件 发送失败时仍可等待锁定到期
+		token, err := generators.GenerateSalt(consts.ACCOUNT_UNLOCK_TOKEN_LENGTH)
+		if err != nil {
+			return true, types.NewError(types.ErrServerError, err.Error())
+		}
+		err = service.Storage.AuthStorage.SaveUnlockToken(token, userID)
+		if err != nil {
+			return true, err
+		}
+		_ = service.Mailer.SendMail(
+			userAuthInfo.Email,
+			"ZeWise 账号已被临时锁定",
+			fmt.Sprintf(
+				"%s，你好：\n\n你的账号因多次登录失败已被临时锁定，将在 %d 分钟后自动解锁。\n\n如为本人操作，可使用以下解锁令牌立即解锁：%s\n\n如非本人操作，建议解锁后尽快修改密码。",
+				userAuthInfo.UserName, consts.ACCOUNT_LOCK_DURATION/60, token,
+			),
+		)
+		return true, nil
+	}
+
+	// 失败次数达到阈值后 每次失败的等待时间翻倍
+	if accountFailures >= consts.LOGIN_DELAY_THRESHOLD {
+		delay := consts.LOGIN_DELAY_MAX
+		if shift := accountFailures - consts.LOGIN_DELAY_THRESHOLD; shift < 5 {
+			delay = min(1<<shift, consts.LOGIN_DELAY_MAX)
+		}
+		err = service.Storage.AuthStorage.SetLoginDelay(userID, time.Duration(delay)*time.Second)
+		if err != nil {
+			return false, err
 		}
+	}
+
+	return false, nil
+}
+
+/*
+lockIPOnFailures IP 失败次数过多时禁止其登录
+
+参数：
+  - ip：IP 地址
+  - ipFailures：IP 失败次数
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) lockIPOnFailures(ip string, ipFailures int64) error {
+	if ipFailures < consts.IP_LOCK_THRESHOLD {
+		return nil
+	}
 
-		// 获取 IP 对应地理位置
-		ipInfo, _ := thirdparty.RequestIPInfo(ip, os.Getenv("AMAP_KEY"))
+	return service.Storage.AuthStorage.LockIP(ip)
+}
 
-		// 获取浏览器信息
-		broswer, broswerVersion := userAgent.Browser()
+/*
+recordFailedLogin 在独立事务中记录失败的登录
 
-		// 写入登录日志
-		err = service.Storage.AuthStorage.RecordLoginEvent(
+参数：
+  - session：数据库会话
+  - userID：用户 ID
+  - ip：IP 地址
+  - userAgent：用户代理
+  - locked：是否因账号锁定被拒绝或触发锁定
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) recordFailedLogin(session mongo.Session, userID primitive.ObjectID, ip string, userAgent *useragent.UserAgent, locked bool) error {
+	location, device, application := resolveLoginClient(ip, userAgent)
+	_, err := session.WithTransaction(context.Background(), func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, service.Storage.AuthStorage.RecordLoginEvent(
 			sessionCtx,
-			userAuthInfo.ID,
+			userID,
 			ip,
-			functools.JoinStrings(ipInfo.Province, ipInfo.City),
-			userAgent.OSInfo().FullName,
-			functools.JoinStrings(broswer, " ", broswerVersion),
+			location,
+			device,
+			application,
+			false,
+			locked,
 		)
+	})
+
+	return err
+}
+
+/*
+UnlockAccount 使用解锁令牌解除账号锁定
+
+参数：
+  - token：解锁令牌
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) UnlockAccount(token string) error {
+	userID, err := service.Storage.AuthStorage.ConsumeUnlockToken(token)
+	if err != nil {
+		return err
+	}
+
+	return service.Storage.AuthStorage.ClearLoginFailures(userID)
+}
+
+/*
+resolveLoginClient 解析登录客户端信息
+
+参数：
+  - ip：IP 地址
+  - userAgent：用户代理
+
+返回：
+  - string：地理位置
+  - string：设备
+  - string：应用
+*/
+func resolveLoginClient(ip string, userAgent *useragent.UserAgent) (string, string, string) {
+	// 获取 IP 对应地理位置
+	ipInfo, _ := thirdparty.RequestIPInfo(ip, os.Getenv("AMAP_KEY"))
+
+	// 获取浏览器信息
+	broswer, broswerVersion := userAgent.Browser()
+
+	return functools.JoinStrings(ipInfo.Province, ipInfo.City),
+		userAgent.OSInfo().FullName,
+		functools.JoinStrings(broswer, " ", broswerVersion)
+}
+
+/*
+AuthLogout 用户登出 同时吊销当前会话的刷新令牌
+
+参数：
+  - tokenClaim：访问令牌声明
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) AuthLogout(tokenClaim parsers.BearerTokenClaims) error {
+	return service.Storage.AuthStorage.RemoveSessions(tokenClaim.UID, tokenClaim.SessionID)
+}
+
+/*
+RefreshToken 使用刷新令牌换取新的访问令牌与刷新令牌 旧刷新令牌随即失效
+已轮换的刷新令牌被再次使用时视为泄露 吊销整个令牌族
+
+参数：
+  - refreshToken：刷新令牌
+
+返回：
+  - newToken：新访问令牌
+  - newRefreshToken：新刷新令牌
+  - error：错误信息
+*/
+func (service *AuthService) RefreshToken(refreshToken string) (string, string, error) {
+	tokenHash := encryptors.HashToken(refreshToken)
+
+	// 取出刷新令牌
+	info, ok, err := service.Storage.AuthStorage.ConsumeRefreshToken(tokenHash)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		// 检测已轮换令牌的重放
+		used, reused, err := service.Storage.AuthStorage.GetUsedRefreshToken(tokenHash)
 		if err != nil {
-			return nil, err
+			return "", "", err
+		}
+		if !reused {
+			return "", "", types.NewError(types.ErrAuthFailed, "刷新令牌无效或已过期")
 		}
 
+		err = service.Storage.AuthStorage.RemoveSessions(used.UID, used.FamilyID)
+		if err != nil {
+			return "", "", err
+		}
+		return "", "", types.NewError(types.ErrAuthFailed, "刷新令牌已被使用 该会话已被吊销 请重新登录")
+	}
+
+	userID, err := primitive.ObjectIDFromHex(info.UID)
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 获取当前用户名 会话期间用户名可能已修改
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		info.UserName = authInfo.UserName
 		return nil, nil
 	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// 生成同一令牌族的访问令牌与刷新令牌
+	newToken, newClaims, err := generators.GenerateToken(userID, info.UserName, info.FamilyID)
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+	newRefreshToken, err := generators.GenerateRefreshToken()
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 替换会话的访问令牌 会话已被吊销时拒绝刷新
+	rotated, err := service.Storage.AuthStorage.RotateSessionToken(info.UID, info.FamilyID, info.AccessJTI, newClaims.ID)
+	if err != nil {
+		return "", "", err
+	}
+	if !rotated {
+		return "", "", types.NewError(types.ErrAuthFailed, "会话已失效 请重新登录")
+	}
+
+	// 保存新刷新令牌
+	err = service.Storage.AuthStorage.SaveRefreshToken(encryptors.HashToken(newRefreshToken), models.RefreshTokenInfo{
+		UID:       info.UID,
+		UserName:  info.UserName,
+		FamilyID:  info.FamilyID,
+		AccessJTI: newClaims.ID,
+	})
+	if err != nil {
+		return "", "", err
+	}
 
+	return newToken, newRefreshToken, nil
+}
+
+/*
+GetJWKS 获取令牌验证公钥集合
+
+返回：
+  - signers.JWKSet：公钥集合
+  - error：错误信息
+*/
+func (service *AuthService) GetJWKS() (signers.JWKSet, error) {
+	keySet, err := signers.Default()
 	if err != nil {
-		return "", types.NewError(types.ErrServerError, err.Error())
+		return signers.JWKSet{}, types.NewError(types.ErrServerError, err.Error())
 	}
 
-	return token, nil
+	return keySet.JWKS(), nil
 }
 
 /*
-AuthLogout 用户登出
+SendVerifyMail 向用户当前邮箱发送验证邮件
 
 参数：
-  - token：JWT 令牌
+  - userID：用户 ID
 
 返回：
   - error：错误信息
 */
-func (service *AuthService) AuthLogout(tokenClaim parsers.BearerTokenClaims, token string) error {
-	return service.Storage.AuthStorage.RmoveToken(tokenClaim.UID, token)
+func (service *AuthService) SendVerifyMail(userID primitive.ObjectID) error {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 获取用户信息
+	var userInfo models.UserInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		userInfo, err = service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+	if userInfo.EmailVerified {
+		return types.NewError(types.ErrInvalidParams, "邮箱已验证")
+	}
+
+	// 限制发送频率
+	ok, err := service.Storage.AuthStorage.AcquireMailSendLock(userID.Hex(), "verify")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return types.NewError(types.ErrInvalidParams, "发送过于频繁 请稍后再试")
+	}
+
+	// 生成并保存验证令牌
+	token, err := generators.GenerateSalt(consts.MAIL_VERIFY_TOKEN_LENGTH)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	err = service.Storage.AuthStorage.SaveMailVerifyToken(token, userID.Hex(), userInfo.Email)
+	if err != nil {
+		return err
+	}
+
+	// 发送验证邮件
+	err = service.Mailer.SendMail(
+		userInfo.Email,
+		"ZeWise 邮箱验证",
+		fmt.Sprintf(
+			"%s，你好：\n\n你的邮箱验证令牌为：%s\n\n令牌将在 %d 分钟后失效，如非本人操作请忽略此邮件。",
+			userInfo.UserName, token, consts.MAIL_VERIFY_TOKEN_EXPIRE_DURATION/60,
+		),
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, "验证邮件发送失败")
+	}
+
+	return nil
 }
 
 /*
-RefreshToken 刷新 Token
+ConfirmVerifyMail 使用验证令牌确认邮箱
 
 参数：
-  - userID：用户 ID
+  - token：验证令牌
 
 返回：
-  - newToken：新 Token
   - error：错误信息
 */
-func (service *AuthService) RefreshToken(userID primitive.ObjectID, username string, oldToken string) (string, error) {
-	// 生成 JWT 令牌
-	newToken, _, err := generators.GenerateToken(userID, username)
+func (service *AuthService) ConfirmVerifyMail(token string) error {
+	// 读取验证令牌
+	userID, email, err := service.Storage.AuthStorage.ConsumeMailVerifyToken(token)
+	if err != nil {
+		return err
+	}
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 标记邮箱已验证
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		return nil, service.Storage.UserStorage.SetEmailVerified(sessionContext, objID, email)
+	})
 	if err != nil {
-		return "", types.NewError(types.ErrServerError, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+/*
+RequestPasswordReset 向邮箱发送密码重置令牌 邮箱未注册时同样返回成功 以免泄露用户是否存在
+
+参数：
+  - email：邮箱
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) RequestPasswordReset(email string) error {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 获取用户认证信息
+	var authInfo models.UserAuthInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		authInfo, err = service.Storage.AuthStorage.GetUserAuthInfoByEmail(sessionContext, email)
+		return nil, err
+	})
+	if errors.Is(err, types.ErrInvalidParams) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 限制发送频率
+	ok, err := service.Storage.AuthStorage.AcquireMailSendLock(authInfo.ID.Hex(), "reset")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// 与邮箱未注册时的响应保持一致
+		return nil
 	}
 
-	// 替换新令牌
-	err = service.Storage.AuthStorage.UpdateToken(userID.Hex(), newToken, oldToken)
+	// 生成并保存重置令牌
+	token, err := generators.GenerateSalt(consts.PASSWORD_RESET_TOKEN_LENGTH)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	err = service.Storage.AuthStorage.SavePasswordResetToken(token, authInfo.ID.Hex(), authInfo.Email)
+	if err != nil {
+		return err
+	}
+
+	// 发送重置邮件
+	err = service.Mailer.SendMail(
+		authInfo.Email,
+		"ZeWise 密码重置",
+		fmt.Sprintf(
+			"%s，你好：\n\n你的密码重置令牌为：%s\n\n令牌将在 %d 分钟后失效且只能使用一次，如非本人操作请忽略此邮件。",
+			authInfo.UserName, token, consts.PASSWORD_RESET_TOKEN_EXPIRE_DURATION/60,
+		),
+	)
+	if err != nil {
+		// 仅记录日志 发送失败与邮箱未注册时的响应保持一致
+		log.Printf("发送密码重置邮件失败: %v", err)
+	}
+
+	return nil
+}
+
+/*
+ConfirmPasswordReset 使用重置令牌设置新密码 成功后用户的全部令牌失效
+
+参数：
+  - token：重置令牌
+  - newPassword：新密码
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) ConfirmPasswordReset(token string, newPassword string) error {
+	// 校验新密码 不合法时保留令牌以便重试
+	if !validers.IsValidPassword(newPassword) {
+		return types.NewError(types.ErrInvalidParams, "不合法的密码")
+	}
+
+	// 读取重置令牌
+	userID, email, err := service.Storage.AuthStorage.ConsumePasswordResetToken(token)
+	if err != nil {
+		return err
+	}
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 确认用户存在 且邮箱未在令牌签发后修改
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+		if authInfo.Email != email {
+			return nil, types.NewError(types.ErrInvalidParams, "重置令牌无效或已过期")
+		}
+
+		// 生成新哈希密码
+		hashedPassword, err := encryptors.HashPassword(newPassword)
+		if err != nil {
+			return nil, types.NewError(types.ErrServerError, err.Error())
+		}
+
+		// 更新用户密码
+		err = service.Storage.UserStorage.UpdateUserPassword(sessionContext, objID, hashedPassword)
+		return nil, err
+	})
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return newToken, nil
+	// 吊销全部会话
+	return service.Storage.AuthStorage.RemoveAllSessions(userID)
 }
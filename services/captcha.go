@@ -0,0 +1,87 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明图片验证码相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"crypto/subtle"
+	"image"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/generators"
+	"zewise.space/backend/utils/imagetools"
+)
+
+/*
+CreateCaptcha 生成图片验证码
+
+返回：
+  - string：验证码ID
+  - []byte：WebP 格式的验证码图片
+  - error：错误信息
+*/
+func (service *AuthService) CreateCaptcha() (string, []byte, error) {
+	// 生成验证码ID与答案
+	captchaID, err := generators.GenerateSalt(consts.CAPTCHA_ID_LENGTH)
+	if err != nil {
+		return "", nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	answer, err := generators.GenerateCaptchaAnswer()
+	if err != nil {
+		return "", nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 绘制并编码验证码图片
+	captchaImage, err := imagetools.DrawCaptcha(answer, consts.CAPTCHA_IMAGE_WIDTH, consts.CAPTCHA_IMAGE_HEIGHT)
+	if err != nil {
+		return "", nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	encoder := imagetools.NewWebpImageEncoder(consts.CAPTCHA_IMAGE_QUALITY)
+	imageData, err := encoder.Encode(captchaImage, image.Config{
+		Width:  consts.CAPTCHA_IMAGE_WIDTH,
+		Height: consts.CAPTCHA_IMAGE_HEIGHT,
+	})
+	if err != nil {
+		return "", nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 保存答案
+	err = service.Storage.AuthStorage.SaveCaptcha(captchaID, answer)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return captchaID, imageData, nil
+}
+
+/*
+verifyCaptcha 校验图片验证码 验证码在校验后立即失效
+
+参数：
+  - storage：存储对象
+  - captchaID：验证码ID
+  - answer：用户作答
+
+返回：
+  - error：未提供或作答错误时返回 types.ErrCaptchaRequired 错误
+*/
+func verifyCaptcha(storage *stores.Storage, captchaID string, answer string) error {
+	if captchaID == "" || answer == "" {
+		return types.NewError(types.ErrCaptchaRequired, "需要完成图片验证码")
+	}
+
+	expected, err := storage.AuthStorage.ConsumeCaptcha(captchaID)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(generators.NormalizeCaptchaAnswer(answer))) != 1 {
+		return types.NewError(types.ErrCaptchaRequired, "验证码错误")
+	}
+
+	return nil
+}
@@ -0,0 +1,442 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明评论与回复相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/validers"
+)
+
+// CommentService 评论服务
+type CommentService struct {
+	Storage *stores.Storage
+}
+
+/*
+CreateComment 发表评论
+
+参数：
+  - userID：用户ID
+  - reqBody：请求体
+
+返回：
+  - models.CommentInfo：评论信息
+  - error：错误信息
+*/
+func (service *CommentService) CreateComment(userID primitive.ObjectID, reqBody parsers.CommentCreateBody) (models.CommentInfo, error) {
+	commentInfo := models.CommentInfo{}
+
+	// 校验参数
+	postID, err := primitive.ObjectIDFromHex(reqBody.PostID)
+	if err != nil {
+		return commentInfo, types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+	if !validers.IsValidCommentContent(reqBody.Content) {
+		return commentInfo, types.NewError(types.ErrInvalidParams, "不合法的评论内容")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return commentInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验博文是否可见
+		_, err := getVisiblePost(service.Storage, sessionContext, postID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 获取当前用户名 令牌中的用户名在改名后可能已过时
+		userInfo, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 创建评论
+		commentID, err := service.Storage.CommentStorage.CreateComment(sessionContext, models.CommentInfo{
+			PostID:   postID,
+			UID:      userID,
+			Username: userInfo.UserName,
+			Content:  reqBody.Content,
+			IsPublic: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		commentInfo, err = service.Storage.CommentStorage.GetCommentByID(sessionContext, commentID)
+		return nil, err
+	})
+	if err != nil {
+		return commentInfo, err
+	}
+
+	return commentInfo, nil
+}
+
+/*
+GetPostComments 获取博文的评论列表
+
+参数：
+  - postID：博文ID
+  - viewerID：访问者ID 未登录时为空
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.CommentInfo：评论列表
+  - error：错误信息
+*/
+func (service *CommentService) GetPostComments(postID string, viewerID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.CommentInfo, error) {
+	// 转换博文ID
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var comments []models.CommentInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验博文是否可见
+		_, err := getVisiblePost(service.Storage, sessionContext, objID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 获取评论列表
+		comments, err = service.Storage.CommentStorage.GetCommentsByPost(sessionContext, objID, cursor, limit)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+/*
+CreateReply 发表回复 未指定父回复时作为新楼层的顶层回复
+
+参数：
+  - userID：用户ID
+  - reqBody：请求体
+
+返回：
+  - models.ReplyInfo：回复信息
+  - error：错误信息
+*/
+func (service *CommentService) CreateReply(userID primitive.ObjectID, reqBody parsers.ReplyCreateBody) (models.ReplyInfo, error) {
+	replyInfo := models.ReplyInfo{}
+
+	// 校验参数
+	commentID, err := primitive.ObjectIDFromHex(reqBody.CommentID)
+	if err != nil {
+		return replyInfo, types.NewError(types.ErrInvalidParams, "不合法的评论ID")
+	}
+	parentReplyID := primitive.NilObjectID
+	if reqBody.ParentReplyID != "" {
+		parentReplyID, err = primitive.ObjectIDFromHex(reqBody.ParentReplyID)
+		if err != nil {
+			return replyInfo, types.NewError(types.ErrInvalidParams, "不合法的父回复ID")
+		}
+	}
+	if !validers.IsValidCommentContent(reqBody.Content) {
+		return replyInfo, types.NewError(types.ErrInvalidParams, "不合法的回复内容")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return replyInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验评论与博文是否可见
+		commentInfo, err := service.Storage.CommentStorage.GetCommentByID(sessionContext, commentID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = getVisiblePost(service.Storage, sessionContext, commentInfo.PostID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 获取当前用户名 令牌中的用户名在改名后可能已过时
+		userInfo, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 构造回复 顶层回复的楼层ID即为自身ID
+		newReply := models.ReplyInfo{
+			ID:        primitive.NewObjectID(),
+			CommentID: commentID,
+			UID:       userID,
+			Username:  userInfo.UserName,
+			Content:   reqBody.Content,
+			IsPublic:  true,
+		}
+		newReply.RootReplyID = newReply.ID
+		if !parentReplyID.IsZero() {
+			parentReply, err := service.Storage.ReplyStorage.GetReplyByID(sessionContext, parentReplyID)
+			if err != nil {
+				return nil, err
+			}
+			if parentReply.CommentID != commentID {
+				return nil, types.NewError(types.ErrInvalidParams, "父回复不属于该评论")
+			}
+			newReply.ParentReplyID = parentReplyID
+			newReply.RootReplyID = parentReply.RootReplyID
+		}
+
+		// 创建回复
+		replyID, err := service.Storage.ReplyStorage.CreateReply(sessionContext, newReply)
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新回复计数
+		err = service.Storage.CommentStorage.IncreaseReplyCount(sessionContext, commentID, 1)
+		if err != nil {
+			return nil, err
+		}
+		if !parentReplyID.IsZero() {
+			err = service.Storage.ReplyStorage.IncreaseReplyCount(sessionContext, newReply.RootReplyID, 1)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		replyInfo, err = service.Storage.ReplyStorage.GetReplyByID(sessionContext, replyID)
+		return nil, err
+	})
+	if err != nil {
+		return replyInfo, err
+	}
+
+	return replyInfo, nil
+}
+
+/*
+GetReplyThread 获取评论下的回复楼层 按顶层回复分页 每个楼层返回完整的回复树
+
+参数：
+  - commentID：评论ID
+  - viewerID：访问者ID 未登录时为空
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.ReplyInfo：当前页的顶层回复
+  - []models.ReplyInfo：顶层回复下的全部子回复
+  - error：错误信息
+*/
+func (service *CommentService) GetReplyThread(commentID string, viewerID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.ReplyInfo, []models.ReplyInfo, error) {
+	// 转换评论ID
+	objID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, nil, types.NewError(types.ErrInvalidParams, "不合法的评论ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var roots, descendants []models.ReplyInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验评论与博文是否可见
+		commentInfo, err := service.Storage.CommentStorage.GetCommentByID(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = getVisiblePost(service.Storage, sessionContext, commentInfo.PostID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 获取顶层回复
+		roots, err = service.Storage.ReplyStorage.GetRootReplies(sessionContext, objID, cursor, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		// 获取楼层内的子回复
+		rootIDs := make([]primitive.ObjectID, 0, len(roots))
+		for _, root := range roots {
+			rootIDs = append(rootIDs, root.ID)
+		}
+		replies, err := service.Storage.ReplyStorage.GetRepliesByRoots(sessionContext, rootIDs)
+		if err != nil {
+			return nil, err
+		}
+		descendants = make([]models.ReplyInfo, 0, len(replies))
+		for _, reply := range replies {
+			if !reply.ParentReplyID.IsZero() {
+				descendants = append(descendants, reply)
+			}
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return roots, descendants, nil
+}
+
+/*
+DeleteComment 删除评论 评论作者与博文作者可以操作
+
+参数：
+  - userID：操作者ID
+  - commentID：评论ID
+
+返回：
+  - error：错误信息
+*/
+func (service *CommentService) DeleteComment(userID primitive.ObjectID, commentID string) error {
+	// 转换评论ID
+	objID, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的评论ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验权限
+		commentInfo, err := service.Storage.CommentStorage.GetCommentByID(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+		if commentInfo.UID != userID {
+			postInfo, err := service.Storage.PostStorage.GetPostByID(sessionContext, commentInfo.PostID)
+			if err != nil || postInfo.UID != userID {
+				return nil, types.NewError(types.ErrAuthFailed, "无权删除该评论")
+			}
+		}
+
+		// 删除评论
+		err = service.Storage.CommentStorage.DeleteComment(sessionContext, objID)
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+DeleteReply 删除回复 回复作者与博文作者可以操作 已删除的回复在楼层中保留占位
+
+参数：
+  - userID：操作者ID
+  - replyID：回复ID
+
+返回：
+  - error：错误信息
+*/
+func (service *CommentService) DeleteReply(userID primitive.ObjectID, replyID string) error {
+	// 转换回复ID
+	objID, err := primitive.ObjectIDFromHex(replyID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的回复ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验权限
+		replyInfo, err := service.Storage.ReplyStorage.GetReplyByID(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+		if replyInfo.IsDeleted {
+			return nil, types.NewError(types.ErrInvalidParams, "回复不存在")
+		}
+		if replyInfo.UID != userID {
+			commentInfo, err := service.Storage.CommentStorage.GetCommentByID(sessionContext, replyInfo.CommentID)
+			if err != nil {
+				return nil, types.NewError(types.ErrAuthFailed, "无权删除该回复")
+			}
+			postInfo, err := service.Storage.PostStorage.GetPostByID(sessionContext, commentInfo.PostID)
+			if err != nil || postInfo.UID != userID {
+				return nil, types.NewError(types.ErrAuthFailed, "无权删除该回复")
+			}
+		}
+
+		// 删除回复
+		err = service.Storage.ReplyStorage.DeleteReply(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新回复计数
+		err = service.Storage.CommentStorage.IncreaseReplyCount(sessionContext, replyInfo.CommentID, -1)
+		if err != nil {
+			return nil, err
+		}
+		if replyInfo.RootReplyID != replyInfo.ID {
+			err = service.Storage.ReplyStorage.IncreaseReplyCount(sessionContext, replyInfo.RootReplyID, -1)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
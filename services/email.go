@@ -0,0 +1,166 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明修改邮箱相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/encryptors"
+	"zewise.space/backend/utils/generators"
+	"zewise.space/backend/utils/validers"
+)
+
+/*
+RequestEmailChange 申请修改邮箱 向新邮箱发送确认令牌并通知原邮箱 确认前邮箱不会变更
+
+参数：
+  - userID：用户 ID
+  - password：密码
+  - email：新邮箱
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) RequestEmailChange(userID primitive.ObjectID, password string, email string) error {
+	if !validers.IsValidEmail(email) {
+		return types.NewError(types.ErrInvalidParams, "不合法的邮箱")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 校验密码与新邮箱
+	var userInfo models.UserInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		_, err = encryptors.VerifyPassword(authInfo.PasswordHash, password, authInfo.Salt)
+		if err != nil {
+			return nil, types.NewError(types.ErrInvalidParams, "密码错误")
+		}
+		if authInfo.Email == email {
+			return nil, types.NewError(types.ErrInvalidParams, "新邮箱与当前邮箱相同")
+		}
+
+		registered, err := service.Storage.UserStorage.IsEmailRegistered(sessionContext, email)
+		if err != nil {
+			return nil, err
+		}
+		if registered {
+			return nil, types.NewError(types.ErrInvalidParams, "邮箱已被注册")
+		}
+
+		userInfo, err = service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 限制发送频率
+	ok, err := service.Storage.AuthStorage.AcquireMailSendLock(userID.Hex(), "email_change")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return types.NewError(types.ErrInvalidParams, "发送过于频繁 请稍后再试")
+	}
+
+	// 生成并保存确认令牌
+	token, err := generators.GenerateSalt(consts.EMAIL_CHANGE_TOKEN_LENGTH)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	err = service.Storage.AuthStorage.SaveEmailChangeToken(token, userID.Hex(), email)
+	if err != nil {
+		return err
+	}
+
+	// 向新邮箱发送确认令牌
+	err = service.Mailer.SendMail(
+		email,
+		"ZeWise 修改邮箱确认",
+		fmt.Sprintf(
+			"%s，你好：\n\n你正在将账号邮箱修改为此邮箱，确认令牌为：%s\n\n令牌将在 %d 分钟后失效，如非本人操作请忽略此邮件。",
+			userInfo.UserName, token, consts.EMAIL_CHANGE_TOKEN_EXPIRE_DURATION/60,
+		),
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, "确认邮件发送失败")
+	}
+
+	// 通知原邮箱 通知失败不影响修改流程
+	err = service.Mailer.SendMail(
+		userInfo.Email,
+		"ZeWise 邮箱修改提醒",
+		fmt.Sprintf(
+			"%s，你好：\n\n你的账号申请将邮箱修改为 %s，新邮箱确认后本邮箱将不再用于登录与找回密码。\n\n如非本人操作，请立即修改密码。",
+			userInfo.UserName, email,
+		),
+	)
+	if err != nil {
+		log.Printf("发送邮箱修改提醒失败: %v", err)
+	}
+
+	return nil
+}
+
+/*
+ConfirmEmailChange 使用确认令牌完成邮箱修改 新邮箱同时视为已验证
+发往原邮箱的密码重置令牌随之失效 用户的全部会话被吊销
+
+参数：
+  - token：确认令牌
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) ConfirmEmailChange(token string) error {
+	// 读取确认令牌
+	userID, email, err := service.Storage.AuthStorage.ConsumeEmailChangeToken(token)
+	if err != nil {
+		return err
+	}
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 修改邮箱 并发占用由唯一索引保证
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		return nil, service.Storage.UserStorage.ChangeEmail(sessionContext, objID, email)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 吊销全部会话
+	return service.Storage.AuthStorage.RemoveAllSessions(userID)
+}
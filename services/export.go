@@ -0,0 +1,374 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明个人数据导出相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+	"zewise.space/backend/utils/imagetools"
+	"zewise.space/backend/utils/serializers"
+)
+
+// ExportService 个人数据导出服务
+type ExportService struct {
+	Storage *stores.Storage
+}
+
+/*
+RequestDataExport 申请导出个人数据 归档在后台异步生成
+
+参数：
+  - userID：用户ID
+
+返回：
+  - models.DataExport：导出记录
+  - error：错误信息
+*/
+func (service *ExportService) RequestDataExport(userID primitive.ObjectID) (models.DataExport, error) {
+	now := time.Now()
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return models.DataExport{}, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var export models.DataExport
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 同一时间仅允许一个导出任务 且成功导出后需间隔一段时间
+		latest, found, err := service.Storage.ExportStorage.GetLatestDataExport(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			switch {
+			case latest.Status == consts.DATA_EXPORT_STATUS_PENDING && now.Before(latest.CreatedAt.Add(consts.DATA_EXPORT_BUILD_TIMEOUT*time.Second)):
+				return nil, types.NewError(types.ErrInvalidParams, "数据导出正在生成中")
+			case latest.Status == consts.DATA_EXPORT_STATUS_READY && now.Before(latest.CreatedAt.Add(consts.DATA_EXPORT_COOLDOWN*time.Second)):
+				return nil, types.NewError(types.ErrInvalidParams, "申请数据导出过于频繁 请稍后再试")
+			}
+		}
+
+		// 创建导出记录
+		export = models.DataExport{
+			UID:       userID,
+			Status:    consts.DATA_EXPORT_STATUS_PENDING,
+			CreatedAt: now,
+			ExpiresAt: now.AddDate(0, 0, consts.DATA_EXPORT_RETENTION_DAYS),
+		}
+		export.ID, err = service.Storage.ExportStorage.CreateDataExport(sessionContext, export)
+		return nil, err
+	})
+	if err != nil {
+		return models.DataExport{}, err
+	}
+
+	// 后台生成归档
+	go service.buildDataExport(export)
+
+	return export, nil
+}
+
+/*
+GetDataExport 获取最近一次个人数据导出的状态 归档可下载时一并生成限时下载链接
+
+参数：
+  - userID：用户ID
+
+返回：
+  - models.DataExport：导出记录 生成超时或已过期时状态会相应改写
+  - string：限时下载链接
+  - error：错误信息
+*/
+func (service *ExportService) GetDataExport(userID primitive.ObjectID) (models.DataExport, string, error) {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return models.DataExport{}, "", types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 获取导出记录
+	var export models.DataExport
+	var found bool
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		export, found, err = service.Storage.ExportStorage.GetLatestDataExport(sessionContext, userID)
+		return nil, err
+	})
+	if err != nil {
+		return models.DataExport{}, "", err
+	}
+	if !found {
+		return models.DataExport{}, "", types.NewError(types.ErrInvalidParams, "尚未申请数据导出")
+	}
+
+	now := time.Now()
+	switch export.Status {
+	case consts.DATA_EXPORT_STATUS_PENDING:
+		// 生成进程中断时记录会停留在生成中 超时后视为失败 允许重新申请
+		if !now.Before(export.CreatedAt.Add(consts.DATA_EXPORT_BUILD_TIMEOUT * time.Second)) {
+			export.Status = consts.DATA_EXPORT_STATUS_FAILED
+		}
+	case consts.DATA_EXPORT_STATUS_READY:
+		if !now.Before(export.ExpiresAt) {
+			export.Status = consts.DATA_EXPORT_STATUS_EXPIRED
+			break
+		}
+
+		// 链接有效期不超过归档的剩余保留时间
+		expiry := min(consts.DATA_EXPORT_LINK_EXPIRE_DURATION*time.Second, export.ExpiresAt.Sub(now))
+		downloadURL, err := service.Storage.ExportStorage.PresignExportFile(ctx, export.ObjectName, expiry)
+		if err != nil {
+			return models.DataExport{}, "", err
+		}
+		return export, downloadURL, nil
+	}
+
+	return export, "", nil
+}
+
+/*
+buildDataExport 生成导出归档并更新导出记录
+
+参数：
+  - export：导出记录
+*/
+func (service *ExportService) buildDataExport(export models.DataExport) {
+	ctx, cancel := context.WithTimeout(context.Background(), consts.DATA_EXPORT_BUILD_TIMEOUT*time.Second)
+	defer cancel()
+	objectName, size, buildErr := service.writeDataExport(ctx, export)
+
+	// 创建数据库会话
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		log.Printf("更新数据导出记录失败: %v", err)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	// 记录生成结果
+	_, err = session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (any, error) {
+		if buildErr != nil {
+			return nil, service.Storage.ExportStorage.FailDataExport(sessionContext, export.ID, buildErr.Error())
+		}
+		now := time.Now()
+		return nil, service.Storage.ExportStorage.CompleteDataExport(
+			sessionContext, export.ID, objectName, size, now, now.AddDate(0, 0, consts.DATA_EXPORT_RETENTION_DAYS),
+		)
+	})
+	if err != nil {
+		log.Printf("更新数据导出记录失败: %v", err)
+	}
+}
+
+/*
+writeDataExport 收集用户数据 打包为 zip 归档并上传
+
+参数：
+  - ctx：上下文
+  - export：导出记录
+
+返回：
+  - string：归档对象名
+  - int64：归档大小
+  - error：错误信息
+*/
+func (service *ExportService) writeDataExport(ctx context.Context, export models.DataExport) (string, int64, error) {
+	// 创建数据库会话
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return "", 0, err
+	}
+	defer session.EndSession(ctx)
+
+	// 收集用户数据
+	var userInfo models.UserInfo
+	var logs []models.UserLoginLog
+	var posts []models.PostInfo
+	var comments []models.CommentInfo
+	var replies []models.ReplyInfo
+	var media []models.MediaInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		userInfo, err = service.Storage.UserStorage.GetUserDataByID(sessionContext, export.UID)
+		if err != nil {
+			return nil, err
+		}
+		logs, err = service.Storage.AuthStorage.GetLoginLogs(sessionContext, export.UID, primitive.NilObjectID, 0)
+		if err != nil {
+			return nil, err
+		}
+		posts, err = service.Storage.PostStorage.GetPostsByUser(sessionContext, export.UID, primitive.NilObjectID, 0, true)
+		if err != nil {
+			return nil, err
+		}
+		comments, err = service.Storage.CommentStorage.GetCommentsByUser(sessionContext, export.UID)
+		if err != nil {
+			return nil, err
+		}
+		replies, err = service.Storage.ReplyStorage.GetRepliesByUser(sessionContext, export.UID)
+		if err != nil {
+			return nil, err
+		}
+		media, err = service.Storage.MediaStorage.GetMediaByUser(sessionContext, export.UID)
+		return nil, err
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	// 归档可能较大 先写入临时文件
+	file, err := os.CreateTemp("", "zewise-export-*.zip")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	// 写入 JSON 数据
+	archive := serializers.NewDataExportArchive(userInfo, logs, posts, comments, replies, media)
+	zipWriter := zip.NewWriter(file)
+	for _, document := range []struct {
+		name string
+		data any
+	}{
+		{"profile.json", archive.Profile},
+		{"login_history.json", archive.LoginHistory},
+		{"posts.json", archive.Posts},
+		{"comments.json", archive.Comments},
+		{"replies.json", archive.Replies},
+		{"media.json", archive.Media},
+	} {
+		err = writeExportJSON(zipWriter, document.name, document.data)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	// 写入头像与媒体文件
+	if userInfo.Avatar != "vanilla" {
+		suffix := imagetools.NewWebpImageEncoder(consts.AVATAR_QUALITY).GetFormatFileSuffix()
+		object, err := service.Storage.UserStorage.GetAvatarFile(ctx, functools.JoinStrings(userInfo.Avatar, ".", suffix))
+		if err != nil {
+			return "", 0, err
+		}
+		err = writeExportObject(zipWriter, functools.JoinStrings("avatar.", suffix), object)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	suffix := imagetools.NewWebpImageEncoder(consts.MEDIA_QUALITY).GetFormatFileSuffix()
+	for _, mediaInfo := range media {
+		fileName := functools.JoinStrings(mediaInfo.ID.Hex(), ".", suffix)
+		object, err := service.Storage.MediaStorage.GetMediaFile(ctx, fileName)
+		if err != nil {
+			return "", 0, err
+		}
+		err = writeExportObject(zipWriter, functools.JoinStrings("media/", fileName), object)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		return "", 0, err
+	}
+
+	// 上传归档
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", 0, err
+	}
+	objectName := functools.JoinStrings(export.UID.Hex(), "/", export.ID.Hex(), ".zip")
+	_, err = service.Storage.ExportStorage.UploadExportFile(ctx, objectName, file, size)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return objectName, size, nil
+}
+
+/*
+writeExportJSON 将数据以 JSON 格式写入归档
+
+参数：
+  - zipWriter：归档写入器
+  - name：归档内文件名
+  - data：数据
+
+返回：
+  - error：错误信息
+*/
+func writeExportJSON(zipWriter *zip.Writer, name string, data any) error {
+	writer, err := zipWriter.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+/*
+writeExportObject 将对象存储中的文件写入归档 文件不存在时跳过
+
+参数：
+  - zipWriter：归档写入器
+  - name：归档内文件名
+  - object：对象存储中的文件
+
+返回：
+  - error：错误信息
+*/
+func writeExportObject(zipWriter *zip.Writer, name string, object *minio.Object) error {
+	defer object.Close()
+
+	info, err := object.Stat()
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 图片已经过压缩 直接存储即可
+	writer, err := zipWriter.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Store,
+		Modified: info.LastModified,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, object)
+	return err
+}
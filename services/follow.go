@@ -0,0 +1,301 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明关注关系相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+)
+
+// FollowService 关注关系服务
+type FollowService struct {
+	Storage *stores.Storage
+}
+
+/*
+Follow 关注用户 关注关系与双方计数在同一事务内更新
+
+参数：
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+
+返回：
+  - error：错误信息
+*/
+func (service *FollowService) Follow(followerID primitive.ObjectID, followeeID string) error {
+	// 转换用户ID
+	objID, err := primitive.ObjectIDFromHex(followeeID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+	}
+	if objID == followerID {
+		return types.NewError(types.ErrInvalidParams, "不能关注自己")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验被关注者是否存在
+		_, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 创建关注关系
+		err = service.Storage.FollowStorage.CreateFollow(sessionContext, followerID, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新计数
+		err = service.Storage.UserStorage.IncreaseFollowCounts(sessionContext, followerID, objID, 1)
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 更新时间线 失败不影响关注结果
+	err = backfillTimeline(service.Storage, followerID, objID)
+	if err != nil {
+		log.Printf("补充时间线失败: %v", err)
+	}
+
+	return nil
+}
+
+/*
+Unfollow 取消关注 关注关系与双方计数在同一事务内更新
+
+参数：
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+
+返回：
+  - error：错误信息
+*/
+func (service *FollowService) Unfollow(followerID primitive.ObjectID, followeeID string) error {
+	// 转换用户ID
+	objID, err := primitive.ObjectIDFromHex(followeeID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 删除关注关系
+		err := service.Storage.FollowStorage.DeleteFollow(sessionContext, followerID, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新计数
+		err = service.Storage.UserStorage.IncreaseFollowCounts(sessionContext, followerID, objID, -1)
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 更新时间线 失败不影响关注结果
+	err = pruneTimeline(service.Storage, followerID, objID)
+	if err != nil {
+		log.Printf("清理时间线失败: %v", err)
+	}
+
+	return nil
+}
+
+/*
+GetFollowers 获取用户的粉丝列表 并标记其中与该用户互相关注的用户
+
+参数：
+  - userID：用户ID
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.FollowInfo：当前页的关注关系
+  - []models.UserInfo：粉丝信息 与关注关系顺序一致
+  - map[primitive.ObjectID]bool：互相关注的用户集合
+  - error：错误信息
+*/
+func (service *FollowService) GetFollowers(userID string, cursor primitive.ObjectID, limit int64) ([]models.FollowInfo, []models.UserInfo, map[primitive.ObjectID]bool, error) {
+	return service.getFollowList(userID, cursor, limit, true)
+}
+
+/*
+GetFollowing 获取用户的关注列表 并标记其中与该用户互相关注的用户
+
+参数：
+  - userID：用户ID
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.FollowInfo：当前页的关注关系
+  - []models.UserInfo：被关注者信息 与关注关系顺序一致
+  - map[primitive.ObjectID]bool：互相关注的用户集合
+  - error：错误信息
+*/
+func (service *FollowService) GetFollowing(userID string, cursor primitive.ObjectID, limit int64) ([]models.FollowInfo, []models.UserInfo, map[primitive.ObjectID]bool, error) {
+	return service.getFollowList(userID, cursor, limit, false)
+}
+
+/*
+GetRelation 获取访问者与目标用户之间的关注关系
+
+参数：
+  - viewerID：访问者ID
+  - targetID：目标用户ID
+
+返回：
+  - bool：访问者是否关注目标用户
+  - bool：目标用户是否关注访问者
+  - error：错误信息
+*/
+func (service *FollowService) GetRelation(viewerID primitive.ObjectID, targetID string) (bool, bool, error) {
+	// 转换用户ID
+	objID, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return false, false, types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return false, false, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var following, followedBy bool
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		following, err = service.Storage.FollowStorage.IsFollowing(sessionContext, viewerID, objID)
+		if err != nil {
+			return nil, err
+		}
+		followedBy, err = service.Storage.FollowStorage.IsFollowing(sessionContext, objID, viewerID)
+		return nil, err
+	})
+	if err != nil {
+		return false, false, err
+	}
+
+	return following, followedBy, nil
+}
+
+/*
+getFollowList 获取粉丝或关注列表
+
+参数：
+  - userID：用户ID
+  - cursor：游标
+  - limit：分页大小
+  - followers：为 true 时获取粉丝列表 否则获取关注列表
+
+返回：
+  - []models.FollowInfo：当前页的关注关系
+  - []models.UserInfo：对方用户信息 与关注关系顺序一致
+  - map[primitive.ObjectID]bool：互相关注的用户集合
+  - error：错误信息
+*/
+func (service *FollowService) getFollowList(userID string, cursor primitive.ObjectID, limit int64, followers bool) ([]models.FollowInfo, []models.UserInfo, map[primitive.ObjectID]bool, error) {
+	// 转换用户ID
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, nil, nil, types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, nil, nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var (
+		follows []models.FollowInfo
+		users   []models.UserInfo
+		mutual  map[primitive.ObjectID]bool
+	)
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取关注关系
+		if followers {
+			follows, err = service.Storage.FollowStorage.GetFollowers(sessionContext, objID, cursor, limit)
+		} else {
+			follows, err = service.Storage.FollowStorage.GetFollowing(sessionContext, objID, cursor, limit)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// 收集对方用户ID
+		otherIDs := make([]primitive.ObjectID, 0, len(follows))
+		for _, follow := range follows {
+			if followers {
+				otherIDs = append(otherIDs, follow.FollowerID)
+			} else {
+				otherIDs = append(otherIDs, follow.FolloweeID)
+			}
+		}
+
+		// 获取对方用户信息 并按关注关系顺序排列
+		result, err := service.Storage.UserStorage.GetUsersByIDs(sessionContext, otherIDs)
+		if err != nil {
+			return nil, err
+		}
+		userMap := make(map[primitive.ObjectID]models.UserInfo, len(result))
+		for _, user := range result {
+			userMap[user.ID] = user
+		}
+		users = make([]models.UserInfo, 0, len(otherIDs))
+		for _, otherID := range otherIDs {
+			if user, ok := userMap[otherID]; ok {
+				users = append(users, user)
+			}
+		}
+
+		// 检测互相关注
+		if followers {
+			mutual, err = service.Storage.FollowStorage.GetFollowedAmong(sessionContext, objID, otherIDs)
+		} else {
+			mutual, err = service.Storage.FollowStorage.GetFollowersAmong(sessionContext, objID, otherIDs)
+		}
+		return nil, err
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return follows, users, mutual, nil
+}
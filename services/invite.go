@@ -0,0 +1,209 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明邀请码相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/generators"
+)
+
+// RegistrationConfig 注册设置
+type RegistrationConfig struct {
+	Mode           string // 注册模式 open, invite, closed
+	InviteMinLevel uint64 // 可生成邀请码的最低用户等级
+}
+
+/*
+CreateInviteCode 生成邀请码 拥有邀请码管理权限的用户不受等级与数量限制
+
+参数：
+  - userID：用户ID
+  - maxUses：可使用次数 为 0 时使用默认值
+  - expiresInDays：有效天数 为 0 时使用默认值
+
+返回：
+  - models.InviteCode：邀请码信息
+  - error：错误信息
+*/
+func (service *UserService) CreateInviteCode(userID primitive.ObjectID, maxUses int64, expiresInDays int) (models.InviteCode, error) {
+	// 校验参数
+	if maxUses == 0 {
+		maxUses = consts.INVITE_CODE_DEFAULT_MAX_USES
+	}
+	if maxUses < 0 {
+		return models.InviteCode{}, types.NewError(types.ErrInvalidParams, "邀请码可使用次数不合法")
+	}
+	if expiresInDays == 0 {
+		expiresInDays = consts.INVITE_CODE_DEFAULT_EXPIRE_DAYS
+	}
+	if expiresInDays < 0 || expiresInDays > consts.INVITE_CODE_MAX_EXPIRE_DAYS {
+		return models.InviteCode{}, types.NewError(types.ErrInvalidParams, "邀请码有效天数不合法")
+	}
+
+	// 生成邀请码
+	code, err := generators.GenerateInviteCode()
+	if err != nil {
+		return models.InviteCode{}, types.NewError(types.ErrServerError, err.Error())
+	}
+	now := time.Now()
+	invite := models.InviteCode{
+		Code:      code,
+		CreatorID: userID,
+		MaxUses:   maxUses,
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, expiresInDays),
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return models.InviteCode{}, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		userInfo, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 普通用户需满足等级要求 且受次数与数量上限约束
+		if !slices.Contains(consts.ROLE_PERMISSIONS[userInfo.Authority], consts.PERMISSION_INVITE_MANAGE) {
+			if userInfo.Level < service.Registration.InviteMinLevel {
+				return nil, types.NewError(types.ErrAuthFailed, "用户等级不足 无法生成邀请码")
+			}
+			if maxUses > consts.INVITE_CODE_MAX_USES {
+				return nil, types.NewError(types.ErrInvalidParams, "邀请码可使用次数超过上限")
+			}
+			count, err := service.Storage.UserStorage.CountActiveInviteCodes(sessionContext, userID, now)
+			if err != nil {
+				return nil, err
+			}
+			if count >= consts.MAX_ACTIVE_INVITE_CODES_PER_USER {
+				return nil, types.NewError(types.ErrInvalidParams, "有效邀请码数量已达上限")
+			}
+		}
+
+		// 保存邀请码
+		invite.ID, err = service.Storage.UserStorage.CreateInviteCode(sessionContext, invite)
+		return nil, err
+	})
+	if err != nil {
+		return models.InviteCode{}, err
+	}
+
+	return invite, nil
+}
+
+/*
+ListInviteCodes 获取用户生成的邀请码列表
+
+参数：
+  - userID：用户ID
+
+返回：
+  - []models.InviteCode：邀请码列表
+  - error：错误信息
+*/
+func (service *UserService) ListInviteCodes(userID primitive.ObjectID) ([]models.InviteCode, error) {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var invites []models.InviteCode
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		invites, err = service.Storage.UserStorage.GetInviteCodesByCreator(sessionContext, userID)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+/*
+RevokeInviteCode 作废邀请码
+
+参数：
+  - userID：用户ID
+  - inviteID：邀请码ID
+
+返回：
+  - error：错误信息
+*/
+func (service *UserService) RevokeInviteCode(userID primitive.ObjectID, inviteID string) error {
+	objID, err := primitive.ObjectIDFromHex(inviteID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的邀请码ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		return nil, service.Storage.UserStorage.RevokeInviteCode(sessionContext, userID, objID)
+	})
+
+	return err
+}
+
+/*
+GetInvitees 获取用户邀请注册的用户列表
+
+参数：
+  - userID：用户ID
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.UserInfo：用户信息列表
+  - error：错误信息
+*/
+func (service *UserService) GetInvitees(userID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.UserInfo, error) {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var users []models.UserInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		users, err = service.Storage.UserStorage.GetInvitees(sessionContext, userID, cursor, limit)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,198 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明媒体文件相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+	"zewise.space/backend/utils/imagetools"
+)
+
+// MediaService 媒体文件服务
+type MediaService struct {
+	Storage *stores.Storage
+}
+
+/*
+UploadImage 上传图片 生成展示图与缩略图并记录媒体文件信息
+
+参数：
+  - userID：上传者ID
+  - imageFileHeader：图片文件
+
+返回：
+  - models.MediaInfo：媒体文件信息
+  - error：错误信息
+*/
+func (service *MediaService) UploadImage(userID primitive.ObjectID, imageFileHeader *multipart.FileHeader) (models.MediaInfo, error) {
+	mediaInfo := models.MediaInfo{}
+
+	if imageFileHeader.Size > consts.MEDIA_MAX_FILE_SIZE {
+		return mediaInfo, types.NewError(types.ErrInvalidParams, "图片文件过大")
+	}
+
+	// 处理图片文件
+	contentType := imageFileHeader.Header.Get("Content-Type")
+	decoder := imagetools.NewDefaultImageDecoderChain()
+	decoder.SetContentType(contentType)
+	sizeLimiter := imagetools.NewSizeLimiter(consts.MEDIA_MAX_SIZE, consts.MEDIA_MAX_SIZE)
+	encoder := imagetools.NewWebpImageEncoder(consts.MEDIA_QUALITY)
+	thumbnailEncoder := imagetools.NewWebpImageEncoder(consts.MEDIA_THUMBNAIL_QUALITY)
+
+	imageFile, err := imageFileHeader.Open()
+	if err != nil {
+		return mediaInfo, types.NewError(types.ErrInvalidParams, "不合法的图片文件")
+	}
+	defer imageFile.Close()
+
+	originalConfig, images, err := imagetools.ProcessImageVariants(
+		imageFile,
+		decoder,
+		imagetools.ImageVariant{
+			Encoder: encoder,
+			ProcessHandlers: []imagetools.ImageProcessHandler{
+				sizeLimiter,
+				imagetools.NewResizeProcessHandler(consts.MEDIA_DISPLAY_SIZE, consts.MEDIA_DISPLAY_SIZE, &imagetools.ScallingDownProcessor{}),
+			},
+		},
+		imagetools.ImageVariant{
+			Encoder: thumbnailEncoder,
+			ProcessHandlers: []imagetools.ImageProcessHandler{
+				sizeLimiter,
+				imagetools.NewResizeProcessHandler(consts.MEDIA_THUMBNAIL_SIZE, consts.MEDIA_THUMBNAIL_SIZE, &imagetools.ScallingDownProcessor{}),
+			},
+		},
+	)
+	if err != nil {
+		return mediaInfo, types.NewError(types.ErrInvalidParams, err.Error())
+	}
+	display, thumbnail := images[0], images[1]
+
+	mediaInfo = models.MediaInfo{
+		ID:               primitive.NewObjectID(),
+		UID:              userID,
+		MimeType:         "image/webp",
+		OriginalMimeType: contentType,
+		OriginalWidth:    originalConfig.Width,
+		OriginalHeight:   originalConfig.Height,
+		Width:            display.Config.Width,
+		Height:           display.Config.Height,
+		Size:             int64(len(display.Data)),
+		ThumbnailWidth:   thumbnail.Config.Width,
+		ThumbnailHeight:  thumbnail.Config.Height,
+		ThumbnailSize:    int64(len(thumbnail.Data)),
+	}
+	fileName := functools.JoinStrings(mediaInfo.ID.Hex(), ".", encoder.GetFormatFileSuffix())
+	thumbnailFileName := functools.JoinStrings(mediaInfo.ID.Hex(), consts.MEDIA_THUMBNAIL_SUFFIX, ".", thumbnailEncoder.GetFormatFileSuffix())
+
+	// 上传图片文件
+	_, err = service.Storage.MediaStorage.UploadMediaFile(
+		context.Background(), fileName, bytes.NewReader(display.Data), mediaInfo.Size, mediaInfo.MimeType,
+	)
+	if err != nil {
+		return mediaInfo, err
+	}
+	_, err = service.Storage.MediaStorage.UploadMediaFile(
+		context.Background(), thumbnailFileName, bytes.NewReader(thumbnail.Data), mediaInfo.ThumbnailSize, mediaInfo.MimeType,
+	)
+	if err != nil {
+		_ = service.Storage.MediaStorage.DeleteMediaFile(context.Background(), fileName)
+		return mediaInfo, err
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return mediaInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 记录媒体文件信息
+		err := service.Storage.MediaStorage.CreateMedia(sessionContext, mediaInfo)
+		return nil, err
+	})
+	if err != nil {
+		// 记录失败时清理已上传的文件
+		_ = service.Storage.MediaStorage.DeleteMediaFile(context.Background(), fileName)
+		_ = service.Storage.MediaStorage.DeleteMediaFile(context.Background(), thumbnailFileName)
+		return mediaInfo, err
+	}
+
+	return mediaInfo, nil
+}
+
+/*
+parseMediaIDs 解析博文引用的媒体ID列表
+
+参数：
+  - rawIDs：媒体ID字符串列表
+
+返回：
+  - []primitive.ObjectID：去重后的媒体ID列表
+  - error：错误信息
+*/
+func parseMediaIDs(rawIDs []string) ([]primitive.ObjectID, error) {
+	if len(rawIDs) > consts.MAX_MEDIA_PER_POST {
+		return nil, types.NewError(types.ErrInvalidParams, "媒体文件数量超出限制")
+	}
+
+	mediaIDs := make([]primitive.ObjectID, 0, len(rawIDs))
+	seen := make(map[primitive.ObjectID]bool, len(rawIDs))
+	for _, rawID := range rawIDs {
+		mediaID, err := primitive.ObjectIDFromHex(rawID)
+		if err != nil {
+			return nil, types.NewError(types.ErrInvalidParams, "不合法的媒体ID")
+		}
+		if !seen[mediaID] {
+			seen[mediaID] = true
+			mediaIDs = append(mediaIDs, mediaID)
+		}
+	}
+
+	return mediaIDs, nil
+}
+
+/*
+checkMediaOwnership 校验媒体文件是否均属于指定用户
+
+参数：
+  - storage：存储对象
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - mediaIDs：媒体ID列表
+
+返回：
+  - error：错误信息
+*/
+func checkMediaOwnership(storage *stores.Storage, sessionContext mongo.SessionContext, userID primitive.ObjectID, mediaIDs []primitive.ObjectID) error {
+	if len(mediaIDs) == 0 {
+		return nil
+	}
+
+	count, err := storage.MediaStorage.CountUserMedia(sessionContext, userID, mediaIDs)
+	if err != nil {
+		return err
+	}
+	if count != int64(len(mediaIDs)) {
+		return types.NewError(types.ErrInvalidParams, "媒体文件不存在")
+	}
+
+	return nil
+}
@@ -0,0 +1,729 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明博文相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/validers"
+)
+
+// PostService 博文服务
+type PostService struct {
+	Storage *stores.Storage
+}
+
+/*
+CreatePost 发布博文
+
+参数：
+  - userID：作者ID
+  - ip：发布者 IP 地址
+  - reqBody：请求体
+
+返回：
+  - models.PostInfo：博文信息
+  - error：错误信息
+*/
+func (service *PostService) CreatePost(userID primitive.ObjectID, ip string, reqBody parsers.PostCreateBody) (models.PostInfo, error) {
+	// 校验参数
+	if !validers.IsValidPostTitle(reqBody.Title) {
+		return models.PostInfo{}, types.NewError(types.ErrInvalidParams, "不合法的标题")
+	}
+	mediaIDs, err := parseMediaIDs(reqBody.MediaIDs)
+	if err != nil {
+		return models.PostInfo{}, err
+	}
+	// 附带媒体文件时允许正文为空
+	if !validers.IsValidPostContent(reqBody.Content) && !(reqBody.Content == "" && len(mediaIDs) > 0) {
+		return models.PostInfo{}, types.NewError(types.ErrInvalidParams, "不合法的内容")
+	}
+
+	postInfo := models.PostInfo{
+		UID:        userID,
+		IpAddrress: ip,
+		Title:      reqBody.Title,
+		Content:    reqBody.Content,
+		MediaIDs:   mediaIDs,
+		IsPublic:   reqBody.IsPublic,
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return postInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验媒体文件
+		err := checkMediaOwnership(service.Storage, sessionContext, userID, mediaIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// 创建博文
+		postID, err := service.Storage.PostStorage.CreatePost(sessionContext, postInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		// 读取完整的博文信息
+		postInfo, err = service.Storage.PostStorage.GetPostByID(sessionContext, postID)
+		return nil, err
+	})
+	if err != nil {
+		return postInfo, err
+	}
+
+	// 推送到时间线 推送失败不影响发布结果
+	err = fanOutPost(service.Storage, postInfo)
+	if err != nil {
+		log.Printf("推送时间线失败: %v", err)
+	}
+
+	return postInfo, nil
+}
+
+/*
+GetPost 获取博文 非公开博文仅作者可见
+
+参数：
+  - postID：博文ID
+  - viewerID：访问者ID 未登录时为空
+
+返回：
+  - models.PostInfo：博文信息
+  - error：错误信息
+*/
+func (service *PostService) GetPost(postID string, viewerID primitive.ObjectID) (models.PostInfo, error) {
+	postInfo := models.PostInfo{}
+
+	// 转换博文ID
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return postInfo, types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return postInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取博文信息
+		postInfo, err = getVisiblePost(service.Storage, sessionContext, objID, viewerID)
+		return nil, err
+	})
+	if err != nil {
+		return postInfo, err
+	}
+
+	return postInfo, nil
+}
+
+/*
+getVisiblePost 获取访问者可见的博文 非公开博文对作者以外的用户表现为不存在
+
+参数：
+  - storage：存储对象
+  - sessionContext：数据库会话上下文
+  - postID：博文ID
+  - viewerID：访问者ID 未登录时为空
+
+返回：
+  - models.PostInfo：博文信息
+  - error：错误信息
+*/
+func getVisiblePost(storage *stores.Storage, sessionContext mongo.SessionContext, postID primitive.ObjectID, viewerID primitive.ObjectID) (models.PostInfo, error) {
+	postInfo, err := storage.PostStorage.GetPostByID(sessionContext, postID)
+	if err != nil {
+		return postInfo, err
+	}
+	if !postInfo.IsPublic && postInfo.UID != viewerID {
+		return models.PostInfo{}, types.NewError(types.ErrInvalidParams, "博文不存在")
+	}
+
+	return postInfo, nil
+}
+
+/*
+GetUserPosts 获取用户博文列表 作者本人可以看到非公开博文
+
+参数：
+  - userID：作者ID
+  - viewerID：访问者ID 未登录时为空
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.PostInfo：博文列表
+  - error：错误信息
+*/
+func (service *PostService) GetUserPosts(userID string, viewerID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.PostInfo, error) {
+	// 转换用户ID
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, types.NewError(types.ErrInvalidParams, "不合法的用户ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var posts []models.PostInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取博文列表
+		posts, err = service.Storage.PostStorage.GetPostsByUser(sessionContext, objID, cursor, limit, objID == viewerID)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+/*
+UpdatePost 更新博文 仅作者可以操作
+
+参数：
+  - userID：操作者ID
+  - reqBody：请求体
+
+返回：
+  - error：错误信息
+*/
+func (service *PostService) UpdatePost(userID primitive.ObjectID, reqBody parsers.PostUpdateBody) error {
+	// 转换博文ID
+	postID, err := primitive.ObjectIDFromHex(reqBody.ID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+
+	// 构造更新字段
+	fields := bson.M{}
+	if reqBody.Title != nil {
+		if !validers.IsValidPostTitle(*reqBody.Title) {
+			return types.NewError(types.ErrInvalidParams, "不合法的标题")
+		}
+		fields["title"] = *reqBody.Title
+	}
+	if reqBody.Content != nil {
+		// 正文为空时需在合并后确认博文附带媒体文件
+		if *reqBody.Content != "" && !validers.IsValidPostContent(*reqBody.Content) {
+			return types.NewError(types.ErrInvalidParams, "不合法的内容")
+		}
+		fields["content"] = *reqBody.Content
+	}
+	mediaIDs := []primitive.ObjectID{}
+	if reqBody.MediaIDs != nil {
+		mediaIDs, err = parseMediaIDs(*reqBody.MediaIDs)
+		if err != nil {
+			return err
+		}
+		fields["media_ids"] = mediaIDs
+	}
+	if reqBody.IsPublic != nil {
+		fields["is_public"] = *reqBody.IsPublic
+	}
+	if len(fields) == 0 {
+		return types.NewError(types.ErrInvalidParams, "没有需要更新的内容")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验作者
+		postInfo, err := service.Storage.PostStorage.GetPostByID(sessionContext, postID)
+		if err != nil {
+			return nil, err
+		}
+		if postInfo.UID != userID {
+			return nil, types.NewError(types.ErrAuthFailed, "无权修改该博文")
+		}
+		if isRepost(postInfo) {
+			return nil, types.NewError(types.ErrInvalidParams, "转发不能修改")
+		}
+
+		// 与发布时一致 正文与媒体文件不能同时为空
+		content := postInfo.Content
+		if reqBody.Content != nil {
+			content = *reqBody.Content
+		}
+		mediaCount := len(postInfo.MediaIDs)
+		if reqBody.MediaIDs != nil {
+			mediaCount = len(mediaIDs)
+		}
+		if content == "" && mediaCount == 0 {
+			return nil, types.NewError(types.ErrInvalidParams, "不合法的内容")
+		}
+
+		// 校验媒体文件
+		err = checkMediaOwnership(service.Storage, sessionContext, userID, mediaIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新博文
+		err = service.Storage.PostStorage.UpdatePost(sessionContext, postID, fields)
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+DeletePost 删除博文 仅作者可以操作
+
+参数：
+  - userID：操作者ID
+  - postID：博文ID
+
+返回：
+  - error：错误信息
+*/
+func (service *PostService) DeletePost(userID primitive.ObjectID, postID string) error {
+	// 转换博文ID
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验作者
+		postInfo, err := service.Storage.PostStorage.GetPostByID(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+		if postInfo.UID != userID {
+			return nil, types.NewError(types.ErrAuthFailed, "无权删除该博文")
+		}
+
+		// 删除博文
+		err = service.Storage.PostStorage.DeletePost(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新被转发博文的转发数
+		if !postInfo.ParentPostID.IsZero() {
+			err = service.Storage.PostStorage.IncreaseRepostCount(sessionContext, postInfo.ParentPostID, -1)
+		}
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+RepostPost 直接转发博文 转发一条直接转发时实际转发其原博文
+
+参数：
+  - userID：用户ID
+  - ip：转发者 IP 地址
+  - postID：被转发的博文ID
+
+返回：
+  - models.PostInfo：转发博文信息
+  - error：错误信息
+*/
+func (service *PostService) RepostPost(userID primitive.ObjectID, ip string, postID string) (models.PostInfo, error) {
+	postInfo := models.PostInfo{}
+
+	// 转换博文ID
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return postInfo, types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return postInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取转发目标
+		target, err := getRepostTarget(service.Storage, sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 不允许重复转发
+		_, err = service.Storage.PostStorage.GetUserRepost(sessionContext, userID, target.ID)
+		if err == nil {
+			return nil, types.NewError(types.ErrInvalidParams, "已经转发过该博文")
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+
+		// 创建转发
+		repostID, err := service.Storage.PostStorage.CreatePost(sessionContext, models.PostInfo{
+			ParentPostID: target.ID,
+			UID:          userID,
+			IpAddrress:   ip,
+			IsPublic:     true,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新转发数
+		err = service.Storage.PostStorage.IncreaseRepostCount(sessionContext, target.ID, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		postInfo, err = service.Storage.PostStorage.GetPostByID(sessionContext, repostID)
+		return nil, err
+	})
+	if err != nil {
+		return postInfo, err
+	}
+
+	// 推送到时间线 推送失败不影响发布结果
+	err = fanOutPost(service.Storage, postInfo)
+	if err != nil {
+		log.Printf("推送时间线失败: %v", err)
+	}
+
+	return postInfo, nil
+}
+
+/*
+UndoRepost 取消直接转发
+
+参数：
+  - userID：用户ID
+  - postID：被转发的博文ID
+
+返回：
+  - error：错误信息
+*/
+func (service *PostService) UndoRepost(userID primitive.ObjectID, postID string) error {
+	// 转换博文ID
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取转发
+		repost, err := service.Storage.PostStorage.GetUserRepost(sessionContext, userID, objID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, types.NewError(types.ErrInvalidParams, "尚未转发该博文")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// 删除转发并更新转发数
+		err = service.Storage.PostStorage.DeletePost(sessionContext, repost.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = service.Storage.PostStorage.IncreaseRepostCount(sessionContext, objID, -1)
+		return nil, err
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+QuotePost 引用转发博文 引用一条直接转发时实际引用其原博文
+
+参数：
+  - userID：用户ID
+  - ip：转发者 IP 地址
+  - reqBody：请求体
+
+返回：
+  - models.PostInfo：引用博文信息
+  - error：错误信息
+*/
+func (service *PostService) QuotePost(userID primitive.ObjectID, ip string, reqBody parsers.PostQuoteBody) (models.PostInfo, error) {
+	postInfo := models.PostInfo{}
+
+	// 校验参数
+	objID, err := primitive.ObjectIDFromHex(reqBody.ID)
+	if err != nil {
+		return postInfo, types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+	if !validers.IsValidPostTitle(reqBody.Title) {
+		return postInfo, types.NewError(types.ErrInvalidParams, "不合法的标题")
+	}
+	if !validers.IsValidPostContent(reqBody.Content) {
+		return postInfo, types.NewError(types.ErrInvalidParams, "不合法的内容")
+	}
+	mediaIDs, err := parseMediaIDs(reqBody.MediaIDs)
+	if err != nil {
+		return postInfo, err
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return postInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取引用目标
+		target, err := getRepostTarget(service.Storage, sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 校验媒体文件
+		err = checkMediaOwnership(service.Storage, sessionContext, userID, mediaIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// 创建引用
+		quoteID, err := service.Storage.PostStorage.CreatePost(sessionContext, models.PostInfo{
+			ParentPostID: target.ID,
+			UID:          userID,
+			IpAddrress:   ip,
+			Title:        reqBody.Title,
+			Content:      reqBody.Content,
+			MediaIDs:     mediaIDs,
+			IsPublic:     reqBody.IsPublic,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// 更新转发数
+		err = service.Storage.PostStorage.IncreaseRepostCount(sessionContext, target.ID, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		postInfo, err = service.Storage.PostStorage.GetPostByID(sessionContext, quoteID)
+		return nil, err
+	})
+	if err != nil {
+		return postInfo, err
+	}
+
+	// 推送到时间线 推送失败不影响发布结果
+	err = fanOutPost(service.Storage, postInfo)
+	if err != nil {
+		log.Printf("推送时间线失败: %v", err)
+	}
+
+	return postInfo, nil
+}
+
+/*
+GetReposts 获取博文的转发列表
+
+参数：
+  - postID：博文ID
+  - viewerID：访问者ID 未登录时为空
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.PostInfo：转发列表
+  - error：错误信息
+*/
+func (service *PostService) GetReposts(postID string, viewerID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.PostInfo, error) {
+	// 转换博文ID
+	objID, err := primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, types.NewError(types.ErrInvalidParams, "不合法的博文ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var posts []models.PostInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 校验博文是否可见
+		_, err := getVisiblePost(service.Storage, sessionContext, objID, viewerID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 获取转发列表
+		posts, err = service.Storage.PostStorage.GetReposts(sessionContext, objID, cursor, limit)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+/*
+ResolveParentPosts 解析博文列表中被转发的原博文
+已删除或对访问者不可见的原博文不会出现在结果中
+
+参数：
+  - posts：博文列表
+  - viewerID：访问者ID 未登录时为空
+
+返回：
+  - map[primitive.ObjectID]models.PostInfo：原博文ID到原博文的映射
+  - error：错误信息
+*/
+func (service *PostService) ResolveParentPosts(posts []models.PostInfo, viewerID primitive.ObjectID) (map[primitive.ObjectID]models.PostInfo, error) {
+	parents := map[primitive.ObjectID]models.PostInfo{}
+
+	// 收集原博文ID
+	parentIDs := []primitive.ObjectID{}
+	for _, post := range posts {
+		if !post.ParentPostID.IsZero() {
+			parentIDs = append(parentIDs, post.ParentPostID)
+		}
+	}
+	if len(parentIDs) == 0 {
+		return parents, nil
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return parents, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		result, err := service.Storage.PostStorage.GetPostsByIDs(sessionContext, parentIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range result {
+			if parent.IsPublic || parent.UID == viewerID {
+				parents[parent.ID] = parent
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return parents, err
+	}
+
+	return parents, nil
+}
+
+/*
+getRepostTarget 获取转发或引用的目标博文 目标为直接转发时返回其原博文
+只有公开博文可以被转发
+
+参数：
+  - storage：存储对象
+  - sessionContext：数据库会话上下文
+  - postID：博文ID
+
+返回：
+  - models.PostInfo：目标博文
+  - error：错误信息
+*/
+func getRepostTarget(storage *stores.Storage, sessionContext mongo.SessionContext, postID primitive.ObjectID) (models.PostInfo, error) {
+	target, err := storage.PostStorage.GetPostByID(sessionContext, postID)
+	if err != nil {
+		return target, err
+	}
+	if isRepost(target) {
+		target, err = storage.PostStorage.GetPostByID(sessionContext, target.ParentPostID)
+		if err != nil {
+			return target, types.NewError(types.ErrInvalidParams, "原博文已删除")
+		}
+	}
+	if !target.IsPublic {
+		return target, types.NewError(types.ErrInvalidParams, "非公开博文不能转发")
+	}
+
+	return target, nil
+}
+
+/*
+isRepost 判断博文是否为直接转发 直接转发没有正文 引用转发有正文
+
+参数：
+  - postInfo：博文信息
+
+返回：
+  - bool：是否为直接转发
+*/
+func isRepost(postInfo models.PostInfo) bool {
+	return !postInfo.ParentPostID.IsZero() && postInfo.Content == ""
+}
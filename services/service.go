@@ -6,13 +6,23 @@ Copyright (c) [2024], Author(s):
 */
 package services
 
-import "zewise.space/backend/stores"
+import (
+	"zewise.space/backend/stores"
+	"zewise.space/backend/utils/mailers"
+)
 
 // Service 服务对象
 type Service struct {
-	storage     *stores.Storage // 存储对象
-	UserService *UserService    // 用户服务
-	AuthService *AuthService    // 认证服务
+	storage         *stores.Storage  // 存储对象
+	UserService     *UserService     // 用户服务
+	AuthService     *AuthService     // 认证服务
+	PostService     *PostService     // 博文服务
+	CommentService  *CommentService  // 评论服务
+	MediaService    *MediaService    // 媒体文件服务
+	FollowService   *FollowService   // 关注关系服务
+	TimelineService *TimelineService // 时间线服务
+	AdminService    *AdminService    // 管理服务
+	ExportService   *ExportService   // 个人数据导出服务
 }
 
 /*
@@ -20,14 +30,24 @@ NewService 新建服务对象
 
 参数：
   - storage：存储对象
+  - mailer：邮件发送器
+  - registration：注册设置
+  - deletion：账号注销设置
 
 返回：
   - *Service：服务对象
 */
-func NewService(storage *stores.Storage) *Service {
+func NewService(storage *stores.Storage, mailer mailers.Mailer, registration RegistrationConfig, deletion AccountDeletionConfig) *Service {
 	return &Service{
-		storage:     storage,
-		UserService: &UserService{storage},
-		AuthService: &AuthService{storage},
+		storage:         storage,
+		UserService:     &UserService{storage, registration, deletion},
+		AuthService:     &AuthService{storage, mailer},
+		PostService:     &PostService{storage},
+		CommentService:  &CommentService{storage},
+		MediaService:    &MediaService{storage},
+		FollowService:   &FollowService{storage},
+		TimelineService: &TimelineService{storage},
+		AdminService:    &AdminService{storage},
+		ExportService:   &ExportService{storage},
 	}
 }
@@ -0,0 +1,120 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明会话管理相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+/*
+ListSessions 获取用户当前有效的会话 按创建时间倒序
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - []models.SessionInfo：会话列表
+  - error：错误信息
+*/
+func (service *AuthService) ListSessions(userID string) ([]models.SessionInfo, error) {
+	return service.Storage.AuthStorage.GetSessions(userID)
+}
+
+/*
+RevokeSession 吊销指定会话
+
+参数：
+  - userID：用户 ID
+  - sessionID：会话 ID
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) RevokeSession(userID string, sessionID string) error {
+	// 获取会话列表
+	sessions, err := service.Storage.AuthStorage.GetSessions(userID)
+	if err != nil {
+		return err
+	}
+
+	// 查找并吊销对应会话
+	for _, session := range sessions {
+		if session.ID == sessionID {
+			return service.Storage.AuthStorage.RemoveSessions(userID, sessionID)
+		}
+	}
+
+	return types.NewError(types.ErrInvalidParams, "会话不存在")
+}
+
+/*
+RevokeOtherSessions 吊销除当前会话外的全部会话
+
+参数：
+  - userID：用户 ID
+  - currentSessionID：当前会话 ID
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) RevokeOtherSessions(userID string, currentSessionID string) error {
+	// 获取会话列表
+	sessions, err := service.Storage.AuthStorage.GetSessions(userID)
+	if err != nil {
+		return err
+	}
+
+	// 吊销其余会话
+	sessionIDs := []string{}
+	for _, session := range sessions {
+		if session.ID != currentSessionID {
+			sessionIDs = append(sessionIDs, session.ID)
+		}
+	}
+
+	return service.Storage.AuthStorage.RemoveSessions(userID, sessionIDs...)
+}
+
+/*
+GetLoginHistory 分页获取用户的登录历史 包含成功与失败的登录尝试
+
+参数：
+  - userID：用户 ID
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.UserLoginLog：登录日志列表
+  - error：错误信息
+*/
+func (service *AuthService) GetLoginHistory(userID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.UserLoginLog, error) {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var logs []models.UserLoginLog
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		logs, err = service.Storage.AuthStorage.GetLoginLogs(sessionContext, userID, cursor, limit)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,352 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明时间线相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/stores"
+	"zewise.space/backend/types"
+)
+
+// TimelineService 时间线服务
+type TimelineService struct {
+	Storage *stores.Storage
+}
+
+/*
+GetHomeTimeline 获取首页时间线
+
+普通用户的博文在发布时推送到粉丝的时间线缓存中 粉丝数达到阈值的用户的博文则在读取时从数据库拉取 两者合并后按发布时间倒序返回
+
+参数：
+  - viewerID：用户ID
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.PostInfo：博文列表
+  - primitive.ObjectID：下一页游标 没有更多博文时为空
+  - error：错误信息
+*/
+func (service *TimelineService) GetHomeTimeline(viewerID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.PostInfo, primitive.ObjectID, error) {
+	// 时间线缓存未重建时重建 已重建时延长有效期
+	ctx := context.Background()
+	exists, err := service.Storage.TimelineStorage.TouchTimeline(ctx, viewerID)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+	if !exists {
+		err = rebuildTimeline(service.Storage, viewerID)
+		if err != nil {
+			return nil, primitive.NilObjectID, err
+		}
+	}
+
+	// 读取推送的博文ID
+	pushedIDs, err := service.Storage.TimelineStorage.GetTimeline(ctx, viewerID, cursor, limit)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	// 创建数据库会话
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, primitive.NilObjectID, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var (
+		posts      []models.PostInfo
+		nextCursor primitive.ObjectID
+	)
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 拉取所关注的高粉丝数用户的博文
+		followingIDs, err := service.Storage.FollowStorage.GetFollowingIDs(sessionContext, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		popularIDs, err := service.Storage.UserStorage.FilterPopularUsers(sessionContext, followingIDs, consts.TIMELINE_FANOUT_THRESHOLD)
+		if err != nil {
+			return nil, err
+		}
+		pulledPosts, err := service.Storage.PostStorage.GetPublicPostsByUsers(sessionContext, popularIDs, cursor, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		// 读取推送的博文 已删除的博文不会返回
+		pushedPosts, err := service.Storage.PostStorage.GetPostsByIDs(sessionContext, pushedIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		// 合并两部分博文ID 并按发布时间倒序截取一页
+		postMap := make(map[primitive.ObjectID]models.PostInfo, len(pushedPosts)+len(pulledPosts))
+		candidates := make([]primitive.ObjectID, 0, len(pushedIDs)+len(pulledPosts))
+		seen := make(map[primitive.ObjectID]bool, len(pushedIDs)+len(pulledPosts))
+		for _, post := range pushedPosts {
+			postMap[post.ID] = post
+		}
+		for _, post := range pulledPosts {
+			postMap[post.ID] = post
+		}
+		for _, postID := range pushedIDs {
+			if !seen[postID] {
+				seen[postID] = true
+				candidates = append(candidates, postID)
+			}
+		}
+		for _, post := range pulledPosts {
+			if !seen[post.ID] {
+				seen[post.ID] = true
+				candidates = append(candidates, post.ID)
+			}
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			return bytes.Compare(candidates[i][:], candidates[j][:]) > 0
+		})
+		if int64(len(candidates)) > limit {
+			candidates = candidates[:limit]
+		}
+		if len(candidates) > 0 && int64(len(candidates)) == limit {
+			nextCursor = candidates[len(candidates)-1]
+		}
+
+		// 过滤已删除或已设为非公开的博文
+		posts = make([]models.PostInfo, 0, len(candidates))
+		for _, postID := range candidates {
+			post, ok := postMap[postID]
+			if !ok || (!post.IsPublic && post.UID != viewerID) {
+				continue
+			}
+			posts = append(posts, post)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	return posts, nextCursor, nil
+}
+
+/*
+fanOutPost 将新发布的博文推送到作者及其粉丝的时间线 粉丝数达到阈值时仅推送给作者本人
+
+参数：
+  - storage：存储对象
+  - postInfo：博文信息
+
+返回：
+  - error：错误信息
+*/
+func fanOutPost(storage *stores.Storage, postInfo models.PostInfo) error {
+	recipients := []primitive.ObjectID{postInfo.UID}
+
+	// 非公开博文仅作者可见
+	if postInfo.IsPublic {
+		// 创建数据库会话
+		ctx := context.Background()
+		session, err := storage.NewSession()
+		if err != nil {
+			return types.NewError(types.ErrServerError, err.Error())
+		}
+		defer session.EndSession(ctx)
+
+		// 开启事务
+		_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+			author, err := storage.UserStorage.GetUserDataByID(sessionContext, postInfo.UID)
+			if err != nil {
+				return nil, err
+			}
+			if author.FollowerCount >= consts.TIMELINE_FANOUT_THRESHOLD {
+				return nil, nil
+			}
+
+			followerIDs, err := storage.FollowStorage.GetFollowerIDs(sessionContext, postInfo.UID)
+			if err != nil {
+				return nil, err
+			}
+			recipients = append(recipients, followerIDs...)
+			return nil, nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return storage.TimelineStorage.PushPosts(context.Background(), recipients, []primitive.ObjectID{postInfo.ID})
+}
+
+/*
+backfillTimeline 关注用户后将其近期博文补充到关注者的时间线 高粉丝数用户的博文在读取时拉取 无需补充
+
+参数：
+  - storage：存储对象
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+
+返回：
+  - error：错误信息
+*/
+func backfillTimeline(storage *stores.Storage, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	// 时间线缓存未重建时 读取时会完整重建
+	ctx := context.Background()
+	exists, err := storage.TimelineStorage.HasTimeline(ctx, followerID)
+	if err != nil || !exists {
+		return err
+	}
+
+	// 创建数据库会话
+	session, err := storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var postIDs []primitive.ObjectID
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		followee, err := storage.UserStorage.GetUserDataByID(sessionContext, followeeID)
+		if err != nil {
+			return nil, err
+		}
+		if followee.FollowerCount >= consts.TIMELINE_FANOUT_THRESHOLD {
+			return nil, nil
+		}
+
+		posts, err := storage.PostStorage.GetPostsByUser(sessionContext, followeeID, primitive.NilObjectID, consts.TIMELINE_BACKFILL_SIZE, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			postIDs = append(postIDs, post.ID)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return storage.TimelineStorage.PushPosts(ctx, []primitive.ObjectID{followerID}, postIDs)
+}
+
+/*
+pruneTimeline 取消关注后从关注者的时间线中移除被关注者的博文
+
+参数：
+  - storage：存储对象
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+
+返回：
+  - error：错误信息
+*/
+func pruneTimeline(storage *stores.Storage, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var postIDs []primitive.ObjectID
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		posts, err := storage.PostStorage.GetPostsByUser(sessionContext, followeeID, primitive.NilObjectID, consts.TIMELINE_MAX_LENGTH, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range posts {
+			postIDs = append(postIDs, post.ID)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return storage.TimelineStorage.RemovePosts(ctx, followerID, postIDs)
+}
+
+/*
+rebuildTimeline 从数据库重建用户的时间线缓存 包含本人博文与所关注的普通用户的公开博文
+
+参数：
+  - storage：存储对象
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func rebuildTimeline(storage *stores.Storage, userID primitive.ObjectID) error {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var postIDs []primitive.ObjectID
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		postIDs = nil
+
+		// 本人博文
+		ownPosts, err := storage.PostStorage.GetPostsByUser(sessionContext, userID, primitive.NilObjectID, consts.TIMELINE_MAX_LENGTH, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range ownPosts {
+			postIDs = append(postIDs, post.ID)
+		}
+
+		// 所关注的普通用户的博文
+		followingIDs, err := storage.FollowStorage.GetFollowingIDs(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		popularIDs, err := storage.UserStorage.FilterPopularUsers(sessionContext, followingIDs, consts.TIMELINE_FANOUT_THRESHOLD)
+		if err != nil {
+			return nil, err
+		}
+		popular := make(map[primitive.ObjectID]bool, len(popularIDs))
+		for _, popularID := range popularIDs {
+			popular[popularID] = true
+		}
+		normalIDs := make([]primitive.ObjectID, 0, len(followingIDs))
+		for _, followingID := range followingIDs {
+			if !popular[followingID] {
+				normalIDs = append(normalIDs, followingID)
+			}
+		}
+		followingPosts, err := storage.PostStorage.GetPublicPostsByUsers(sessionContext, normalIDs, primitive.NilObjectID, consts.TIMELINE_MAX_LENGTH)
+		if err != nil {
+			return nil, err
+		}
+		for _, post := range followingPosts {
+			postIDs = append(postIDs, post.ID)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return storage.TimelineStorage.ReplaceTimeline(ctx, userID, postIDs)
+}
@@ -0,0 +1,169 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明个人访问令牌相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/encryptors"
+	"zewise.space/backend/utils/generators"
+)
+
+/*
+CreatePersonalAccessToken 创建个人访问令牌 令牌明文仅在创建时返回一次
+
+参数：
+  - userID：用户ID
+  - name：令牌名称
+  - scopes：权限范围
+  - expiresInDays：有效天数 为 0 时使用默认值
+
+返回：
+  - string：令牌明文
+  - models.PersonalAccessTokenInfo：令牌信息
+  - error：错误信息
+*/
+func (service *AuthService) CreatePersonalAccessToken(userID primitive.ObjectID, name string, scopes []string, expiresInDays int) (string, models.PersonalAccessTokenInfo, error) {
+	// 校验参数
+	if name == "" || utf8.RuneCountInString(name) > consts.PERSONAL_ACCESS_TOKEN_NAME_MAX_LENGTH {
+		return "", models.PersonalAccessTokenInfo{}, types.NewError(types.ErrInvalidParams, "令牌名称长度不合法")
+	}
+	if len(scopes) == 0 {
+		return "", models.PersonalAccessTokenInfo{}, types.NewError(types.ErrInvalidParams, "需要提供至少一个权限范围")
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(consts.PERSONAL_ACCESS_TOKEN_SCOPES, scope) {
+			return "", models.PersonalAccessTokenInfo{}, types.NewError(types.ErrInvalidParams, "未知的权限范围："+scope)
+		}
+	}
+	if expiresInDays == 0 {
+		expiresInDays = consts.PERSONAL_ACCESS_TOKEN_DEFAULT_EXPIRE_DAYS
+	}
+	if expiresInDays < 0 || expiresInDays > consts.PERSONAL_ACCESS_TOKEN_MAX_EXPIRE_DAYS {
+		return "", models.PersonalAccessTokenInfo{}, types.NewError(types.ErrInvalidParams, "令牌有效天数不合法")
+	}
+
+	// 生成令牌
+	token, err := generators.GeneratePersonalAccessToken()
+	if err != nil {
+		return "", models.PersonalAccessTokenInfo{}, types.NewError(types.ErrServerError, err.Error())
+	}
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	now := time.Now()
+	tokenInfo := models.PersonalAccessTokenInfo{
+		UID:       userID,
+		Name:      name,
+		TokenHash: encryptors.HashToken(token),
+		Hint:      token[len(token)-consts.PERSONAL_ACCESS_TOKEN_HINT_LENGTH:],
+		Scopes:    slices.Compact(scopes),
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, expiresInDays),
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return "", models.PersonalAccessTokenInfo{}, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 检查令牌数量上限
+		count, err := service.Storage.AuthStorage.CountPersonalAccessTokens(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= consts.MAX_PERSONAL_ACCESS_TOKENS_PER_USER {
+			return nil, types.NewError(types.ErrInvalidParams, "个人访问令牌数量已达上限")
+		}
+
+		// 保存令牌
+		tokenInfo.ID, err = service.Storage.AuthStorage.CreatePersonalAccessToken(sessionContext, tokenInfo)
+		return nil, err
+	})
+	if err != nil {
+		return "", models.PersonalAccessTokenInfo{}, err
+	}
+
+	return token, tokenInfo, nil
+}
+
+/*
+ListPersonalAccessTokens 获取用户的个人访问令牌列表
+
+参数：
+  - userID：用户ID
+
+返回：
+  - []models.PersonalAccessTokenInfo：令牌列表
+  - error：错误信息
+*/
+func (service *AuthService) ListPersonalAccessTokens(userID primitive.ObjectID) ([]models.PersonalAccessTokenInfo, error) {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var tokens []models.PersonalAccessTokenInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		tokens, err = service.Storage.AuthStorage.GetPersonalAccessTokens(sessionContext, userID)
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+/*
+RevokePersonalAccessToken 吊销个人访问令牌
+
+参数：
+  - userID：用户ID
+  - tokenID：令牌ID
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) RevokePersonalAccessToken(userID primitive.ObjectID, tokenID string) error {
+	objID, err := primitive.ObjectIDFromHex(tokenID)
+	if err != nil {
+		return types.NewError(types.ErrInvalidParams, "不合法的令牌ID")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		return nil, service.Storage.AuthStorage.DeletePersonalAccessToken(sessionContext, userID, objID)
+	})
+
+	return err
+}
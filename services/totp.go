@@ -0,0 +1,426 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明两步验证相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/mssola/useragent"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/encryptors"
+	"zewise.space/backend/utils/generators"
+	"zewise.space/backend/utils/imagetools"
+)
+
+/*
+SetupTOTP 开始设置两步验证 生成待确认的 TOTP 密钥
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - string：TOTP 密钥
+  - string：otpauth 配置 URI
+  - []byte：PNG 格式的配置二维码
+  - error：错误信息
+*/
+func (service *AuthService) SetupTOTP(userID primitive.ObjectID) (string, string, []byte, error) {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return "", "", nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 获取用户认证信息
+	var authInfo models.UserAuthInfo
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		authInfo, err = service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userID)
+		return nil, err
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+	if authInfo.TOTPEnabled {
+		return "", "", nil, types.NewError(types.ErrInvalidParams, "两步验证已开启")
+	}
+
+	// 生成密钥
+	secret, err := generators.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	uri := generators.GenerateTOTPURI(secret, authInfo.UserName)
+	qrCode, err := imagetools.EncodeQRCode(uri, consts.TOTP_QR_CODE_SIZE)
+	if err != nil {
+		return "", "", nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 保存待确认的密钥
+	err = service.Storage.AuthStorage.SaveTOTPSetup(userID.Hex(), secret)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return secret, uri, qrCode, nil
+}
+
+/*
+ConfirmTOTP 使用验证码确认并开启两步验证
+
+参数：
+  - userID：用户 ID
+  - code：验证码
+
+返回：
+  - []string：恢复码 仅在此时返回一次
+  - error：错误信息
+*/
+func (service *AuthService) ConfirmTOTP(userID primitive.ObjectID, code string) ([]string, error) {
+	// 获取待确认的密钥
+	secret, err := service.Storage.AuthStorage.GetTOTPSetup(userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验验证码
+	err = service.verifyTOTPCode(userID.Hex(), secret, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// 生成恢复码
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启两步验证
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		return nil, service.Storage.AuthStorage.EnableTOTP(sessionContext, userID, secret, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 删除待确认的密钥
+	err = service.Storage.AuthStorage.DeleteTOTPSetup(userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+/*
+DisableTOTP 关闭两步验证 需要提供密码与验证码或恢复码
+
+参数：
+  - userID：用户 ID
+  - password：密码
+  - code：验证码或恢复码
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) DisableTOTP(userID primitive.ObjectID, password string, code string) error {
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var totpVerified bool // 验证码是否已通过 验证码仅可使用一次 事务重试时不再重复校验
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取用户认证信息
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !authInfo.TOTPEnabled {
+			return nil, types.NewError(types.ErrInvalidParams, "两步验证未开启")
+		}
+
+		// 校验密码
+		_, err = encryptors.VerifyPassword(authInfo.PasswordHash, password, authInfo.Salt)
+		if err != nil {
+			return nil, types.NewError(types.ErrInvalidParams, "密码错误")
+		}
+
+		// 校验验证码
+		err = service.verifySecondFactor(sessionContext, authInfo, code, &totpVerified)
+		if err != nil {
+			return nil, err
+		}
+
+		// 关闭两步验证
+		return nil, service.Storage.AuthStorage.DisableTOTP(sessionContext, userID)
+	})
+
+	return err
+}
+
+/*
+RegenerateRecoveryCodes 重新生成恢复码 原有恢复码全部失效
+
+参数：
+  - userID：用户 ID
+  - code：验证码
+
+返回：
+  - []string：新的恢复码
+  - error：错误信息
+*/
+func (service *AuthService) RegenerateRecoveryCodes(userID primitive.ObjectID, code string) ([]string, error) {
+	// 生成恢复码
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var totpVerified bool // 验证码是否已通过 验证码仅可使用一次 事务重试时不再重复校验
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取用户认证信息
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !authInfo.TOTPEnabled {
+			return nil, types.NewError(types.ErrInvalidParams, "两步验证未开启")
+		}
+
+		// 校验验证码 此处不接受恢复码
+		if !totpVerified {
+			err = service.verifyTOTPCode(userID.Hex(), authInfo.TOTPSecret, code)
+			if err != nil {
+				return nil, err
+			}
+			totpVerified = true
+		}
+
+		// 替换恢复码
+		return nil, service.Storage.AuthStorage.ReplaceRecoveryCodes(sessionContext, userID, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+/*
+VerifyLoginChallenge 完成两步验证 验证通过后签发令牌
+
+参数：
+  - challengeToken：登录时返回的挑战令牌
+  - code：验证码或恢复码
+  - ip：IP 地址
+  - userAgent：用户代理
+
+返回：
+  - string：访问令牌
+  - string：刷新令牌
+  - error：错误信息
+*/
+func (service *AuthService) VerifyLoginChallenge(challengeToken string, code string, ip string, userAgent *useragent.UserAgent) (string, string, error) {
+	// 获取挑战对应的用户
+	userID, err := service.Storage.AuthStorage.GetLoginChallenge(challengeToken)
+	if err != nil {
+		return "", "", err
+	}
+	objID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	var token, refreshToken string
+	var codeRejected bool
+	var totpVerified bool // 验证码是否已通过 验证码仅可使用一次 事务重试时不再重复校验
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		// 获取用户认证信息
+		authInfo, err := service.Storage.AuthStorage.GetUserAuthInfoByID(sessionContext, objID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 检查账号是否已停用
+		err = checkAccountSuspension(authInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		// 校验验证码或恢复码
+		err = service.verifySecondFactor(sessionContext, authInfo, code, &totpVerified)
+		if err != nil {
+			codeRejected = true
+			return nil, err
+		}
+
+		// 挑战令牌仅可使用一次
+		ok, err := service.Storage.AuthStorage.DeleteLoginChallenge(challengeToken)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, types.NewError(types.ErrAuthFailed, "两步验证已过期 请重新登录")
+		}
+
+		// 签发令牌
+		token, refreshToken, err = service.issueSession(sessionContext, authInfo, ip, userAgent)
+		return nil, err
+	})
+
+	// 验证码错误时在事务外记录 错误次数过多时作废挑战
+	if codeRejected {
+		recordErr := service.recordFailedLogin(session, objID, ip, userAgent, false)
+		if recordErr != nil {
+			return "", "", recordErr
+		}
+		attempts, recordErr := service.Storage.AuthStorage.RecordChallengeFailure(challengeToken)
+		if recordErr != nil {
+			return "", "", recordErr
+		}
+		if attempts >= consts.TOTP_CHALLENGE_MAX_ATTEMPTS {
+			_, recordErr = service.Storage.AuthStorage.DeleteLoginChallenge(challengeToken)
+			if recordErr != nil {
+				return "", "", recordErr
+			}
+			return "", "", types.NewError(types.ErrAuthFailed, "验证码错误次数过多 请重新登录")
+		}
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+/*
+verifySecondFactor 校验 TOTP 验证码或恢复码 恢复码校验通过后即失效
+TOTP 验证码的使用记录写入 Redis 不随事务回滚 因此通过 totpVerified 保证事务重试时不再重复校验
+恢复码的消耗写入数据库 随事务回滚 事务重试时需重新消耗
+
+参数：
+  - sessionContext：数据库会话上下文
+  - authInfo：用户认证信息
+  - code：验证码或恢复码
+  - totpVerified：TOTP 验证码是否已在之前的事务尝试中通过
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) verifySecondFactor(sessionContext mongo.SessionContext, authInfo models.UserAuthInfo, code string, totpVerified *bool) error {
+	if len(code) == consts.TOTP_DIGITS {
+		if *totpVerified {
+			return nil
+		}
+		err := service.verifyTOTPCode(authInfo.ID.Hex(), authInfo.TOTPSecret, code)
+		if err != nil {
+			return err
+		}
+		*totpVerified = true
+		return nil
+	}
+
+	ok, err := service.Storage.AuthStorage.ConsumeRecoveryCode(
+		sessionContext, authInfo.ID, encryptors.HashToken(generators.NormalizeRecoveryCode(code)),
+	)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return types.NewError(types.ErrInvalidParams, "恢复码无效或已使用")
+	}
+
+	return nil
+}
+
+/*
+verifyTOTPCode 校验 TOTP 验证码 同一验证码仅可使用一次
+
+参数：
+  - userID：用户 ID
+  - secret：TOTP 密钥
+  - code：验证码
+
+返回：
+  - error：错误信息
+*/
+func (service *AuthService) verifyTOTPCode(userID string, secret string, code string) error {
+	step, ok := encryptors.VerifyTOTPCode(secret, code, time.Now())
+	if !ok {
+		return types.NewError(types.ErrInvalidParams, "验证码错误")
+	}
+
+	fresh, err := service.Storage.AuthStorage.MarkTOTPStepUsed(userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return types.NewError(types.ErrInvalidParams, "验证码已使用 请等待下一个验证码")
+	}
+
+	return nil
+}
+
+/*
+generateRecoveryCodes 生成恢复码及其摘要
+
+返回：
+  - []string：恢复码
+  - []string：恢复码摘要
+  - error：错误信息
+*/
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := generators.GenerateRecoveryCodes(consts.RECOVERY_CODE_COUNT)
+	if err != nil {
+		return nil, nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, encryptors.HashToken(generators.NormalizeRecoveryCode(code)))
+	}
+
+	return codes, hashes, nil
+}
@@ -0,0 +1,52 @@
+/*
+Package services - ZeWise 服务层
+该文件用于测试两步验证相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+func TestVerifySecondFactorWithoutStorage(t *testing.T) {
+	// 以下情形均不应访问存储 服务未设置存储对象 访问存储时测试将直接失败
+	service := &AuthService{}
+	authInfo := models.UserAuthInfo{
+		ID:          primitive.NewObjectID(),
+		TOTPSecret:  "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		TOTPEnabled: true,
+	}
+
+	tests := []struct {
+		name             string
+		code             string
+		totpVerified     bool
+		wantErr          error
+		wantTOTPVerified bool
+	}{
+		// 事务重试时 已通过的验证码不再校验 以免被防重放记录拒绝
+		{"verified on earlier attempt", "000000", true, nil, true},
+		{"wrong code", "abcdef", false, types.ErrInvalidParams, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			totpVerified := test.totpVerified
+			err := service.verifySecondFactor(nil, authInfo, test.code, &totpVerified)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("verifySecondFactor() error = %v, want %v", err, test.wantErr)
+			}
+			if totpVerified != test.wantTOTPVerified {
+				t.Fatalf("totpVerified = %v, want %v", totpVerified, test.wantTOTPVerified)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ package services
 import (
 	"bytes"
 	"context"
+	"errors"
 	"mime/multipart"
 	"time"
 
@@ -30,21 +31,43 @@ import (
 
 // UserService 用户服务
 type UserService struct {
-	Storage *stores.Storage
+	Storage      *stores.Storage
+	Registration RegistrationConfig
+	Deletion     AccountDeletionConfig
 }
 
 /*
-RegisterUser 注册用户
+RegisterUser 注册用户 仅限邀请注册时必须提供邀请码 开放注册时提供的邀请码同样会被使用并记录邀请人
 
 参数：
   - username：用户名
   - email：邮箱
   - password：密码
+  - inviteCode：邀请码
+  - captchaID：图片验证码ID
+  - captchaAnswer：图片验证码答案
 
 返回：
   - error：错误信息
 */
-func (service *UserService) RegisterUser(username string, email string, password string) error {
+func (service *UserService) RegisterUser(username string, email string, password string, inviteCode string, captchaID string, captchaAnswer string) error {
+	// 检查注册模式
+	inviteCode = generators.NormalizeInviteCode(inviteCode)
+	switch service.Registration.Mode {
+	case consts.REGISTRATION_MODE_CLOSED:
+		return types.NewError(types.ErrInvalidParams, "暂未开放注册")
+	case consts.REGISTRATION_MODE_INVITE:
+		if inviteCode == "" {
+			return types.NewError(types.ErrInvalidParams, "当前仅限邀请注册 需要提供邀请码")
+		}
+	}
+
+	// 校验图片验证码
+	err := verifyCaptcha(service.Storage, captchaID, captchaAnswer)
+	if err != nil {
+		return err
+	}
+
 	// 验证用户名 密码 邮箱是否合法
 	if !validers.IsValidEmail(email) {
 		return types.NewError(types.ErrInvalidParams, "不合法的邮箱")
@@ -71,22 +94,38 @@ func (service *UserService) RegisterUser(username string, email string, password
 		if err != nil {
 			return nil, err
 		}
-
-		// 生成盐和哈希密码
-		salt, err := generators.GenerateSalt(consts.SALT_LENGTH)
+		reserved, err := service.Storage.UserStorage.IsUsernameReserved(sessionContext, username, primitive.NilObjectID, time.Now())
 		if err != nil {
 			return nil, err
 		}
-		hashedPassword, err := encryptors.HashPassword(password, salt)
+		if reserved {
+			return nil, types.NewError(types.ErrInvalidParams, "用户名或邮箱已被注册")
+		}
+
+		// 使用邀请码 与注册处于同一事务 注册失败时不消耗次数
+		var invite models.InviteCode
+		if inviteCode != "" {
+			invite, err = service.Storage.UserStorage.ConsumeInviteCode(sessionContext, inviteCode, time.Now())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// 生成哈希密码
+		hashedPassword, err := encryptors.HashPassword(password)
 		if err != nil {
 			return nil, err
 		}
 
 		// 注册用户
-		err = service.Storage.UserStorage.RegisterUser(sessionContext, username, email, salt, hashedPassword)
+		err = service.Storage.UserStorage.RegisterUser(sessionContext, username, email, hashedPassword, invite)
 		return nil, err
 	})
 
+	var typedErr types.Error
+	if errors.As(err, &typedErr) {
+		return err
+	}
 	if err != nil {
 		return types.NewError(types.ErrServerError, err.Error())
 	}
@@ -130,12 +169,15 @@ func (service *UserService) GetUserProfileByID(userID string) (models.UserInfo,
 	if err != nil {
 		return userInfo, err
 	}
+	if userInfo.Deactivated {
+		return models.UserInfo{}, types.NewError(types.ErrInvalidParams, "用户不存在")
+	}
 
 	return userInfo, nil
 }
 
 /*
-GetUserProfileByUsername 获取用户信息
+GetUserProfileByUsername 获取用户信息 用户名不存在时按近期修改记录跳转至改名后的用户
 
 参数：
   - username：用户名
@@ -159,11 +201,27 @@ func (service *UserService) GetUserProfileByUsername(username string) (models.Us
 	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
 		// 获取用户信息
 		userInfo, err = service.Storage.UserStorage.GetUserDataByUsername(sessionContext, username)
+		if !errors.Is(err, types.ErrInvalidParams) {
+			return nil, err
+		}
+
+		// 查找旧用户名的跳转
+		userID, found, redirectErr := service.Storage.UserStorage.GetUsernameRedirect(sessionContext, username, time.Now())
+		if redirectErr != nil {
+			return nil, redirectErr
+		}
+		if !found {
+			return nil, err
+		}
+		userInfo, err = service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
 		return nil, err
 	})
 	if err != nil {
 		return userInfo, err
 	}
+	if userInfo.Deactivated {
+		return models.UserInfo{}, types.NewError(types.ErrInvalidParams, "用户不存在")
+	}
 
 	return userInfo, nil
 }
@@ -318,13 +376,13 @@ func (service *UserService) UpdateUserPassword(userID primitive.ObjectID, oldPas
 		}
 
 		// 验证密码
-		err = encryptors.CompareHashPassword(authInfo.PasswordHash, oldPassword, authInfo.Salt)
+		_, err = encryptors.VerifyPassword(authInfo.PasswordHash, oldPassword, authInfo.Salt)
 		if err != nil {
 			return nil, types.NewError(types.ErrInvalidParams, "旧密码错误")
 		}
 
 		// 生成新哈希密码
-		hashedPassword, err := encryptors.HashPassword(newPassword, authInfo.Salt)
+		hashedPassword, err := encryptors.HashPassword(newPassword)
 		if err != nil {
 			return nil, err
 		}
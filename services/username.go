@@ -0,0 +1,97 @@
+/*
+Package services - ZeWise 服务层
+该文件用于声明修改用户名相关服务
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/validers"
+)
+
+/*
+ChangeUsername 修改用户名 旧用户名在一段时间内仍跳转至本账号 并在更长的时间内保留不可被他人使用
+
+参数：
+  - userID：用户ID
+  - username：新用户名
+
+返回：
+  - error：错误信息
+*/
+func (service *UserService) ChangeUsername(userID primitive.ObjectID, username string) error {
+	if !validers.IsValidUsername(username) {
+		return types.NewError(types.ErrInvalidParams, "不合法的用户名")
+	}
+
+	// 创建数据库会话
+	ctx := context.Background()
+	session, err := service.Storage.NewSession()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	defer session.EndSession(ctx)
+
+	// 开启事务
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (any, error) {
+		now := time.Now()
+
+		// 获取用户信息并检查修改间隔
+		userInfo, err := service.Storage.UserStorage.GetUserDataByID(sessionContext, userID)
+		if err != nil {
+			return nil, err
+		}
+		if userInfo.UserName == username {
+			return nil, types.NewError(types.ErrInvalidParams, "新用户名与当前用户名相同")
+		}
+		nextChangeAt := userInfo.UsernameChangedAt.AddDate(0, 0, consts.USERNAME_CHANGE_COOLDOWN_DAYS)
+		if !userInfo.UsernameChangedAt.IsZero() && now.Before(nextChangeAt) {
+			return nil, types.NewError(types.ErrInvalidParams, "修改用户名过于频繁 下次可修改时间为 "+nextChangeAt.Format(time.DateTime))
+		}
+
+		// 检查新用户名是否为他人保留中的旧用户名 是否被占用由唯一索引保证
+		reserved, err := service.Storage.UserStorage.IsUsernameReserved(sessionContext, username, userID, now)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, types.NewError(types.ErrInvalidParams, "用户名已被占用")
+		}
+
+		// 修改用户名并记录
+		err = service.Storage.UserStorage.ChangeUsername(sessionContext, userID, username, now)
+		if err != nil {
+			return nil, err
+		}
+		err = service.Storage.UserStorage.CreateUsernameHistory(sessionContext, models.UsernameHistory{
+			UID:           userID,
+			OldName:       userInfo.UserName,
+			NewName:       username,
+			ChangedAt:     now,
+			RedirectUntil: now.AddDate(0, 0, consts.USERNAME_REDIRECT_DAYS),
+			ReservedUntil: now.AddDate(0, 0, consts.USERNAME_RESERVE_DAYS),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// 同步评论与回复中记录的用户名
+		err = service.Storage.CommentStorage.RenameUserComments(sessionContext, userID, username)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.Storage.ReplyStorage.RenameUserReplies(sessionContext, userID, username)
+	})
+
+	return err
+}
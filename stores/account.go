@@ -0,0 +1,291 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现账号注销相关存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+/*
+ScheduleAccountDeletion 停用账号并计划在冷静期结束后清除其数据
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - purgeAt：计划清除时间
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) ScheduleAccountDeletion(sessionContext mongo.SessionContext, userID primitive.ObjectID, purgeAt time.Time) error {
+	_, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"deletion_scheduled_at": purgeAt}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	_, err = store.mongo.Collection(models.USER_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"deactivated": true}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+CancelAccountDeletion 撤销注销申请并恢复账号
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) CancelAccountDeletion(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$unset": bson.M{"deletion_scheduled_at": "", "purge_failures": "", "purge_retry_at": ""}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	_, err = store.mongo.Collection(models.USER_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$unset": bson.M{"deactivated": ""}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetAccountsDueForPurge 获取冷静期已结束的注销账号 按计划清除时间排序 跳过清除失败后尚未到重试时间的账号
+
+参数：
+  - sessionContext：数据库会话上下文
+  - now：当前时间
+  - limit：数量
+
+返回：
+  - []primitive.ObjectID：用户ID列表
+  - error：错误信息
+*/
+func (store *UserStorage) GetAccountsDueForPurge(sessionContext mongo.SessionContext, now time.Time, limit int64) ([]primitive.ObjectID, error) {
+	result, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).Find(
+		sessionContext,
+		bson.M{
+			"deletion_scheduled_at": bson.M{"$lte": now},
+			"$or": bson.A{
+				bson.M{"purge_retry_at": bson.M{"$exists": false}},
+				bson.M{"purge_retry_at": bson.M{"$lte": now}},
+			},
+		},
+		options.Find().
+			SetProjection(bson.M{"_id": 1}).
+			SetSort(bson.D{{Key: "deletion_scheduled_at", Value: 1}}).
+			SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	accounts := []models.UserAuthInfo{}
+	err = result.All(sessionContext, &accounts)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(accounts))
+	for _, account := range accounts {
+		userIDs = append(userIDs, account.ID)
+	}
+
+	return userIDs, nil
+}
+
+/*
+RecordPurgeFailure 记录账号清除失败 并按连续失败次数推迟下次重试的时间
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - now：当前时间
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) RecordPurgeFailure(sessionContext mongo.SessionContext, userID primitive.ObjectID, now time.Time) error {
+	collection := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION)
+
+	// 增加失败次数
+	account := models.UserAuthInfo{}
+	err := collection.FindOneAndUpdate(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$inc": bson.M{"purge_failures": 1}},
+		options.FindOneAndUpdate().SetProjection(bson.M{"purge_failures": 1}).SetReturnDocument(options.After),
+	).Decode(&account)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	// 按失败次数指数退避
+	interval := consts.ACCOUNT_PURGE_RETRY_INTERVAL * time.Second
+	for i := 1; i < account.PurgeFailures && interval < consts.ACCOUNT_PURGE_RETRY_MAX_INTERVAL*time.Second; i++ {
+		interval *= 2
+	}
+	interval = min(interval, consts.ACCOUNT_PURGE_RETRY_MAX_INTERVAL*time.Second)
+	_, err = collection.UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"purge_retry_at": now.Add(interval)}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DeleteUserAccount 删除用户信息、认证信息、用户名修改记录与其生成的邀请码
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) DeleteUserAccount(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.USER_INFO_COLLECTION).DeleteOne(sessionContext, bson.M{"_id": userID})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	_, err = store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).DeleteOne(sessionContext, bson.M{"_id": userID})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	_, err = store.mongo.Collection(models.INVITE_CODE_COLLECTION).DeleteMany(sessionContext, bson.M{"creator_id": userID})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	_, err = store.mongo.Collection(models.USERNAME_HISTORY_COLLECTION).DeleteMany(sessionContext, bson.M{"uid": userID})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DecreaseFollowCounts 注销用户的关注关系解除后 减少相关用户的关注数与粉丝数
+
+参数：
+  - sessionContext：数据库会话上下文
+  - followerIDs：注销用户的粉丝ID列表 其关注数减一
+  - followeeIDs：注销用户关注的用户ID列表 其粉丝数减一
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) DecreaseFollowCounts(sessionContext mongo.SessionContext, followerIDs []primitive.ObjectID, followeeIDs []primitive.ObjectID) error {
+	collection := store.mongo.Collection(models.USER_INFO_COLLECTION)
+
+	if len(followerIDs) > 0 {
+		_, err := collection.UpdateMany(sessionContext, bson.M{"_id": bson.M{"$in": followerIDs}}, bson.M{"$inc": bson.M{"following_count": -1}})
+		if err != nil {
+			return types.NewError(types.ErrServerError, err.Error())
+		}
+	}
+	if len(followeeIDs) > 0 {
+		_, err := collection.UpdateMany(sessionContext, bson.M{"_id": bson.M{"$in": followeeIDs}}, bson.M{"$inc": bson.M{"follower_count": -1}})
+		if err != nil {
+			return types.NewError(types.ErrServerError, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// releasePurgeLockScript 仅当清除任务锁仍由当前持有者持有时释放
+//
+// KEYS[1]：任务锁键 ARGV[1]：持有者令牌
+var releasePurgeLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+/*
+AcquirePurgeLock 获取注销账号清除任务锁
+
+参数：
+  - token：持有者令牌
+
+返回：
+  - bool：是否获取成功
+  - error：错误信息
+*/
+func (store *UserStorage) AcquirePurgeLock(token string) (bool, error) {
+	ok, err := store.redis.SetNX(
+		context.Background(), models.REDIS_ACCOUNT_PURGE_LOCK, token, consts.ACCOUNT_PURGE_LOCK_DURATION*time.Second,
+	).Result()
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return ok, nil
+}
+
+/*
+ReleasePurgeLock 释放注销账号清除任务锁 任务锁已过期并被其他实例获取时不做修改
+
+参数：
+  - token：持有者令牌
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) ReleasePurgeLock(token string) error {
+	err := releasePurgeLockScript.Run(
+		context.Background(), store.redis, []string{models.REDIS_ACCOUNT_PURGE_LOCK}, token,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
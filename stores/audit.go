@@ -0,0 +1,89 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明审计日志存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+// AuditStorage 审计日志数据库
+type AuditStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+}
+
+/*
+RecordAuditLog 记录管理操作审计日志
+
+参数：
+  - sessionContext：数据库会话上下文
+  - auditLog：审计日志 操作时间为空时使用当前时间
+
+返回：
+  - error：错误信息
+*/
+func (store *AuditStorage) RecordAuditLog(sessionContext mongo.SessionContext, auditLog models.AuditLog) error {
+	if auditLog.Time.IsZero() {
+		auditLog.Time = time.Now()
+	}
+
+	_, err := store.mongo.Collection(models.AUDIT_LOG_COLLECTION).InsertOne(sessionContext, auditLog)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetAuditLogs 分页获取审计日志 按时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - targetID：被操作用户ID 为空时获取全部日志
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.AuditLog：审计日志列表
+  - error：错误信息
+*/
+func (store *AuditStorage) GetAuditLogs(sessionContext mongo.SessionContext, targetID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.AuditLog, error) {
+	filter := bson.M{}
+	if !targetID.IsZero() {
+		filter["target_id"] = targetID
+	}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
+
+	result, err := store.mongo.Collection(models.AUDIT_LOG_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	logs := []models.AuditLog{}
+	err = result.All(sessionContext, &logs)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return logs, nil
+}
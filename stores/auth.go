@@ -9,10 +9,14 @@ package stores
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"zewise.space/backend/consts"
 	"zewise.space/backend/models"
 	"zewise.space/backend/types"
 	"zewise.space/backend/utils/functools"
@@ -99,200 +103,238 @@ func (store *AuthStorage) GetUserAuthInfoByUsername(sessionContext mongo.Session
 }
 
 /*
-CheckTokenAvailability 检查令牌是否可用
+RecordLoginEvent 记录登录事件
 
 参数：
-  - token：令牌
+  - userID：用户 ID
+  - ip：IP 地址
+  - Location：位置
+  - Device：设备
+  - Application：应用
+  - succeed：是否登录成功
+  - locked：是否因账号锁定被拒绝或触发锁定
 
 返回：
-  - bool：令牌是否可用
   - error：错误信息
 */
-func (store *AuthStorage) CheckTokenAvailability(userID string, token string) (bool, error) {
-	// 获取用户令牌列表
-	list, err := store.GetAvailableToken(userID)
-	if err != nil {
-		return false, types.NewError(types.ErrServerError, err.Error())
-	}
-	if len(list) == 0 {
-		return false, nil
+func (store *AuthStorage) RecordLoginEvent(sessionContext mongo.SessionContext, userID primitive.ObjectID, ip string, location string, device string, application string, succeed bool, locked bool) error {
+	// 创建登录日志
+	loginLog := models.UserLoginLog{
+		UID:         userID,
+		IP:          ip,
+		Location:    location,
+		Device:      device,
+		Time:        time.Now(),
+		Application: application,
+		IfSucceed:   succeed,
+		IfChecked:   false,
+		IfLocked:    locked,
 	}
 
-	// 检查令牌是否在列表中
-	for _, t := range list {
-		if t == token {
-			return true, nil
-		}
+	// 保存登录日志
+	_, err := store.mongo.Collection(models.USER_LOGIN_LOGS_COLLECTION).InsertOne(sessionContext, loginLog)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
 	}
 
-	return false, nil
+	return nil
 }
 
 /*
-GetAvailableToken 获取可用令牌
+SaveMailVerifyToken 保存邮箱验证令牌
 
 参数：
+  - token：验证令牌
   - userID：用户 ID
+  - email：待验证的邮箱
 
 返回：
-  - []token：令牌列表
   - error：错误信息
 */
-func (store *AuthStorage) GetAvailableToken(userID string) ([]string, error) {
+func (store *AuthStorage) SaveMailVerifyToken(token string, userID string, email string) error {
 	ctx := context.Background()
 
-	// 获取用户令牌列表
-	list, err := store.redis.LRange(
-		ctx, functools.JoinStrings(models.REDIS_AVAILABLE_USER_TOKEN_LIST, ":", userID), 0, -1,
-	).Result()
+	// 保存令牌
+	err := store.redis.Set(
+		ctx,
+		functools.JoinStrings(models.REDIS_MAIL_VERIFY_TOKEN, ":", token),
+		functools.JoinStrings(userID, ":", email),
+		consts.MAIL_VERIFY_TOKEN_EXPIRE_DURATION*time.Second,
+	).Err()
 	if err != nil {
-		return nil, types.NewError(types.ErrServerError, err.Error())
+		return types.NewError(types.ErrServerError, err.Error())
 	}
 
-	return list, nil
+	return nil
 }
 
 /*
-RemoveEarliestToken 移除最早的令牌
+ConsumeMailVerifyToken 读取并删除邮箱验证令牌 令牌仅可使用一次
 
 参数：
-  - userID：用户 ID
-  - count：移除数量
+  - token：验证令牌
 
 返回：
+  - string：用户 ID
+  - string：待验证的邮箱
   - error：错误信息
 */
-func (store *AuthStorage) RemoveEarliestToken(userID string, count int) error {
+func (store *AuthStorage) ConsumeMailVerifyToken(token string) (string, string, error) {
 	ctx := context.Background()
 
-	// 移除最早的令牌
-	_, err := store.redis.LTrim(
-		ctx, functools.JoinStrings(models.REDIS_AVAILABLE_USER_TOKEN_LIST, ":", userID), int64(count), -1,
-	).Result()
+	// 读取并删除令牌
+	value, err := store.redis.GetDel(ctx, functools.JoinStrings(models.REDIS_MAIL_VERIFY_TOKEN, ":", token)).Result()
 	if err != nil {
-		return types.NewError(types.ErrServerError, err.Error())
+		if errors.Is(err, redis.Nil) {
+			return "", "", types.NewError(types.ErrInvalidParams, "验证令牌无效或已过期")
+		}
+		return "", "", types.NewError(types.ErrServerError, err.Error())
 	}
 
-	return nil
+	userID, email, found := strings.Cut(value, ":")
+	if !found {
+		return "", "", types.NewError(types.ErrServerError, "验证令牌数据损坏")
+	}
+
+	return userID, email, nil
 }
 
 /*
-SaveToken 保存令牌
+AcquireMailSendLock 获取邮件发送锁 在发送间隔内重复获取将失败
 
 参数：
   - userID：用户 ID
-  - token：令牌
+  - purpose：邮件用途
 
 返回：
+  - bool：是否获取成功
   - error：错误信息
 */
-func (store *AuthStorage) SaveToken(userID string, token string) error {
+func (store *AuthStorage) AcquireMailSendLock(userID string, purpose string) (bool, error) {
 	ctx := context.Background()
 
-	// 保存令牌
-	_, err := store.redis.RPush(
-		ctx, functools.JoinStrings(models.REDIS_AVAILABLE_USER_TOKEN_LIST, ":", userID), token,
+	// 设置发送锁
+	ok, err := store.redis.SetNX(
+		ctx,
+		functools.JoinStrings(models.REDIS_MAIL_SEND_LOCK, ":", purpose, ":", userID),
+		1,
+		consts.MAIL_SEND_INTERVAL*time.Second,
 	).Result()
 	if err != nil {
-		return types.NewError(types.ErrServerError, err.Error())
+		return false, types.NewError(types.ErrServerError, err.Error())
 	}
 
-	return nil
+	return ok, nil
 }
 
 /*
-UpdateToken 更新令牌
+SavePasswordResetToken 保存密码重置令牌
 
 参数：
+  - token：重置令牌
   - userID：用户 ID
-  - token：令牌
+  - email：接收重置邮件的邮箱
 
 返回：
   - error：错误信息
 */
-func (store *AuthStorage) UpdateToken(userID string, newToken string, oldToken string) error {
+func (store *AuthStorage) SavePasswordResetToken(token string, userID string, email string) error {
 	ctx := context.Background()
 
-	// 查找旧令牌索引
-	tokens, err := store.GetAvailableToken(userID)
+	// 保存令牌
+	err := store.redis.Set(
+		ctx,
+		functools.JoinStrings(models.REDIS_PASSWORD_RESET_TOKEN, ":", token),
+		functools.JoinStrings(userID, ":", email),
+		consts.PASSWORD_RESET_TOKEN_EXPIRE_DURATION*time.Second,
+	).Err()
 	if err != nil {
 		return types.NewError(types.ErrServerError, err.Error())
 	}
 
-	index := -1
-	for i, t := range tokens {
-		if t == oldToken {
-			index = i
-			break
+	return nil
+}
+
+/*
+ConsumePasswordResetToken 读取并删除密码重置令牌 令牌仅可使用一次
+
+参数：
+  - token：重置令牌
+
+返回：
+  - string：用户 ID
+  - string：接收重置邮件的邮箱
+  - error：错误信息
+*/
+func (store *AuthStorage) ConsumePasswordResetToken(token string) (string, string, error) {
+	ctx := context.Background()
+
+	// 读取并删除令牌
+	value, err := store.redis.GetDel(ctx, functools.JoinStrings(models.REDIS_PASSWORD_RESET_TOKEN, ":", token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", "", types.NewError(types.ErrInvalidParams, "重置令牌无效或已过期")
 		}
-	}
-	if index == -1 {
-		return types.NewError(types.ErrServerError, "未找到旧令牌")
+		return "", "", types.NewError(types.ErrServerError, err.Error())
 	}
 
-	// 更新令牌
-	_, err = store.redis.LSet(
-		ctx, functools.JoinStrings(models.REDIS_AVAILABLE_USER_TOKEN_LIST, ":", userID), int64(index), newToken,
-	).Result()
-	if err != nil {
-		return types.NewError(types.ErrServerError, err.Error())
+	userID, email, found := strings.Cut(value, ":")
+	if !found {
+		return "", "", types.NewError(types.ErrInvalidParams, "重置令牌无效或已过期")
 	}
 
-	return nil
+	return userID, email, nil
 }
 
 /*
-RmoveToken 移除令牌
+GetLoginLogs 分页获取用户的登录日志 按登录时间倒序
 
 参数：
+  - sessionContext：数据库会话上下文
   - userID：用户 ID
-  - token：令牌
+  - cursor：游标 即上一页最后一条日志的ID 为空时从头开始
+  - limit：数量
 
 返回：
+  - []models.UserLoginLog：登录日志列表
   - error：错误信息
 */
-func (store *AuthStorage) RmoveToken(userID string, token string) error {
-	ctx := context.Background()
+func (store *AuthStorage) GetLoginLogs(sessionContext mongo.SessionContext, userID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.UserLoginLog, error) {
+	filter := bson.M{"uid": userID}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
 
-	// 移除令牌
-	_, err := store.redis.LRem(
-		ctx, functools.JoinStrings(models.REDIS_AVAILABLE_USER_TOKEN_LIST, ":", userID), 0, token,
-	).Result()
+	result, err := store.mongo.Collection(models.USER_LOGIN_LOGS_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit),
+	)
 	if err != nil {
-		return types.NewError(types.ErrServerError, err.Error())
+		return nil, types.NewError(types.ErrServerError, err.Error())
 	}
 
-	return nil
+	logs := []models.UserLoginLog{}
+	err = result.All(sessionContext, &logs)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return logs, nil
 }
 
 /*
-RecordLoginEvent 记录登录事件
+DeleteLoginLogs 删除用户的全部登录日志
 
 参数：
+  - sessionContext：数据库会话上下文
   - userID：用户 ID
-  - ip：IP 地址
-  - Location：位置
-  - Device：设备
-  - Application：应用
 
 返回：
   - error：错误信息
 */
-func (store *AuthStorage) RecordLoginEvent(sessionContext mongo.SessionContext, userID primitive.ObjectID, ip string, location string, device string, application string) error {
-	// 创建登录日志
-	loginLog := models.UserLoginLog{
-		UID:         userID,
-		IP:          ip,
-		Location:    location,
-		Device:      device,
-		Time:        time.Now(),
-		Application: application,
-		IfSucceed:   false,
-		IfChecked:   false,
-	}
-
-	// 保存登录日志
-	_, err := store.mongo.Collection(models.USER_LOGIN_LOGS_COLLECTION).InsertOne(sessionContext, loginLog)
+func (store *AuthStorage) DeleteLoginLogs(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.USER_LOGIN_LOGS_COLLECTION).DeleteMany(sessionContext, bson.M{"uid": userID})
 	if err != nil {
 		return types.NewError(types.ErrServerError, err.Error())
 	}
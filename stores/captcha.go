@@ -0,0 +1,68 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现图片验证码存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+/*
+SaveCaptcha 保存图片验证码答案
+
+参数：
+  - captchaID：验证码ID
+  - answer：验证码答案
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SaveCaptcha(captchaID string, answer string) error {
+	err := store.redis.Set(
+		context.Background(),
+		functools.JoinStrings(models.REDIS_CAPTCHA, ":", captchaID),
+		answer,
+		consts.CAPTCHA_EXPIRE_DURATION*time.Second,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+ConsumeCaptcha 读取并删除图片验证码答案 无论作答是否正确 验证码均只能使用一次
+
+参数：
+  - captchaID：验证码ID
+
+返回：
+  - string：验证码答案
+  - error：错误信息
+*/
+func (store *AuthStorage) ConsumeCaptcha(captchaID string) (string, error) {
+	answer, err := store.redis.GetDel(
+		context.Background(), functools.JoinStrings(models.REDIS_CAPTCHA, ":", captchaID),
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", types.NewError(types.ErrCaptchaRequired, "验证码无效或已过期")
+		}
+		return "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return answer, nil
+}
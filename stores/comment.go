@@ -0,0 +1,268 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明评论存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+// CommentStorage 评论信息数据库
+type CommentStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+}
+
+/*
+CreateComment 创建评论
+
+参数：
+  - sessionContext：数据库会话上下文
+  - commentInfo：评论信息
+
+返回：
+  - primitive.ObjectID：评论ID
+  - error：错误信息
+*/
+func (store *CommentStorage) CreateComment(sessionContext mongo.SessionContext, commentInfo models.CommentInfo) (primitive.ObjectID, error) {
+	commentInfo.CreatedAt = time.Now()
+	commentInfo.ReplyCount = 0
+
+	result, err := store.mongo.Collection(models.COMMENT_COLLECTION).InsertOne(sessionContext, commentInfo)
+	if err != nil {
+		return primitive.NilObjectID, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+/*
+GetCommentByID 通过评论ID获取评论 已删除的评论视为不存在
+
+参数：
+  - sessionContext：数据库会话上下文
+  - commentID：评论ID
+
+返回：
+  - models.CommentInfo：评论信息
+  - error：错误信息
+*/
+func (store *CommentStorage) GetCommentByID(sessionContext mongo.SessionContext, commentID primitive.ObjectID) (models.CommentInfo, error) {
+	var commentInfo models.CommentInfo
+	err := store.mongo.Collection(models.COMMENT_COLLECTION).FindOne(sessionContext, bson.M{
+		"_id":        commentID,
+		"is_deleted": bson.M{"$ne": true},
+	}).Decode(&commentInfo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return commentInfo, types.NewError(types.ErrInvalidParams, "评论不存在")
+		}
+		return commentInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return commentInfo, nil
+}
+
+/*
+GetCommentsByPost 分页获取博文的评论列表 按发布时间正序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postID：博文ID
+  - cursor：游标 即上一页最后一条评论的ID 为空时从头开始
+  - limit：数量
+
+返回：
+  - []models.CommentInfo：评论列表
+  - error：错误信息
+*/
+func (store *CommentStorage) GetCommentsByPost(sessionContext mongo.SessionContext, postID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.CommentInfo, error) {
+	filter := bson.M{
+		"post_id":    postID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$gt": cursor}
+	}
+
+	result, err := store.mongo.Collection(models.COMMENT_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	comments := []models.CommentInfo{}
+	err = result.All(sessionContext, &comments)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return comments, nil
+}
+
+/*
+IncreaseReplyCount 增加评论的回复总数
+
+参数：
+  - sessionContext：数据库会话上下文
+  - commentID：评论ID
+  - delta：增量
+
+返回：
+  - error：错误信息
+*/
+func (store *CommentStorage) IncreaseReplyCount(sessionContext mongo.SessionContext, commentID primitive.ObjectID, delta int64) error {
+	_, err := store.mongo.Collection(models.COMMENT_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": commentID},
+		bson.M{"$inc": bson.M{"reply_count": delta}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DeleteComment 删除评论 仅做标记删除
+
+参数：
+  - sessionContext：数据库会话上下文
+  - commentID：评论ID
+
+返回：
+  - error：错误信息
+*/
+func (store *CommentStorage) DeleteComment(sessionContext mongo.SessionContext, commentID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.COMMENT_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": commentID},
+		bson.M{"$set": bson.M{"is_deleted": true}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetCommentsByUser 获取用户发表的全部未删除评论 按发表时间正序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - []models.CommentInfo：评论列表
+  - error：错误信息
+*/
+func (store *CommentStorage) GetCommentsByUser(sessionContext mongo.SessionContext, userID primitive.ObjectID) ([]models.CommentInfo, error) {
+	result, err := store.mongo.Collection(models.COMMENT_COLLECTION).Find(
+		sessionContext,
+		bson.M{"uid": userID, "is_deleted": bson.M{"$ne": true}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	comments := []models.CommentInfo{}
+	err = result.All(sessionContext, &comments)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return comments, nil
+}
+
+/*
+RenameUserComments 用户修改用户名后 同步其评论中记录的用户名
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - username：新用户名
+
+返回：
+  - error：错误信息
+*/
+func (store *CommentStorage) RenameUserComments(sessionContext mongo.SessionContext, userID primitive.ObjectID, username string) error {
+	_, err := store.mongo.Collection(models.COMMENT_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID, "is_deleted": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"username": username}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+AnonymizeUserComments 将注销用户评论中的用户名替换为匿名名称 评论内容保留
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *CommentStorage) AnonymizeUserComments(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.COMMENT_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID},
+		bson.M{"$set": bson.M{"username": consts.DELETED_USER_NAME}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+RemoveUserComments 删除注销用户的全部评论 标记删除并清空内容 以保留回复的引用关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *CommentStorage) RemoveUserComments(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.COMMENT_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID},
+		bson.M{
+			"$set":   bson.M{"is_deleted": true, "username": consts.DELETED_USER_NAME},
+			"$unset": bson.M{"content": ""},
+		},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
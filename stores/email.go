@@ -0,0 +1,139 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现修改邮箱相关存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+/*
+SaveEmailChangeToken 保存修改邮箱确认令牌
+
+参数：
+  - token：确认令牌
+  - userID：用户 ID
+  - email：新邮箱
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SaveEmailChangeToken(token string, userID string, email string) error {
+	err := store.redis.Set(
+		context.Background(),
+		functools.JoinStrings(models.REDIS_EMAIL_CHANGE_TOKEN, ":", token),
+		functools.JoinStrings(userID, ":", email),
+		consts.EMAIL_CHANGE_TOKEN_EXPIRE_DURATION*time.Second,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+ConsumeEmailChangeToken 读取并删除修改邮箱确认令牌 令牌仅可使用一次
+
+参数：
+  - token：确认令牌
+
+返回：
+  - string：用户 ID
+  - string：新邮箱
+  - error：错误信息
+*/
+func (store *AuthStorage) ConsumeEmailChangeToken(token string) (string, string, error) {
+	value, err := store.redis.GetDel(context.Background(), functools.JoinStrings(models.REDIS_EMAIL_CHANGE_TOKEN, ":", token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", "", types.NewError(types.ErrInvalidParams, "确认令牌无效或已过期")
+		}
+		return "", "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	userID, email, found := strings.Cut(value, ":")
+	if !found {
+		return "", "", types.NewError(types.ErrServerError, "确认令牌数据损坏")
+	}
+
+	return userID, email, nil
+}
+
+/*
+IsEmailRegistered 检查邮箱是否已被注册
+
+参数：
+  - sessionContext：数据库会话上下文
+  - email：邮箱
+
+返回：
+  - bool：是否已被注册
+  - error：错误信息
+*/
+func (store *UserStorage) IsEmailRegistered(sessionContext mongo.SessionContext, email string) (bool, error) {
+	count, err := store.mongo.Collection(models.USER_INFO_COLLECTION).CountDocuments(sessionContext, bson.M{"email": email})
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count > 0, nil
+}
+
+/*
+ChangeEmail 同时修改用户信息与认证信息中的邮箱 新邮箱已通过确认令牌验证
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - email：新邮箱
+
+返回：
+  - error：错误信息 邮箱已被注册时返回 types.ErrInvalidParams 错误
+*/
+func (store *UserStorage) ChangeEmail(sessionContext mongo.SessionContext, userID primitive.ObjectID, email string) error {
+	result, err := store.mongo.Collection(models.USER_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"email": email, "email_verified": true}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.NewError(types.ErrInvalidParams, "邮箱已被注册")
+	}
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	if result.MatchedCount == 0 {
+		return types.NewError(types.ErrInvalidParams, "用户不存在")
+	}
+
+	_, err = store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"email": email}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.NewError(types.ErrInvalidParams, "邮箱已被注册")
+	}
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
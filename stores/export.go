@@ -0,0 +1,234 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明个人数据导出存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+// ExportStorage 个人数据导出数据库
+type ExportStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+	minio *minio.Client
+}
+
+/*
+CreateDataExport 创建导出记录
+
+参数：
+  - sessionContext：数据库会话上下文
+  - export：导出记录
+
+返回：
+  - primitive.ObjectID：导出记录ID
+  - error：错误信息
+*/
+func (store *ExportStorage) CreateDataExport(sessionContext mongo.SessionContext, export models.DataExport) (primitive.ObjectID, error) {
+	result, err := store.mongo.Collection(models.DATA_EXPORT_COLLECTION).InsertOne(sessionContext, export)
+	if err != nil {
+		return primitive.NilObjectID, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+/*
+GetLatestDataExport 获取用户最近一次导出记录
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - models.DataExport：导出记录
+  - bool：是否存在
+  - error：错误信息
+*/
+func (store *ExportStorage) GetLatestDataExport(sessionContext mongo.SessionContext, userID primitive.ObjectID) (models.DataExport, bool, error) {
+	var export models.DataExport
+	err := store.mongo.Collection(models.DATA_EXPORT_COLLECTION).FindOne(
+		sessionContext,
+		bson.M{"uid": userID},
+		options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}}),
+	).Decode(&export)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return export, false, nil
+	}
+	if err != nil {
+		return export, false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return export, true, nil
+}
+
+/*
+CompleteDataExport 将导出记录标记为已生成
+
+参数：
+  - sessionContext：数据库会话上下文
+  - exportID：导出记录ID
+  - objectName：归档对象名
+  - size：归档大小
+  - completedAt：生成完成时间
+  - expiresAt：过期时间
+
+返回：
+  - error：错误信息
+*/
+func (store *ExportStorage) CompleteDataExport(sessionContext mongo.SessionContext, exportID primitive.ObjectID, objectName string, size int64, completedAt time.Time, expiresAt time.Time) error {
+	_, err := store.mongo.Collection(models.DATA_EXPORT_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": exportID},
+		bson.M{"$set": bson.M{
+			"status":       consts.DATA_EXPORT_STATUS_READY,
+			"object_name":  objectName,
+			"size":         size,
+			"completed_at": completedAt,
+			"expires_at":   expiresAt,
+		}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+FailDataExport 将导出记录标记为生成失败
+
+参数：
+  - sessionContext：数据库会话上下文
+  - exportID：导出记录ID
+  - reason：失败原因
+
+返回：
+  - error：错误信息
+*/
+func (store *ExportStorage) FailDataExport(sessionContext mongo.SessionContext, exportID primitive.ObjectID, reason string) error {
+	_, err := store.mongo.Collection(models.DATA_EXPORT_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": exportID},
+		bson.M{"$set": bson.M{
+			"status":      consts.DATA_EXPORT_STATUS_FAILED,
+			"fail_reason": reason,
+		}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DeleteDataExports 删除用户的全部导出记录
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *ExportStorage) DeleteDataExports(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.DATA_EXPORT_COLLECTION).DeleteMany(sessionContext, bson.M{"uid": userID})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+UploadExportFile 上传导出归档
+
+参数：
+  - ctx：上下文
+  - objectName：对象名
+  - data：归档数据
+  - size：数据大小
+
+返回：
+  - minio.UploadInfo：上传信息
+  - error：错误信息
+*/
+func (store *ExportStorage) UploadExportFile(ctx context.Context, objectName string, data io.Reader, size int64) (minio.UploadInfo, error) {
+	info, err := store.minio.PutObject(
+		ctx,
+		models.DATA_EXPORT_BUCKET,
+		objectName,
+		data,
+		size,
+		minio.PutObjectOptions{ContentType: consts.DATA_EXPORT_CONTENT_TYPE},
+	)
+	if err != nil {
+		return info, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return info, nil
+}
+
+/*
+PresignExportFile 生成导出归档的限时下载链接
+
+参数：
+  - ctx：上下文
+  - objectName：对象名
+  - expiry：链接有效期
+
+返回：
+  - string：下载链接
+  - error：错误信息
+*/
+func (store *ExportStorage) PresignExportFile(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
+	url, err := store.minio.PresignedGetObject(ctx, models.DATA_EXPORT_BUCKET, objectName, expiry, nil)
+	if err != nil {
+		return "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return url.String(), nil
+}
+
+/*
+DeleteExportFiles 删除对象名以指定前缀开头的全部导出归档
+
+参数：
+  - ctx：上下文
+  - prefix：对象名前缀
+
+返回：
+  - error：错误信息
+*/
+func (store *ExportStorage) DeleteExportFiles(ctx context.Context, prefix string) error {
+	objects := store.minio.ListObjects(ctx, models.DATA_EXPORT_BUCKET, minio.ListObjectsOptions{Prefix: prefix, Recursive: true})
+	// 需读完错误通道 以免删除协程阻塞
+	var errs []error
+	for removeErr := range store.minio.RemoveObjects(ctx, models.DATA_EXPORT_BUCKET, objects, minio.RemoveObjectsOptions{}) {
+		errs = append(errs, removeErr.Err)
+	}
+	if len(errs) > 0 {
+		return types.NewError(types.ErrServerError, errors.Join(errs...).Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,354 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明关注关系存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+// FollowStorage 关注关系数据库
+type FollowStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+}
+
+/*
+CreateFollow 创建关注关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+
+返回：
+  - error：错误信息
+*/
+func (store *FollowStorage) CreateFollow(sessionContext mongo.SessionContext, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.FOLLOW_COLLECTION).InsertOne(sessionContext, models.FollowInfo{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return types.NewError(types.ErrInvalidParams, "已经关注该用户")
+		}
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DeleteFollow 删除关注关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+
+返回：
+  - error：错误信息
+*/
+func (store *FollowStorage) DeleteFollow(sessionContext mongo.SessionContext, followerID primitive.ObjectID, followeeID primitive.ObjectID) error {
+	result, err := store.mongo.Collection(models.FOLLOW_COLLECTION).DeleteOne(sessionContext, bson.M{
+		"follower_id": followerID,
+		"followee_id": followeeID,
+	})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	if result.DeletedCount == 0 {
+		return types.NewError(types.ErrInvalidParams, "尚未关注该用户")
+	}
+
+	return nil
+}
+
+/*
+IsFollowing 判断是否存在关注关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+
+返回：
+  - bool：是否关注
+  - error：错误信息
+*/
+func (store *FollowStorage) IsFollowing(sessionContext mongo.SessionContext, followerID primitive.ObjectID, followeeID primitive.ObjectID) (bool, error) {
+	err := store.mongo.Collection(models.FOLLOW_COLLECTION).FindOne(sessionContext, bson.M{
+		"follower_id": followerID,
+		"followee_id": followeeID,
+	}).Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return true, nil
+}
+
+/*
+GetFollowers 分页获取用户的粉丝关系 按关注时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - cursor：游标 即上一页最后一条关注关系的ID 为空时从头开始
+  - limit：数量
+
+返回：
+  - []models.FollowInfo：关注关系列表
+  - error：错误信息
+*/
+func (store *FollowStorage) GetFollowers(sessionContext mongo.SessionContext, userID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.FollowInfo, error) {
+	return store.findFollows(sessionContext, bson.M{"followee_id": userID}, cursor, limit)
+}
+
+/*
+GetFollowing 分页获取用户的关注关系 按关注时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - cursor：游标 即上一页最后一条关注关系的ID 为空时从头开始
+  - limit：数量
+
+返回：
+  - []models.FollowInfo：关注关系列表
+  - error：错误信息
+*/
+func (store *FollowStorage) GetFollowing(sessionContext mongo.SessionContext, userID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.FollowInfo, error) {
+	return store.findFollows(sessionContext, bson.M{"follower_id": userID}, cursor, limit)
+}
+
+/*
+GetFollowedAmong 获取候选用户中被指定用户关注的用户
+
+参数：
+  - sessionContext：数据库会话上下文
+  - followerID：关注者ID
+  - candidates：候选用户ID列表
+
+返回：
+  - map[primitive.ObjectID]bool：被关注的候选用户集合
+  - error：错误信息
+*/
+func (store *FollowStorage) GetFollowedAmong(sessionContext mongo.SessionContext, followerID primitive.ObjectID, candidates []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	followed := map[primitive.ObjectID]bool{}
+	if len(candidates) == 0 {
+		return followed, nil
+	}
+
+	follows := []models.FollowInfo{}
+	result, err := store.mongo.Collection(models.FOLLOW_COLLECTION).Find(sessionContext, bson.M{
+		"follower_id": followerID,
+		"followee_id": bson.M{"$in": candidates},
+	})
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	err = result.All(sessionContext, &follows)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	for _, follow := range follows {
+		followed[follow.FolloweeID] = true
+	}
+	return followed, nil
+}
+
+/*
+GetFollowersAmong 获取候选用户中关注了指定用户的用户
+
+参数：
+  - sessionContext：数据库会话上下文
+  - followeeID：被关注者ID
+  - candidates：候选用户ID列表
+
+返回：
+  - map[primitive.ObjectID]bool：关注了该用户的候选用户集合
+  - error：错误信息
+*/
+func (store *FollowStorage) GetFollowersAmong(sessionContext mongo.SessionContext, followeeID primitive.ObjectID, candidates []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	followers := map[primitive.ObjectID]bool{}
+	if len(candidates) == 0 {
+		return followers, nil
+	}
+
+	follows := []models.FollowInfo{}
+	result, err := store.mongo.Collection(models.FOLLOW_COLLECTION).Find(sessionContext, bson.M{
+		"followee_id": followeeID,
+		"follower_id": bson.M{"$in": candidates},
+	})
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	err = result.All(sessionContext, &follows)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	for _, follow := range follows {
+		followers[follow.FollowerID] = true
+	}
+	return followers, nil
+}
+
+/*
+findFollows 按条件分页查询关注关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - filter：查询条件
+  - cursor：游标
+  - limit：数量
+
+返回：
+  - []models.FollowInfo：关注关系列表
+  - error：错误信息
+*/
+func (store *FollowStorage) findFollows(sessionContext mongo.SessionContext, filter bson.M, cursor primitive.ObjectID, limit int64) ([]models.FollowInfo, error) {
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
+
+	result, err := store.mongo.Collection(models.FOLLOW_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	follows := []models.FollowInfo{}
+	err = result.All(sessionContext, &follows)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return follows, nil
+}
+
+/*
+GetFollowerIDs 获取用户的全部粉丝ID
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - []primitive.ObjectID：粉丝ID列表
+  - error：错误信息
+*/
+func (store *FollowStorage) GetFollowerIDs(sessionContext mongo.SessionContext, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	follows, err := store.findAllFollows(sessionContext, bson.M{"followee_id": userID}, "follower_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FollowerID)
+	}
+
+	return ids, nil
+}
+
+/*
+GetFollowingIDs 获取用户关注的全部用户ID
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - []primitive.ObjectID：被关注者ID列表
+  - error：错误信息
+*/
+func (store *FollowStorage) GetFollowingIDs(sessionContext mongo.SessionContext, userID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	follows, err := store.findAllFollows(sessionContext, bson.M{"follower_id": userID}, "followee_id")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FolloweeID)
+	}
+
+	return ids, nil
+}
+
+/*
+findAllFollows 查询全部符合条件的关注关系 仅返回指定字段
+
+参数：
+  - sessionContext：数据库会话上下文
+  - filter：查询条件
+  - field：需要返回的字段
+
+返回：
+  - []models.FollowInfo：关注关系列表
+  - error：错误信息
+*/
+func (store *FollowStorage) findAllFollows(sessionContext mongo.SessionContext, filter bson.M, field string) ([]models.FollowInfo, error) {
+	result, err := store.mongo.Collection(models.FOLLOW_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetProjection(bson.M{field: 1}),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	follows := []models.FollowInfo{}
+	err = result.All(sessionContext, &follows)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return follows, nil
+}
+
+/*
+DeleteUserFollows 删除用户作为关注者或被关注者的全部关注关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *FollowStorage) DeleteUserFollows(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.FOLLOW_COLLECTION).DeleteMany(
+		sessionContext,
+		bson.M{"$or": bson.A{bson.M{"follower_id": userID}, bson.M{"followee_id": userID}}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现登录防护相关存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+/*
+getRemaining 获取键的剩余有效期 键不存在时返回 0
+
+参数：
+  - key：键名
+
+返回：
+  - time.Duration：剩余有效期
+  - error：错误信息
+*/
+func (store *AuthStorage) getRemaining(key string) (time.Duration, error) {
+	ttl, err := store.redis.TTL(context.Background(), key).Result()
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+/*
+GetAccountLock 获取账号锁定的剩余时间
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - time.Duration：剩余锁定时间 未锁定时为 0
+  - error：错误信息
+*/
+func (store *AuthStorage) GetAccountLock(userID string) (time.Duration, error) {
+	return store.getRemaining(functools.JoinStrings(models.REDIS_ACCOUNT_LOCK, ":", userID))
+}
+
+/*
+GetIPLock 获取 IP 锁定的剩余时间
+
+参数：
+  - ip：IP 地址
+
+返回：
+  - time.Duration：剩余锁定时间 未锁定时为 0
+  - error：错误信息
+*/
+func (store *AuthStorage) GetIPLock(ip string) (time.Duration, error) {
+	return store.getRemaining(functools.JoinStrings(models.REDIS_IP_LOCK, ":", ip))
+}
+
+/*
+GetLoginDelay 获取账号再次尝试登录前需等待的时间
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - time.Duration：剩余等待时间 无需等待时为 0
+  - error：错误信息
+*/
+func (store *AuthStorage) GetLoginDelay(userID string) (time.Duration, error) {
+	return store.getRemaining(functools.JoinStrings(models.REDIS_LOGIN_DELAY, ":", userID))
+}
+
+/*
+RecordLoginFailure 累加账号与 IP 的登录失败次数 计数在统计窗口结束后清零
+
+参数：
+  - userID：用户 ID
+  - ip：IP 地址
+
+返回：
+  - int64：账号失败次数
+  - int64：IP 失败次数
+  - error：错误信息
+*/
+func (store *AuthStorage) RecordLoginFailure(userID string, ip string) (int64, int64, error) {
+	ctx := context.Background()
+	accountKey := functools.JoinStrings(models.REDIS_LOGIN_FAILURE_ACCOUNT, ":", userID)
+	ipKey := functools.JoinStrings(models.REDIS_LOGIN_FAILURE_IP, ":", ip)
+
+	// 计数与过期时间在同一事务中设置 首次失败时开始计时 以免计数失去过期时间
+	pipe := store.redis.TxPipeline()
+	accountCount := pipe.Incr(ctx, accountKey)
+	pipe.ExpireNX(ctx, accountKey, consts.LOGIN_FAILURE_WINDOW*time.Second)
+	ipCount := pipe.Incr(ctx, ipKey)
+	pipe.ExpireNX(ctx, ipKey, consts.LOGIN_FAILURE_WINDOW*time.Second)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return 0, 0, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return accountCount.Val(), ipCount.Val(), nil
+}
+
+/*
+RecordIPFailure 仅累加 IP 的登录失败次数 用于账号不存在的登录尝试 计数在统计窗口结束后清零
+
+参数：
+  - ip：IP 地址
+
+返回：
+  - int64：IP 失败次数
+  - error：错误信息
+*/
+func (store *AuthStorage) RecordIPFailure(ip string) (int64, error) {
+	ctx := context.Background()
+	ipKey := functools.JoinStrings(models.REDIS_LOGIN_FAILURE_IP, ":", ip)
+
+	pipe := store.redis.TxPipeline()
+	ipCount := pipe.Incr(ctx, ipKey)
+	pipe.ExpireNX(ctx, ipKey, consts.LOGIN_FAILURE_WINDOW*time.Second)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return ipCount.Val(), nil
+}
+
+/*
+GetLoginFailures 获取账号与 IP 在当前统计窗口内的登录失败次数
+
+参数：
+  - userID：用户 ID
+  - ip：IP 地址
+
+返回：
+  - int64：账号失败次数
+  - int64：IP 失败次数
+  - error：错误信息
+*/
+func (store *AuthStorage) GetLoginFailures(userID string, ip string) (int64, int64, error) {
+	counts, err := store.redis.MGet(
+		context.Background(),
+		functools.JoinStrings(models.REDIS_LOGIN_FAILURE_ACCOUNT, ":", userID),
+		functools.JoinStrings(models.REDIS_LOGIN_FAILURE_IP, ":", ip),
+	).Result()
+	if err != nil {
+		return 0, 0, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	failures := make([]int64, len(counts))
+	for i, count := range counts {
+		if count == nil {
+			continue
+		}
+		failures[i], err = strconv.ParseInt(count.(string), 10, 64)
+		if err != nil {
+			return 0, 0, types.NewError(types.ErrServerError, err.Error())
+		}
+	}
+
+	return failures[0], failures[1], nil
+}
+
+/*
+SetLoginDelay 设置账号再次尝试登录前需等待的时间
+
+参数：
+  - userID：用户 ID
+  - delay：等待时间
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SetLoginDelay(userID string, delay time.Duration) error {
+	err := store.redis.Set(
+		context.Background(), functools.JoinStrings(models.REDIS_LOGIN_DELAY, ":", userID), 1, delay,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+LockAccount 临时锁定账号 并清空其失败计数 以便解锁后重新计数
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) LockAccount(userID string) error {
+	ctx := context.Background()
+
+	pipe := store.redis.TxPipeline()
+	pipe.Set(ctx, functools.JoinStrings(models.REDIS_ACCOUNT_LOCK, ":", userID), 1, consts.ACCOUNT_LOCK_DURATION*time.Second)
+	pipe.Del(
+		ctx,
+		functools.JoinStrings(models.REDIS_LOGIN_FAILURE_ACCOUNT, ":", userID),
+		functools.JoinStrings(models.REDIS_LOGIN_DELAY, ":", userID),
+	)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+LockIP 临时禁止 IP 登录 并清空其失败计数
+
+参数：
+  - ip：IP 地址
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) LockIP(ip string) error {
+	ctx := context.Background()
+
+	pipe := store.redis.TxPipeline()
+	pipe.Set(ctx, functools.JoinStrings(models.REDIS_IP_LOCK, ":", ip), 1, consts.IP_LOCK_DURATION*time.Second)
+	pipe.Del(ctx, functools.JoinStrings(models.REDIS_LOGIN_FAILURE_IP, ":", ip))
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+ClearLoginFailures 清除账号的失败计数、等待与锁定状态
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) ClearLoginFailures(userID string) error {
+	err := store.redis.Del(
+		context.Background(),
+		functools.JoinStrings(models.REDIS_LOGIN_FAILURE_ACCOUNT, ":", userID),
+		functools.JoinStrings(models.REDIS_LOGIN_DELAY, ":", userID),
+		functools.JoinStrings(models.REDIS_ACCOUNT_LOCK, ":", userID),
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+SaveUnlockToken 保存账号解锁令牌 有效期与锁定时长一致
+
+参数：
+  - token：解锁令牌
+  - userID：用户 ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SaveUnlockToken(token string, userID string) error {
+	err := store.redis.Set(
+		context.Background(),
+		functools.JoinStrings(models.REDIS_ACCOUNT_UNLOCK_TOKEN, ":", token),
+		userID,
+		consts.ACCOUNT_LOCK_DURATION*time.Second,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+ConsumeUnlockToken 读取并删除账号解锁令牌 令牌仅可使用一次
+
+参数：
+  - token：解锁令牌
+
+返回：
+  - string：用户 ID
+  - error：错误信息
+*/
+func (store *AuthStorage) ConsumeUnlockToken(token string) (string, error) {
+	userID, err := store.redis.GetDel(
+		context.Background(), functools.JoinStrings(models.REDIS_ACCOUNT_UNLOCK_TOKEN, ":", token),
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", types.NewError(types.ErrInvalidParams, "解锁令牌无效或已过期")
+		}
+		return "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return userID, nil
+}
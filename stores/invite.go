@@ -0,0 +1,195 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现邀请码存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+/*
+CreateInviteCode 保存邀请码
+
+参数：
+  - sessionContext：数据库会话上下文
+  - invite：邀请码
+
+返回：
+  - primitive.ObjectID：邀请码ID
+  - error：错误信息
+*/
+func (store *UserStorage) CreateInviteCode(sessionContext mongo.SessionContext, invite models.InviteCode) (primitive.ObjectID, error) {
+	result, err := store.mongo.Collection(models.INVITE_CODE_COLLECTION).InsertOne(sessionContext, invite)
+	if err != nil {
+		return primitive.NilObjectID, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+/*
+CountActiveInviteCodes 统计用户创建的仍可使用的邀请码数量
+
+参数：
+  - sessionContext：数据库会话上下文
+  - creatorID：创建者ID
+  - now：当前时间
+
+返回：
+  - int64：邀请码数量
+  - error：错误信息
+*/
+func (store *UserStorage) CountActiveInviteCodes(sessionContext mongo.SessionContext, creatorID primitive.ObjectID, now time.Time) (int64, error) {
+	count, err := store.mongo.Collection(models.INVITE_CODE_COLLECTION).CountDocuments(sessionContext, bson.M{
+		"creator_id": creatorID,
+		"revoked":    bson.M{"$ne": true},
+		"expires_at": bson.M{"$gt": now},
+		"$expr":      bson.M{"$lt": bson.A{"$used_count", "$max_uses"}},
+	})
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+/*
+GetInviteCodesByCreator 获取用户创建的全部邀请码 按创建时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - creatorID：创建者ID
+
+返回：
+  - []models.InviteCode：邀请码列表
+  - error：错误信息
+*/
+func (store *UserStorage) GetInviteCodesByCreator(sessionContext mongo.SessionContext, creatorID primitive.ObjectID) ([]models.InviteCode, error) {
+	result, err := store.mongo.Collection(models.INVITE_CODE_COLLECTION).Find(
+		sessionContext,
+		bson.M{"creator_id": creatorID},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	invites := []models.InviteCode{}
+	err = result.All(sessionContext, &invites)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return invites, nil
+}
+
+/*
+ConsumeInviteCode 使用邀请码 仅在邀请码未作废 未过期且仍有剩余次数时成功
+
+参数：
+  - sessionContext：数据库会话上下文
+  - code：邀请码
+  - now：当前时间
+
+返回：
+  - models.InviteCode：使用后的邀请码
+  - error：错误信息
+*/
+func (store *UserStorage) ConsumeInviteCode(sessionContext mongo.SessionContext, code string, now time.Time) (models.InviteCode, error) {
+	invite := models.InviteCode{}
+	err := store.mongo.Collection(models.INVITE_CODE_COLLECTION).FindOneAndUpdate(
+		sessionContext,
+		bson.M{
+			"code":       code,
+			"revoked":    bson.M{"$ne": true},
+			"expires_at": bson.M{"$gt": now},
+			"$expr":      bson.M{"$lt": bson.A{"$used_count", "$max_uses"}},
+		},
+		bson.M{"$inc": bson.M{"used_count": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&invite)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return invite, types.NewError(types.ErrInvalidParams, "邀请码无效或已过期")
+		}
+		return invite, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return invite, nil
+}
+
+/*
+RevokeInviteCode 作废用户创建的邀请码
+
+参数：
+  - sessionContext：数据库会话上下文
+  - creatorID：创建者ID
+  - inviteID：邀请码ID
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) RevokeInviteCode(sessionContext mongo.SessionContext, creatorID primitive.ObjectID, inviteID primitive.ObjectID) error {
+	result, err := store.mongo.Collection(models.INVITE_CODE_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": inviteID, "creator_id": creatorID},
+		bson.M{"$set": bson.M{"revoked": true}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	if result.MatchedCount == 0 {
+		return types.NewError(types.ErrInvalidParams, "邀请码不存在")
+	}
+
+	return nil
+}
+
+/*
+GetInvitees 获取用户邀请注册的用户 按注册时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - inviterID：邀请人ID
+  - cursor：游标
+  - limit：分页大小
+
+返回：
+  - []models.UserInfo：用户信息列表
+  - error：错误信息
+*/
+func (store *UserStorage) GetInvitees(sessionContext mongo.SessionContext, inviterID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.UserInfo, error) {
+	filter := bson.M{"invited_by": inviterID}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
+
+	result, err := store.mongo.Collection(models.USER_INFO_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	users := []models.UserInfo{}
+	err = result.All(sessionContext, &users)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return users, nil
+}
@@ -0,0 +1,177 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明媒体文件存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+// MediaStorage 媒体文件数据库
+type MediaStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+	minio *minio.Client
+}
+
+/*
+CreateMedia 创建媒体文件记录
+
+参数：
+  - sessionContext：数据库会话上下文
+  - mediaInfo：媒体文件信息
+
+返回：
+  - error：错误信息
+*/
+func (store *MediaStorage) CreateMedia(sessionContext mongo.SessionContext, mediaInfo models.MediaInfo) error {
+	mediaInfo.CreatedAt = time.Now()
+	_, err := store.mongo.Collection(models.MEDIA_COLLECTION).InsertOne(sessionContext, mediaInfo)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+CountUserMedia 统计属于指定用户的媒体文件数量
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - mediaIDs：媒体ID列表
+
+返回：
+  - int64：属于该用户的媒体文件数量
+  - error：错误信息
+*/
+func (store *MediaStorage) CountUserMedia(sessionContext mongo.SessionContext, userID primitive.ObjectID, mediaIDs []primitive.ObjectID) (int64, error) {
+	count, err := store.mongo.Collection(models.MEDIA_COLLECTION).CountDocuments(sessionContext, bson.M{
+		"_id": bson.M{"$in": mediaIDs},
+		"uid": userID,
+	})
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+/*
+UploadMediaFile 上传媒体文件
+
+参数：
+  - ctx：上下文
+  - fileName：文件名
+  - mediaData：媒体数据
+  - size：数据大小
+  - contentType：文件类型
+
+返回：
+  - minio.UploadInfo：上传信息
+  - error：错误信息
+*/
+func (store *MediaStorage) UploadMediaFile(ctx context.Context, fileName string, mediaData io.Reader, size int64, contentType string) (minio.UploadInfo, error) {
+	info, err := store.minio.PutObject(
+		ctx,
+		models.POST_MEDIA_BUCKET,
+		fileName,
+		mediaData,
+		size,
+		minio.PutObjectOptions{ContentType: contentType},
+	)
+	if err != nil {
+		return info, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return info, nil
+}
+
+/*
+GetMediaFile 获取媒体文件
+
+参数：
+  - ctx：上下文
+  - fileName：文件名
+
+返回：
+  - *minio.Object：媒体文件 使用后需关闭
+  - error：错误信息
+*/
+func (store *MediaStorage) GetMediaFile(ctx context.Context, fileName string) (*minio.Object, error) {
+	return store.minio.GetObject(ctx, models.POST_MEDIA_BUCKET, fileName, minio.GetObjectOptions{})
+}
+
+/*
+DeleteMediaFile 删除媒体文件
+
+参数：
+  - ctx：上下文
+  - fileName：文件名
+
+返回：
+  - error：错误信息
+*/
+func (store *MediaStorage) DeleteMediaFile(ctx context.Context, fileName string) error {
+	return store.minio.RemoveObject(ctx, models.POST_MEDIA_BUCKET, fileName, minio.RemoveObjectOptions{})
+}
+
+/*
+GetMediaByUser 获取用户上传的全部媒体文件记录
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - []models.MediaInfo：媒体文件列表
+  - error：错误信息
+*/
+func (store *MediaStorage) GetMediaByUser(sessionContext mongo.SessionContext, userID primitive.ObjectID) ([]models.MediaInfo, error) {
+	result, err := store.mongo.Collection(models.MEDIA_COLLECTION).Find(sessionContext, bson.M{"uid": userID})
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	media := []models.MediaInfo{}
+	err = result.All(sessionContext, &media)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return media, nil
+}
+
+/*
+DeleteMediaByUser 删除用户上传的全部媒体文件记录
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *MediaStorage) DeleteMediaByUser(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.MEDIA_COLLECTION).DeleteMany(sessionContext, bson.M{"uid": userID})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
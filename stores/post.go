@@ -0,0 +1,393 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明博文存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+// PostStorage 博文信息数据库
+type PostStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+}
+
+/*
+CreatePost 创建博文
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postInfo：博文信息
+
+返回：
+  - primitive.ObjectID：博文ID
+  - error：错误信息
+*/
+func (store *PostStorage) CreatePost(sessionContext mongo.SessionContext, postInfo models.PostInfo) (primitive.ObjectID, error) {
+	now := time.Now()
+	postInfo.CreatedAt = now
+	postInfo.UpdatedAt = now
+	postInfo.RepostCount = 0
+
+	result, err := store.mongo.Collection(models.POST_COLLECTION).InsertOne(sessionContext, postInfo)
+	if err != nil {
+		return primitive.NilObjectID, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+/*
+GetPostByID 通过博文ID获取博文信息 已删除的博文视为不存在
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postID：博文ID
+
+返回：
+  - models.PostInfo：博文信息
+  - error：错误信息
+*/
+func (store *PostStorage) GetPostByID(sessionContext mongo.SessionContext, postID primitive.ObjectID) (models.PostInfo, error) {
+	var postInfo models.PostInfo
+	err := store.mongo.Collection(models.POST_COLLECTION).FindOne(sessionContext, bson.M{
+		"_id":        postID,
+		"is_deleted": bson.M{"$ne": true},
+	}).Decode(&postInfo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return postInfo, types.NewError(types.ErrInvalidParams, "博文不存在")
+		}
+		return postInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return postInfo, nil
+}
+
+/*
+GetPostsByIDs 批量获取博文信息 已删除的博文不返回
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postIDs：博文ID列表
+
+返回：
+  - []models.PostInfo：博文列表
+  - error：错误信息
+*/
+func (store *PostStorage) GetPostsByIDs(sessionContext mongo.SessionContext, postIDs []primitive.ObjectID) ([]models.PostInfo, error) {
+	posts := []models.PostInfo{}
+	if len(postIDs) == 0 {
+		return posts, nil
+	}
+
+	result, err := store.mongo.Collection(models.POST_COLLECTION).Find(sessionContext, bson.M{
+		"_id":        bson.M{"$in": postIDs},
+		"is_deleted": bson.M{"$ne": true},
+	})
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	err = result.All(sessionContext, &posts)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return posts, nil
+}
+
+/*
+GetPostsByUser 分页获取用户博文列表 按发布时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - cursor：游标 即上一页最后一条博文的ID 为空时从头开始
+  - limit：数量
+  - includePrivate：是否包含非公开博文
+
+返回：
+  - []models.PostInfo：博文列表
+  - error：错误信息
+*/
+func (store *PostStorage) GetPostsByUser(sessionContext mongo.SessionContext, userID primitive.ObjectID, cursor primitive.ObjectID, limit int64, includePrivate bool) ([]models.PostInfo, error) {
+	filter := bson.M{
+		"uid":        userID,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
+	if !includePrivate {
+		filter["is_public"] = true
+	}
+
+	result, err := store.mongo.Collection(models.POST_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	posts := []models.PostInfo{}
+	err = result.All(sessionContext, &posts)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return posts, nil
+}
+
+/*
+GetPublicPostsByUsers 分页获取多个用户的公开博文 按发布时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userIDs：用户ID列表
+  - cursor：游标 即上一页最后一条博文的ID 为空时从头开始
+  - limit：数量
+
+返回：
+  - []models.PostInfo：博文列表
+  - error：错误信息
+*/
+func (store *PostStorage) GetPublicPostsByUsers(sessionContext mongo.SessionContext, userIDs []primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.PostInfo, error) {
+	posts := []models.PostInfo{}
+	if len(userIDs) == 0 {
+		return posts, nil
+	}
+
+	filter := bson.M{
+		"uid":        bson.M{"$in": userIDs},
+		"is_public":  true,
+		"is_deleted": bson.M{"$ne": true},
+	}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
+
+	result, err := store.mongo.Collection(models.POST_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	err = result.All(sessionContext, &posts)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return posts, nil
+}
+
+/*
+UpdatePost 更新博文
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postID：博文ID
+  - fields：需要更新的字段
+
+返回：
+  - error：错误信息
+*/
+func (store *PostStorage) UpdatePost(sessionContext mongo.SessionContext, postID primitive.ObjectID, fields bson.M) error {
+	fields["updated_at"] = time.Now()
+	_, err := store.mongo.Collection(models.POST_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": postID},
+		bson.M{"$set": fields},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DeletePost 删除博文 仅做标记删除 以保留转发链与评论的引用关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postID：博文ID
+
+返回：
+  - error：错误信息
+*/
+func (store *PostStorage) DeletePost(sessionContext mongo.SessionContext, postID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.POST_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": postID},
+		bson.M{"$set": bson.M{"is_deleted": true, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetReposts 分页获取博文的公开转发列表 包含引用转发 按发布时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postID：被转发的博文ID
+  - cursor：游标 即上一页最后一条博文的ID 为空时从头开始
+  - limit：数量
+
+返回：
+  - []models.PostInfo：转发列表
+  - error：错误信息
+*/
+func (store *PostStorage) GetReposts(sessionContext mongo.SessionContext, postID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.PostInfo, error) {
+	filter := bson.M{
+		"parent_post_id": postID,
+		"is_public":      true,
+		"is_deleted":     bson.M{"$ne": true},
+	}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
+
+	result, err := store.mongo.Collection(models.POST_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	posts := []models.PostInfo{}
+	err = result.All(sessionContext, &posts)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return posts, nil
+}
+
+/*
+GetUserRepost 获取用户对某篇博文的直接转发 不包含引用转发
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - postID：被转发的博文ID
+
+返回：
+  - models.PostInfo：转发博文信息
+  - error：错误信息 未转发时返回 mongo.ErrNoDocuments
+*/
+func (store *PostStorage) GetUserRepost(sessionContext mongo.SessionContext, userID primitive.ObjectID, postID primitive.ObjectID) (models.PostInfo, error) {
+	var postInfo models.PostInfo
+	err := store.mongo.Collection(models.POST_COLLECTION).FindOne(sessionContext, bson.M{
+		"uid":            userID,
+		"parent_post_id": postID,
+		"content":        bson.M{"$in": bson.A{nil, ""}},
+		"is_deleted":     bson.M{"$ne": true},
+	}).Decode(&postInfo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return postInfo, err
+		}
+		return postInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return postInfo, nil
+}
+
+/*
+IncreaseRepostCount 增加博文的转发数
+
+参数：
+  - sessionContext：数据库会话上下文
+  - postID：博文ID
+  - delta：增量
+
+返回：
+  - error：错误信息
+*/
+func (store *PostStorage) IncreaseRepostCount(sessionContext mongo.SessionContext, postID primitive.ObjectID, delta int64) error {
+	_, err := store.mongo.Collection(models.POST_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": postID},
+		bson.M{"$inc": bson.M{"repost_count": delta}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+AnonymizeUserPosts 去除注销用户博文中的 IP 地址 博文内容保留
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *PostStorage) AnonymizeUserPosts(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.POST_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID},
+		bson.M{"$unset": bson.M{"ip_address": ""}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+RemoveUserPosts 删除注销用户的全部博文 标记删除并清空内容 以保留转发链与评论的引用关系
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *PostStorage) RemoveUserPosts(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.POST_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID},
+		bson.M{
+			"$set":   bson.M{"is_deleted": true, "updated_at": time.Now()},
+			"$unset": bson.M{"title": "", "content": "", "media_ids": "", "ip_address": ""},
+		},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
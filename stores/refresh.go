@@ -0,0 +1,143 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现刷新令牌相关存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+// consumeRefreshTokenScript 原子地取出刷新令牌并将其标记为已使用
+//
+// KEYS[1]：刷新令牌键 KEYS[2]：已使用标记键 ARGV[1]：标记有效期（秒）
+var consumeRefreshTokenScript = redis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if not value then
+	return false
+end
+redis.call('DEL', KEYS[1])
+redis.call('SET', KEYS[2], value, 'EX', ARGV[1])
+return value
+`)
+
+/*
+SaveRefreshToken 保存刷新令牌 并将其设为所属令牌族的当前令牌
+
+参数：
+  - tokenHash：刷新令牌摘要
+  - info：刷新令牌信息
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SaveRefreshToken(tokenHash string, info models.RefreshTokenInfo) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	pipe := store.redis.TxPipeline()
+	pipe.Set(
+		ctx,
+		functools.JoinStrings(models.REDIS_REFRESH_TOKEN, ":", tokenHash),
+		data,
+		consts.REFRESH_TOKEN_EXPIRE_DURATION*time.Second,
+	)
+	pipe.Set(
+		ctx,
+		functools.JoinStrings(models.REDIS_REFRESH_TOKEN_FAMILY, ":", info.FamilyID),
+		tokenHash,
+		consts.REFRESH_TOKEN_EXPIRE_DURATION*time.Second,
+	)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+ConsumeRefreshToken 取出刷新令牌 取出后令牌被标记为已使用 不可再次使用
+
+参数：
+  - tokenHash：刷新令牌摘要
+
+返回：
+  - models.RefreshTokenInfo：刷新令牌信息
+  - bool：令牌是否有效
+  - error：错误信息
+*/
+func (store *AuthStorage) ConsumeRefreshToken(tokenHash string) (models.RefreshTokenInfo, bool, error) {
+	var info models.RefreshTokenInfo
+
+	value, err := consumeRefreshTokenScript.Run(
+		context.Background(),
+		store.redis,
+		[]string{
+			functools.JoinStrings(models.REDIS_REFRESH_TOKEN, ":", tokenHash),
+			functools.JoinStrings(models.REDIS_USED_REFRESH_TOKEN, ":", tokenHash),
+		},
+		consts.REFRESH_TOKEN_EXPIRE_DURATION,
+	).Text()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return info, false, nil
+		}
+		return info, false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	err = json.Unmarshal([]byte(value), &info)
+	if err != nil {
+		return info, false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return info, true, nil
+}
+
+/*
+GetUsedRefreshToken 获取已轮换的刷新令牌信息 用于检测刷新令牌重放
+
+参数：
+  - tokenHash：刷新令牌摘要
+
+返回：
+  - models.RefreshTokenInfo：刷新令牌信息
+  - bool：令牌是否曾被使用
+  - error：错误信息
+*/
+func (store *AuthStorage) GetUsedRefreshToken(tokenHash string) (models.RefreshTokenInfo, bool, error) {
+	var info models.RefreshTokenInfo
+
+	value, err := store.redis.Get(
+		context.Background(), functools.JoinStrings(models.REDIS_USED_REFRESH_TOKEN, ":", tokenHash),
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return info, false, nil
+		}
+		return info, false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	err = json.Unmarshal([]byte(value), &info)
+	if err != nil {
+		return info, false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return info, true, nil
+}
@@ -0,0 +1,302 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明回复存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+// ReplyStorage 回复信息数据库
+type ReplyStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+}
+
+/*
+CreateReply 创建回复
+
+参数：
+  - sessionContext：数据库会话上下文
+  - replyInfo：回复信息 ID 为空时由数据库生成
+
+返回：
+  - primitive.ObjectID：回复ID
+  - error：错误信息
+*/
+func (store *ReplyStorage) CreateReply(sessionContext mongo.SessionContext, replyInfo models.ReplyInfo) (primitive.ObjectID, error) {
+	replyInfo.CreatedAt = time.Now()
+	replyInfo.ReplyCount = 0
+
+	result, err := store.mongo.Collection(models.REPLY_COLLECTION).InsertOne(sessionContext, replyInfo)
+	if err != nil {
+		return primitive.NilObjectID, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+/*
+GetReplyByID 通过回复ID获取回复
+
+参数：
+  - sessionContext：数据库会话上下文
+  - replyID：回复ID
+
+返回：
+  - models.ReplyInfo：回复信息
+  - error：错误信息
+*/
+func (store *ReplyStorage) GetReplyByID(sessionContext mongo.SessionContext, replyID primitive.ObjectID) (models.ReplyInfo, error) {
+	var replyInfo models.ReplyInfo
+	err := store.mongo.Collection(models.REPLY_COLLECTION).FindOne(sessionContext, bson.M{"_id": replyID}).Decode(&replyInfo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return replyInfo, types.NewError(types.ErrInvalidParams, "回复不存在")
+		}
+		return replyInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return replyInfo, nil
+}
+
+/*
+GetRootReplies 分页获取评论下的顶层回复 按发布时间正序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - commentID：评论ID
+  - cursor：游标 即上一页最后一条顶层回复的ID 为空时从头开始
+  - limit：数量
+
+返回：
+  - []models.ReplyInfo：回复列表
+  - error：错误信息
+*/
+func (store *ReplyStorage) GetRootReplies(sessionContext mongo.SessionContext, commentID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]models.ReplyInfo, error) {
+	filter := bson.M{
+		"comment_id":      commentID,
+		"parent_reply_id": nil,
+	}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$gt": cursor}
+	}
+
+	result, err := store.mongo.Collection(models.REPLY_COLLECTION).Find(
+		sessionContext,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	replies := []models.ReplyInfo{}
+	err = result.All(sessionContext, &replies)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return replies, nil
+}
+
+/*
+GetRepliesByRoots 获取若干楼层下的全部子回复 按发布时间正序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - rootReplyIDs：顶层回复ID列表
+
+返回：
+  - []models.ReplyInfo：回复列表
+  - error：错误信息
+*/
+func (store *ReplyStorage) GetRepliesByRoots(sessionContext mongo.SessionContext, rootReplyIDs []primitive.ObjectID) ([]models.ReplyInfo, error) {
+	replies := []models.ReplyInfo{}
+	if len(rootReplyIDs) == 0 {
+		return replies, nil
+	}
+
+	result, err := store.mongo.Collection(models.REPLY_COLLECTION).Find(
+		sessionContext,
+		bson.M{"root_reply_id": bson.M{"$in": rootReplyIDs}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	err = result.All(sessionContext, &replies)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return replies, nil
+}
+
+/*
+IncreaseReplyCount 增加楼层的回复数
+
+参数：
+  - sessionContext：数据库会话上下文
+  - rootReplyID：顶层回复ID
+  - delta：增量
+
+返回：
+  - error：错误信息
+*/
+func (store *ReplyStorage) IncreaseReplyCount(sessionContext mongo.SessionContext, rootReplyID primitive.ObjectID, delta int64) error {
+	_, err := store.mongo.Collection(models.REPLY_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": rootReplyID},
+		bson.M{"$inc": bson.M{"reply_count": delta}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DeleteReply 删除回复 仅做标记删除并清空内容 以保留楼层结构
+
+参数：
+  - sessionContext：数据库会话上下文
+  - replyID：回复ID
+
+返回：
+  - error：错误信息
+*/
+func (store *ReplyStorage) DeleteReply(sessionContext mongo.SessionContext, replyID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.REPLY_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": replyID},
+		bson.M{
+			"$set":   bson.M{"is_deleted": true},
+			"$unset": bson.M{"content": ""},
+		},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetRepliesByUser 获取用户发表的全部未删除回复 按发表时间正序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - []models.ReplyInfo：回复列表
+  - error：错误信息
+*/
+func (store *ReplyStorage) GetRepliesByUser(sessionContext mongo.SessionContext, userID primitive.ObjectID) ([]models.ReplyInfo, error) {
+	result, err := store.mongo.Collection(models.REPLY_COLLECTION).Find(
+		sessionContext,
+		bson.M{"uid": userID, "is_deleted": bson.M{"$ne": true}},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	replies := []models.ReplyInfo{}
+	err = result.All(sessionContext, &replies)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return replies, nil
+}
+
+/*
+RenameUserReplies 用户修改用户名后 同步其回复中记录的用户名
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - username：新用户名
+
+返回：
+  - error：错误信息
+*/
+func (store *ReplyStorage) RenameUserReplies(sessionContext mongo.SessionContext, userID primitive.ObjectID, username string) error {
+	_, err := store.mongo.Collection(models.REPLY_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID, "is_deleted": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"username": username}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+AnonymizeUserReplies 将注销用户回复中的用户名替换为匿名名称 回复内容保留
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *ReplyStorage) AnonymizeUserReplies(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.REPLY_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID},
+		bson.M{"$set": bson.M{"username": consts.DELETED_USER_NAME}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+RemoveUserReplies 删除注销用户的全部回复 标记删除并清空内容 以保留楼层结构
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *ReplyStorage) RemoveUserReplies(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.REPLY_COLLECTION).UpdateMany(
+		sessionContext,
+		bson.M{"uid": userID},
+		bson.M{
+			"$set":   bson.M{"is_deleted": true, "username": consts.DELETED_USER_NAME},
+			"$unset": bson.M{"content": ""},
+		},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,370 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现会话与访问令牌存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+// revokeSessionLua 吊销会话的公共 Lua 函数 同时删除会话记录与所属令牌族当前的刷新令牌
+// 会话记录与刷新令牌的键由会话 ID 在脚本内拼接 未在 KEYS 中声明 因此仅支持单节点 Redis
+//
+// KEYS[1]：用户会话索引键
+const revokeSessionLua = `
+local function revoke(sid)
+	redis.call('ZREM', KEYS[1], sid)
+	redis.call('DEL', '` + models.REDIS_SESSION + `:' .. sid)
+	local family = '` + models.REDIS_REFRESH_TOKEN_FAMILY + `:' .. sid
+	local hash = redis.call('GET', family)
+	if hash then
+		redis.call('DEL', '` + models.REDIS_REFRESH_TOKEN + `:' .. hash, family)
+	end
+end
+`
+
+// createSessionScript 创建会话 清理已过期的会话索引 并淘汰超出数量上限的最早会话
+//
+// KEYS[1]：用户会话索引键 KEYS[2]：会话记录键
+// ARGV[1]：会话 ID ARGV[2]：创建时间 ARGV[3]：有效期（秒） ARGV[4]：会话数量上限 ARGV[5...]：会话记录字段与值
+var createSessionScript = redis.NewScript(revokeSessionLua + `
+redis.call('HSET', KEYS[2], unpack(ARGV, 5))
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+
+for _, sid in ipairs(redis.call('ZRANGE', KEYS[1], 0, -1)) do
+	if redis.call('EXISTS', '` + models.REDIS_SESSION + `:' .. sid) == 0 then
+		redis.call('ZREM', KEYS[1], sid)
+	end
+end
+
+local evicted = {}
+local overflow = redis.call('ZCARD', KEYS[1]) - tonumber(ARGV[4])
+if overflow > 0 then
+	for _, sid in ipairs(redis.call('ZRANGE', KEYS[1], 0, overflow - 1)) do
+		revoke(sid)
+		table.insert(evicted, sid)
+	end
+end
+return evicted
+`)
+
+// validateSessionScript 校验访问令牌是否为会话当前的令牌 并按间隔更新最后使用时间 账号已停用时返回 -1
+//
+// KEYS[1]：会话记录键 KEYS[2]：账号停用标记键
+// ARGV[1]：用户 ID ARGV[2]：令牌 ID ARGV[3]：当前时间 ARGV[4]：更新间隔（秒）
+var validateSessionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	return -1
+end
+local record = redis.call('HMGET', KEYS[1], 'uid', 'jti', 'last_used_at')
+if record[1] ~= ARGV[1] or record[2] ~= ARGV[2] then
+	return 0
+end
+if tonumber(ARGV[3]) - (tonumber(record[3]) or 0) >= tonumber(ARGV[4]) then
+	redis.call('HSET', KEYS[1], 'last_used_at', ARGV[3])
+end
+return 1
+`)
+
+// rotateSessionScript 将会话的访问令牌替换为新令牌 旧令牌不是会话当前的令牌时不做修改
+//
+// KEYS[1]：用户会话索引键 KEYS[2]：会话记录键
+// ARGV[1]：旧令牌 ID ARGV[2]：新令牌 ID ARGV[3]：当前时间 ARGV[4]：有效期（秒）
+var rotateSessionScript = redis.NewScript(`
+if redis.call('HGET', KEYS[2], 'jti') ~= ARGV[1] then
+	return 0
+end
+redis.call('HSET', KEYS[2], 'jti', ARGV[2], 'last_used_at', ARGV[3])
+redis.call('EXPIRE', KEYS[2], ARGV[4])
+redis.call('EXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+// removeSessionsScript 吊销指定会话
+//
+// KEYS[1]：用户会话索引键 ARGV：会话 ID 列表
+var removeSessionsScript = redis.NewScript(revokeSessionLua + `
+for _, sid in ipairs(ARGV) do
+	revoke(sid)
+end
+return 1
+`)
+
+// removeAllSessionsScript 吊销用户的全部会话
+//
+// KEYS[1]：用户会话索引键
+var removeAllSessionsScript = redis.NewScript(revokeSessionLua + `
+for _, sid in ipairs(redis.call('ZRANGE', KEYS[1], 0, -1)) do
+	revoke(sid)
+end
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+/*
+CreateSession 创建会话 会话数量超过上限时吊销最早的会话
+
+参数：
+  - session：会话信息
+  - maxSessions：会话数量上限
+
+返回：
+  - []string：被淘汰的会话 ID 列表
+  - error：错误信息
+*/
+func (store *AuthStorage) CreateSession(session models.SessionInfo, maxSessions int) ([]string, error) {
+	args := []interface{}{
+		session.ID,
+		session.IssuedAt.Unix(),
+		consts.REFRESH_TOKEN_EXPIRE_DURATION,
+		maxSessions,
+		"uid", session.UID,
+		"jti", session.JTI,
+		"ip", session.IP,
+		"location", session.Location,
+		"device", session.Device,
+		"application", session.Application,
+		"issued_at", session.IssuedAt.Unix(),
+		"last_used_at", session.LastUsedAt.Unix(),
+	}
+
+	evicted, err := createSessionScript.Run(
+		context.Background(),
+		store.redis,
+		[]string{
+			functools.JoinStrings(models.REDIS_USER_SESSIONS, ":", session.UID),
+			functools.JoinStrings(models.REDIS_SESSION, ":", session.ID),
+		},
+		args...,
+	).StringSlice()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return evicted, nil
+}
+
+/*
+ValidateSession 校验访问令牌是否仍为所属会话当前的令牌 并更新会话最后使用时间
+账号已停用时返回 types.ErrAccountSuspended 错误
+
+参数：
+  - userID：用户 ID
+  - sessionID：会话 ID
+  - jti：令牌 ID
+
+返回：
+  - bool：令牌是否可用
+  - error：错误信息
+*/
+func (store *AuthStorage) ValidateSession(userID string, sessionID string, jti string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	result, err := validateSessionScript.Run(
+		context.Background(),
+		store.redis,
+		[]string{
+			functools.JoinStrings(models.REDIS_SESSION, ":", sessionID),
+			functools.JoinStrings(models.REDIS_ACCOUNT_SUSPENDED, ":", userID),
+		},
+		userID,
+		jti,
+		time.Now().Unix(),
+		consts.SESSION_TOUCH_INTERVAL,
+	).Int()
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+	if result == -1 {
+		return false, store.CheckAccountSuspension(userID)
+	}
+
+	return result == 1, nil
+}
+
+/*
+RotateSessionToken 将会话的访问令牌替换为新令牌
+
+参数：
+  - userID：用户 ID
+  - sessionID：会话 ID
+  - oldJTI：旧令牌 ID
+  - newJTI：新令牌 ID
+
+返回：
+  - bool：是否替换成功 会话已被吊销或旧令牌已被替换时为 false
+  - error：错误信息
+*/
+func (store *AuthStorage) RotateSessionToken(userID string, sessionID string, oldJTI string, newJTI string) (bool, error) {
+	result, err := rotateSessionScript.Run(
+		context.Background(),
+		store.redis,
+		[]string{
+			functools.JoinStrings(models.REDIS_USER_SESSIONS, ":", userID),
+			functools.JoinStrings(models.REDIS_SESSION, ":", sessionID),
+		},
+		oldJTI,
+		newJTI,
+		time.Now().Unix(),
+		consts.REFRESH_TOKEN_EXPIRE_DURATION,
+	).Int()
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result == 1, nil
+}
+
+/*
+GetSessions 获取用户的全部有效会话 按创建时间倒序
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - []models.SessionInfo：会话列表
+  - error：错误信息
+*/
+func (store *AuthStorage) GetSessions(userID string) ([]models.SessionInfo, error) {
+	ctx := context.Background()
+	indexKey := functools.JoinStrings(models.REDIS_USER_SESSIONS, ":", userID)
+
+	// 获取会话索引
+	sessionIDs, err := store.redis.ZRevRange(ctx, indexKey, 0, -1).Result()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	if len(sessionIDs) == 0 {
+		return []models.SessionInfo{}, nil
+	}
+
+	// 批量读取会话记录
+	pipe := store.redis.Pipeline()
+	commands := make([]*redis.MapStringStringCmd, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		commands = append(commands, pipe.HGetAll(ctx, functools.JoinStrings(models.REDIS_SESSION, ":", sessionID)))
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	sessions := make([]models.SessionInfo, 0, len(sessionIDs))
+	stale := []interface{}{}
+	for i, command := range commands {
+		record := command.Val()
+		if len(record) == 0 {
+			stale = append(stale, sessionIDs[i])
+			continue
+		}
+		sessions = append(sessions, newSessionInfo(sessionIDs[i], record))
+	}
+
+	// 清理已过期会话残留的索引
+	if len(stale) > 0 {
+		err = store.redis.ZRem(ctx, indexKey, stale...).Err()
+		if err != nil {
+			return nil, types.NewError(types.ErrServerError, err.Error())
+		}
+	}
+
+	return sessions, nil
+}
+
+/*
+RemoveSessions 吊销会话 同时吊销会话对应的刷新令牌
+
+参数：
+  - userID：用户 ID
+  - sessionIDs：会话 ID 列表
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) RemoveSessions(userID string, sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		args = append(args, sessionID)
+	}
+
+	err := removeSessionsScript.Run(
+		context.Background(),
+		store.redis,
+		[]string{functools.JoinStrings(models.REDIS_USER_SESSIONS, ":", userID)},
+		args...,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+RemoveAllSessions 吊销用户的全部会话与刷新令牌
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) RemoveAllSessions(userID string) error {
+	err := removeAllSessionsScript.Run(
+		context.Background(),
+		store.redis,
+		[]string{functools.JoinStrings(models.REDIS_USER_SESSIONS, ":", userID)},
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+newSessionInfo 由会话记录构造会话信息
+
+参数：
+  - sessionID：会话 ID
+  - record：会话记录
+
+返回：
+  - models.SessionInfo：会话信息
+*/
+func newSessionInfo(sessionID string, record map[string]string) models.SessionInfo {
+	issuedAt, _ := strconv.ParseInt(record["issued_at"], 10, 64)
+	lastUsedAt, _ := strconv.ParseInt(record["last_used_at"], 10, 64)
+
+	return models.SessionInfo{
+		ID:          sessionID,
+		UID:         record["uid"],
+		JTI:         record["jti"],
+		IP:          record["ip"],
+		Location:    record["location"],
+		Device:      record["device"],
+		Application: record["application"],
+		IssuedAt:    time.Unix(issuedAt, 0),
+		LastUsedAt:  time.Unix(lastUsedAt, 0),
+	}
+}
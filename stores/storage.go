@@ -14,14 +14,19 @@ import (
 
 // Storage 存储对象
 type Storage struct {
-	redis       *redis.Client // redis 客户端
-	mongo       *mongo.Client // mongo 客户端
-	minio       *minio.Client // minio 客户端
-	AuthStorage *AuthStorage  // 认证相关存储
-	UserStorage *UserStorage  // 用户相关存储
-	// PostStore    *PostStore    // 文章相关存储
-	// CommentStore *CommentStore // 评论相关存储
-	// ReplyStore   *ReplyStore   // 回复相关存储
+	redis           *redis.Client    // redis 客户端
+	mongo           *mongo.Client    // mongo 客户端
+	minio           *minio.Client    // minio 客户端
+	AuthStorage     *AuthStorage     // 认证相关存储
+	UserStorage     *UserStorage     // 用户相关存储
+	PostStorage     *PostStorage     // 博文相关存储
+	CommentStorage  *CommentStorage  // 评论相关存储
+	ReplyStorage    *ReplyStorage    // 回复相关存储
+	MediaStorage    *MediaStorage    // 媒体文件相关存储
+	FollowStorage   *FollowStorage   // 关注关系相关存储
+	TimelineStorage *TimelineStorage // 时间线相关存储
+	AuditStorage    *AuditStorage    // 审计日志相关存储
+	ExportStorage   *ExportStorage   // 个人数据导出相关存储
 }
 
 /*
@@ -38,14 +43,19 @@ NewStore 新建存储
 func NewStore(redis *redis.Client, mongo *mongo.Client, mongoDBName string, minio *minio.Client) *Storage {
 	mongoDataBase := mongo.Database(mongoDBName)
 	return &Storage{
-		redis:       redis,
-		mongo:       mongo,
-		minio:       minio,
-		AuthStorage: &AuthStorage{redis, mongoDataBase},
-		UserStorage: &UserStorage{redis, mongoDataBase, minio},
-		// PostStore:    &PostStore{redis, mongoDataBase},
-		// CommentStore: &CommentStore{redis, mongoDataBase},
-		// ReplyStore:   &ReplyStore{redis, mongoDataBase},
+		redis:           redis,
+		mongo:           mongo,
+		minio:           minio,
+		AuthStorage:     &AuthStorage{redis, mongoDataBase},
+		UserStorage:     &UserStorage{redis, mongoDataBase, minio},
+		PostStorage:     &PostStorage{redis, mongoDataBase},
+		CommentStorage:  &CommentStorage{redis, mongoDataBase},
+		ReplyStorage:    &ReplyStorage{redis, mongoDataBase},
+		MediaStorage:    &MediaStorage{redis, mongoDataBase, minio},
+		FollowStorage:   &FollowStorage{redis, mongoDataBase},
+		TimelineStorage: &TimelineStorage{redis, mongoDataBase},
+		AuditStorage:    &AuditStorage{redis, mongoDataBase},
+		ExportStorage:   &ExportStorage{redis, mongoDataBase, minio},
 	}
 }
 
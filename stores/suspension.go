@@ -0,0 +1,141 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现账号停用相关存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+/*
+SuspendUser 暂停使用或封禁账号
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - until：暂停使用截止时间 封禁时忽略
+  - banned：是否永久封禁
+  - reason：原因
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SuspendUser(sessionContext mongo.SessionContext, userID primitive.ObjectID, until time.Time, banned bool, reason string) error {
+	update := bson.M{"$set": bson.M{"suspend_reason": reason}}
+	if banned {
+		update["$set"].(bson.M)["banned"] = true
+		update["$unset"] = bson.M{"suspended_until": ""}
+	} else {
+		update["$set"].(bson.M)["suspended_until"] = until
+		update["$unset"] = bson.M{"banned": ""}
+	}
+
+	_, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(sessionContext, bson.M{"_id": userID}, update)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+UnsuspendUser 解除账号的暂停使用或封禁
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) UnsuspendUser(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$unset": bson.M{"banned": "", "suspended_until": "", "suspend_reason": ""}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+SetSuspensionMarker 设置账号停用标记 供认证中间件拒绝已签发的令牌
+
+参数：
+  - userID：用户ID
+  - message：提示信息
+  - expiration：有效期 为 0 时永不过期
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SetSuspensionMarker(userID string, message string, expiration time.Duration) error {
+	err := store.redis.Set(
+		context.Background(), functools.JoinStrings(models.REDIS_ACCOUNT_SUSPENDED, ":", userID), message, expiration,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+DeleteSuspensionMarker 删除账号停用标记
+
+参数：
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) DeleteSuspensionMarker(userID string) error {
+	err := store.redis.Del(
+		context.Background(), functools.JoinStrings(models.REDIS_ACCOUNT_SUSPENDED, ":", userID),
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+CheckAccountSuspension 检查账号停用标记
+
+参数：
+  - userID：用户ID
+
+返回：
+  - error：账号已停用时返回 types.ErrAccountSuspended 错误
+*/
+func (store *AuthStorage) CheckAccountSuspension(userID string) error {
+	message, err := store.redis.Get(
+		context.Background(), functools.JoinStrings(models.REDIS_ACCOUNT_SUSPENDED, ":", userID),
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return types.NewError(types.ErrAccountSuspended, message)
+}
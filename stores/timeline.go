@@ -0,0 +1,283 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于声明时间线存储对象类
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+// TimelineStorage 时间线数据库
+type TimelineStorage struct {
+	redis *redis.Client
+	mongo *mongo.Database
+}
+
+/*
+timelineKey 获取用户首页时间线的键名
+
+参数：
+  - userID：用户ID
+
+返回：
+  - string：键名
+*/
+func timelineKey(userID primitive.ObjectID) string {
+	return functools.JoinStrings(models.REDIS_HOME_TIMELINE, ":", userID.Hex())
+}
+
+/*
+timelineBuiltKey 获取用户首页时间线已重建标记的键名
+
+参数：
+  - userID：用户ID
+
+返回：
+  - string：键名
+*/
+func timelineBuiltKey(userID primitive.ObjectID) string {
+	return functools.JoinStrings(models.REDIS_HOME_TIMELINE_BUILT, ":", userID.Hex())
+}
+
+// pushPostsScript 向已重建的时间线推送博文 并裁剪超出长度上限的旧博文 时间线未重建时不做修改
+// 未重建的时间线若写入推送的博文 读取时会被误认为已重建 从而丢失更早的博文
+//
+// KEYS[1]：时间线键 KEYS[2]：已重建标记键
+// ARGV[1]：长度上限 ARGV[2...]：博文ID列表
+var pushPostsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return 0
+end
+for i = 2, #ARGV do
+	redis.call('ZADD', KEYS[1], 0, ARGV[i])
+end
+redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -tonumber(ARGV[1]) - 1)
+local ttl = redis.call('TTL', KEYS[2])
+if ttl > 0 then
+	redis.call('EXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+/*
+ReplaceTimeline 以重建结果替换用户的时间线 并写入已重建标记
+
+参数：
+  - ctx：上下文
+  - userID：用户ID
+  - postIDs：博文ID列表
+
+返回：
+  - error：错误信息
+*/
+func (store *TimelineStorage) ReplaceTimeline(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) error {
+	key := timelineKey(userID)
+	expiration := consts.TIMELINE_EXPIRE_DURATION * time.Second
+
+	pipe := store.redis.TxPipeline()
+	pipe.Del(ctx, key)
+	if len(postIDs) > 0 {
+		members := make([]redis.Z, 0, len(postIDs))
+		for _, postID := range postIDs {
+			members = append(members, redis.Z{Score: 0, Member: postID.Hex()})
+		}
+		pipe.ZAdd(ctx, key, members...)
+		pipe.ZRemRangeByRank(ctx, key, 0, -consts.TIMELINE_MAX_LENGTH-1)
+		pipe.Expire(ctx, key, expiration)
+	}
+	pipe.Set(ctx, timelineBuiltKey(userID), 1, expiration)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+PushPosts 将博文推送到多个用户的时间线 并裁剪超出长度上限的旧博文 未重建的时间线将被跳过 读取时会完整重建
+
+时间线的分值均为 0 成员按字典序排列 而 ObjectID 的十六进制字典序与其生成顺序一致
+
+参数：
+  - ctx：上下文
+  - userIDs：接收推送的用户ID列表
+  - postIDs：博文ID列表
+
+返回：
+  - error：错误信息
+*/
+func (store *TimelineStorage) PushPosts(ctx context.Context, userIDs []primitive.ObjectID, postIDs []primitive.ObjectID) error {
+	if len(userIDs) == 0 || len(postIDs) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(postIDs)+1)
+	args = append(args, consts.TIMELINE_MAX_LENGTH)
+	for _, postID := range postIDs {
+		args = append(args, postID.Hex())
+	}
+
+	// 预先加载脚本 以便在管道中使用 EVALSHA
+	_, err := pushPostsScript.Load(ctx, store.redis).Result()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	pipe := store.redis.Pipeline()
+	for _, userID := range userIDs {
+		pushPostsScript.EvalSha(ctx, pipe, []string{timelineKey(userID), timelineBuiltKey(userID)}, args...)
+	}
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+RemovePosts 从用户的时间线中移除博文
+
+参数：
+  - ctx：上下文
+  - userID：用户ID
+  - postIDs：博文ID列表
+
+返回：
+  - error：错误信息
+*/
+func (store *TimelineStorage) RemovePosts(ctx context.Context, userID primitive.ObjectID, postIDs []primitive.ObjectID) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	members := make([]any, 0, len(postIDs))
+	for _, postID := range postIDs {
+		members = append(members, postID.Hex())
+	}
+
+	err := store.redis.ZRem(ctx, timelineKey(userID), members...).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetTimeline 分页获取用户时间线中的博文ID 按发布时间倒序
+
+参数：
+  - ctx：上下文
+  - userID：用户ID
+  - cursor：游标 即上一页最后一条博文的ID 为空时从头开始
+  - limit：数量
+
+返回：
+  - []primitive.ObjectID：博文ID列表
+  - error：错误信息
+*/
+func (store *TimelineStorage) GetTimeline(ctx context.Context, userID primitive.ObjectID, cursor primitive.ObjectID, limit int64) ([]primitive.ObjectID, error) {
+	max := "+"
+	if !cursor.IsZero() {
+		max = functools.JoinStrings("(", cursor.Hex())
+	}
+
+	members, err := store.redis.ZRevRangeByLex(ctx, timelineKey(userID), &redis.ZRangeBy{
+		Min:   "-",
+		Max:   max,
+		Count: limit,
+	}).Result()
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	postIDs := make([]primitive.ObjectID, 0, len(members))
+	for _, member := range members {
+		postID, err := primitive.ObjectIDFromHex(member)
+		if err != nil {
+			continue
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	return postIDs, nil
+}
+
+/*
+HasTimeline 判断用户的时间线缓存是否已重建
+
+参数：
+  - ctx：上下文
+  - userID：用户ID
+
+返回：
+  - bool：是否已重建
+  - error：错误信息
+*/
+func (store *TimelineStorage) HasTimeline(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	count, err := store.redis.Exists(ctx, timelineBuiltKey(userID)).Result()
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count > 0, nil
+}
+
+/*
+TouchTimeline 延长用户时间线缓存的有效期 并判断时间线缓存是否已重建
+
+参数：
+  - ctx：上下文
+  - userID：用户ID
+
+返回：
+  - bool：是否已重建
+  - error：错误信息
+*/
+func (store *TimelineStorage) TouchTimeline(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	expiration := consts.TIMELINE_EXPIRE_DURATION * time.Second
+
+	pipe := store.redis.TxPipeline()
+	built := pipe.Expire(ctx, timelineBuiltKey(userID), expiration)
+	pipe.Expire(ctx, timelineKey(userID), expiration)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return built.Val(), nil
+}
+
+/*
+DeleteTimeline 删除用户的首页时间线
+
+参数：
+  - ctx：上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *TimelineStorage) DeleteTimeline(ctx context.Context, userID primitive.ObjectID) error {
+	err := store.redis.Del(ctx, timelineKey(userID), timelineBuiltKey(userID)).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现个人访问令牌存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+/*
+CreatePersonalAccessToken 保存个人访问令牌
+
+参数：
+  - sessionContext：数据库会话上下文
+  - tokenInfo：个人访问令牌信息
+
+返回：
+  - primitive.ObjectID：令牌ID
+  - error：错误信息
+*/
+func (store *AuthStorage) CreatePersonalAccessToken(sessionContext mongo.SessionContext, tokenInfo models.PersonalAccessTokenInfo) (primitive.ObjectID, error) {
+	result, err := store.mongo.Collection(models.PERSONAL_ACCESS_TOKEN_COLLECTION).InsertOne(sessionContext, tokenInfo)
+	if err != nil {
+		return primitive.NilObjectID, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+/*
+CountPersonalAccessTokens 统计用户持有的个人访问令牌数量
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - int64：令牌数量
+  - error：错误信息
+*/
+func (store *AuthStorage) CountPersonalAccessTokens(sessionContext mongo.SessionContext, userID primitive.ObjectID) (int64, error) {
+	count, err := store.mongo.Collection(models.PERSONAL_ACCESS_TOKEN_COLLECTION).CountDocuments(sessionContext, bson.M{"uid": userID})
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+/*
+GetPersonalAccessTokens 获取用户的全部个人访问令牌 按创建时间倒序
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - []models.PersonalAccessTokenInfo：令牌列表
+  - error：错误信息
+*/
+func (store *AuthStorage) GetPersonalAccessTokens(sessionContext mongo.SessionContext, userID primitive.ObjectID) ([]models.PersonalAccessTokenInfo, error) {
+	result, err := store.mongo.Collection(models.PERSONAL_ACCESS_TOKEN_COLLECTION).Find(
+		sessionContext,
+		bson.M{"uid": userID},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	tokens := []models.PersonalAccessTokenInfo{}
+	err = result.All(sessionContext, &tokens)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return tokens, nil
+}
+
+/*
+GetPersonalAccessTokenByHash 通过令牌摘要获取个人访问令牌
+
+参数：
+  - sessionContext：数据库会话上下文
+  - tokenHash：令牌摘要
+
+返回：
+  - models.PersonalAccessTokenInfo：令牌信息
+  - error：错误信息
+*/
+func (store *AuthStorage) GetPersonalAccessTokenByHash(sessionContext mongo.SessionContext, tokenHash string) (models.PersonalAccessTokenInfo, error) {
+	tokenInfo := models.PersonalAccessTokenInfo{}
+	err := store.mongo.Collection(models.PERSONAL_ACCESS_TOKEN_COLLECTION).FindOne(
+		sessionContext, bson.M{"token_hash": tokenHash},
+	).Decode(&tokenInfo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return tokenInfo, types.NewError(types.ErrAuthFailed, "bearer token 无效")
+		}
+		return tokenInfo, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return tokenInfo, nil
+}
+
+/*
+DeletePersonalAccessToken 删除用户的个人访问令牌
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - tokenID：令牌ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) DeletePersonalAccessToken(sessionContext mongo.SessionContext, userID primitive.ObjectID, tokenID primitive.ObjectID) error {
+	result, err := store.mongo.Collection(models.PERSONAL_ACCESS_TOKEN_COLLECTION).DeleteOne(
+		sessionContext, bson.M{"_id": tokenID, "uid": userID},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	if result.DeletedCount == 0 {
+		return types.NewError(types.ErrInvalidParams, "令牌不存在")
+	}
+
+	return nil
+}
+
+/*
+DeleteAllPersonalAccessTokens 删除用户的全部个人访问令牌
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) DeleteAllPersonalAccessTokens(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.PERSONAL_ACCESS_TOKEN_COLLECTION).DeleteMany(sessionContext, bson.M{"uid": userID})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+TouchPersonalAccessToken 按间隔更新个人访问令牌的最后使用时间
+
+参数：
+  - sessionContext：数据库会话上下文
+  - tokenID：令牌ID
+  - now：当前时间
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) TouchPersonalAccessToken(sessionContext mongo.SessionContext, tokenID primitive.ObjectID, now time.Time) error {
+	_, err := store.mongo.Collection(models.PERSONAL_ACCESS_TOKEN_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{
+			"_id": tokenID,
+			"$or": bson.A{
+				bson.M{"last_used_at": bson.M{"$exists": false}},
+				bson.M{"last_used_at": bson.M{"$lt": now.Add(-consts.SESSION_TOUCH_INTERVAL * time.Second)}},
+			},
+		},
+		bson.M{"$set": bson.M{"last_used_at": now}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
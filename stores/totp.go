@@ -0,0 +1,316 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现两步验证相关存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+	"zewise.space/backend/utils/functools"
+)
+
+/*
+EnableTOTP 开启两步验证 并保存 TOTP 密钥与恢复码摘要
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户 ID
+  - secret：TOTP 密钥
+  - recoveryCodes：恢复码摘要列表
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) EnableTOTP(sessionContext mongo.SessionContext, userID primitive.ObjectID, secret string, recoveryCodes []string) error {
+	result, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID, "totp_enabled": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{
+			"totp_secret":    secret,
+			"totp_enabled":   true,
+			"recovery_codes": recoveryCodes,
+		}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	if result.MatchedCount == 0 {
+		return types.NewError(types.ErrInvalidParams, "两步验证已开启")
+	}
+
+	return nil
+}
+
+/*
+DisableTOTP 关闭两步验证 并清除 TOTP 密钥与恢复码
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户 ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) DisableTOTP(sessionContext mongo.SessionContext, userID primitive.ObjectID) error {
+	_, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$unset": bson.M{
+			"totp_secret":    "",
+			"totp_enabled":   "",
+			"recovery_codes": "",
+		}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+ReplaceRecoveryCodes 替换用户的全部恢复码
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户 ID
+  - recoveryCodes：恢复码摘要列表
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) ReplaceRecoveryCodes(sessionContext mongo.SessionContext, userID primitive.ObjectID, recoveryCodes []string) error {
+	_, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID, "totp_enabled": true},
+		bson.M{"$set": bson.M{"recovery_codes": recoveryCodes}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+ConsumeRecoveryCode 使用恢复码 恢复码仅可使用一次
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户 ID
+  - recoveryCode：恢复码摘要
+
+返回：
+  - bool：恢复码是否有效
+  - error：错误信息
+*/
+func (store *AuthStorage) ConsumeRecoveryCode(sessionContext mongo.SessionContext, userID primitive.ObjectID, recoveryCode string) (bool, error) {
+	result, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID, "recovery_codes": recoveryCode},
+		bson.M{"$pull": bson.M{"recovery_codes": recoveryCode}},
+	)
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+/*
+SaveTOTPSetup 保存待确认的 TOTP 密钥
+
+参数：
+  - userID：用户 ID
+  - secret：TOTP 密钥
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SaveTOTPSetup(userID string, secret string) error {
+	err := store.redis.Set(
+		context.Background(),
+		functools.JoinStrings(models.REDIS_TOTP_SETUP, ":", userID),
+		secret,
+		consts.TOTP_SETUP_EXPIRE_DURATION*time.Second,
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetTOTPSetup 获取待确认的 TOTP 密钥
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - string：TOTP 密钥
+  - error：错误信息
+*/
+func (store *AuthStorage) GetTOTPSetup(userID string) (string, error) {
+	secret, err := store.redis.Get(
+		context.Background(), functools.JoinStrings(models.REDIS_TOTP_SETUP, ":", userID),
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", types.NewError(types.ErrInvalidParams, "两步验证设置已过期 请重新开始")
+		}
+		return "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return secret, nil
+}
+
+/*
+DeleteTOTPSetup 删除待确认的 TOTP 密钥
+
+参数：
+  - userID：用户 ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) DeleteTOTPSetup(userID string) error {
+	err := store.redis.Del(
+		context.Background(), functools.JoinStrings(models.REDIS_TOTP_SETUP, ":", userID),
+	).Err()
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+MarkTOTPStepUsed 标记 TOTP 时间步已使用 同一验证码不可重复使用
+
+参数：
+  - userID：用户 ID
+  - step：时间步
+
+返回：
+  - bool：是否首次使用
+  - error：错误信息
+*/
+func (store *AuthStorage) MarkTOTPStepUsed(userID string, step int64) (bool, error) {
+	ok, err := store.redis.SetNX(
+		context.Background(),
+		functools.JoinStrings(models.REDIS_TOTP_USED_STEP, ":", userID, ":", fmt.Sprint(step)),
+		1,
+		(2*consts.TOTP_SKEW+1)*consts.TOTP_PERIOD*time.Second,
+	).Result()
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return ok, nil
+}
+
+/*
+SaveLoginChallenge 保存两步验证挑战
+
+参数：
+  - token：挑战令牌
+  - userID：用户 ID
+
+返回：
+  - error：错误信息
+*/
+func (store *AuthStorage) SaveLoginChallenge(token string, userID string) error {
+	ctx := context.Background()
+	key := functools.JoinStrings(models.REDIS_TOTP_CHALLENGE, ":", token)
+
+	pipe := store.redis.TxPipeline()
+	pipe.HSet(ctx, key, "uid", userID, "attempts", 0)
+	pipe.Expire(ctx, key, consts.TOTP_CHALLENGE_EXPIRE_DURATION*time.Second)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetLoginChallenge 获取两步验证挑战对应的用户
+
+参数：
+  - token：挑战令牌
+
+返回：
+  - string：用户 ID
+  - error：错误信息
+*/
+func (store *AuthStorage) GetLoginChallenge(token string) (string, error) {
+	userID, err := store.redis.HGet(
+		context.Background(), functools.JoinStrings(models.REDIS_TOTP_CHALLENGE, ":", token), "uid",
+	).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", types.NewError(types.ErrAuthFailed, "两步验证已过期 请重新登录")
+		}
+		return "", types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return userID, nil
+}
+
+/*
+RecordChallengeFailure 累加两步验证挑战的错误次数
+
+参数：
+  - token：挑战令牌
+
+返回：
+  - int64：当前错误次数
+  - error：错误信息
+*/
+func (store *AuthStorage) RecordChallengeFailure(token string) (int64, error) {
+	count, err := store.redis.HIncrBy(
+		context.Background(), functools.JoinStrings(models.REDIS_TOTP_CHALLENGE, ":", token), "attempts", 1,
+	).Result()
+	if err != nil {
+		return 0, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+/*
+DeleteLoginChallenge 删除两步验证挑战
+
+参数：
+  - token：挑战令牌
+
+返回：
+  - bool：挑战是否存在 并发完成验证时仅有一方返回 true
+  - error：错误信息
+*/
+func (store *AuthStorage) DeleteLoginChallenge(token string) (bool, error) {
+	count, err := store.redis.Del(
+		context.Background(), functools.JoinStrings(models.REDIS_TOTP_CHALLENGE, ":", token),
+	).Result()
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count > 0, nil
+}
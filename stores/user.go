@@ -15,6 +15,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/redis/go-redis/v9"
@@ -31,7 +32,7 @@ type UserStorage struct {
 }
 
 /*
-CheckUserExistance 检查用户是否存在
+CheckUserExistance 检查用户是否存在 并发注册时由唯一索引兜底 此处仅用于提前返回
 
 参数：
   - sessionContext：数据库会话上下文
@@ -65,24 +66,30 @@ RegisterUser 注册用户
 参数：
   - sessionContext：数据库会话上下文
   - username：用户名
-  - salt：盐
+  - email：邮箱
   - hashedPassword：哈希密码
+  - invite：注册时使用的邀请码 未使用邀请码时为空
 
 返回：
   - error：错误信息
 */
-func (store *UserStorage) RegisterUser(sessionContext mongo.SessionContext, username string, email string, salt string, hashedPassword string) error {
+func (store *UserStorage) RegisterUser(sessionContext mongo.SessionContext, username string, email string, hashedPassword string, invite models.InviteCode) error {
 	// 插入用户信息
 	user := models.UserInfo{
-		UserName:  username,
-		NickName:  username,
-		Email:     email,
-		Avatar:    "vanilla",
-		Sign:      "这个人很懒，什么都没有留下。",
-		Authority: 0,
-		Level:     1,
+		UserName:     username,
+		NickName:     username,
+		Email:        email,
+		Avatar:       "vanilla",
+		Sign:         "这个人很懒，什么都没有留下。",
+		Authority:    0,
+		Level:        1,
+		InvitedBy:    invite.CreatorID,
+		InviteCodeID: invite.ID,
 	}
 	result, err := store.mongo.Collection(models.USER_INFO_COLLECTION).InsertOne(sessionContext, user)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.NewError(types.ErrInvalidParams, "用户名或邮箱已被注册")
+	}
 	if err != nil {
 		return types.NewError(types.ErrServerError, err.Error())
 	}
@@ -95,10 +102,12 @@ func (store *UserStorage) RegisterUser(sessionContext mongo.SessionContext, user
 		ID:           userID,
 		UserName:     username,
 		Email:        email,
-		Salt:         salt,
 		PasswordHash: hashedPassword,
 	}
 	_, err = store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).InsertOne(sessionContext, userAuthInfo)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.NewError(types.ErrInvalidParams, "用户名或邮箱已被注册")
+	}
 	if err != nil {
 		return types.NewError(types.ErrServerError, err.Error())
 	}
@@ -224,6 +233,21 @@ func (store *UserStorage) UploadAvatarFile(ctx context.Context, fileName string,
 	return info, nil
 }
 
+/*
+GetAvatarFile 获取用户头像文件
+
+参数：
+  - ctx 上下文
+  - fileName 文件名
+
+返回：
+  - *minio.Object：头像文件 使用后需关闭
+  - error：错误信息
+*/
+func (store *UserStorage) GetAvatarFile(ctx context.Context, fileName string) (*minio.Object, error) {
+	return store.minio.GetObject(ctx, models.USER_AVATAR_BUCKET, fileName, minio.GetObjectOptions{})
+}
+
 /*
 DeleteAvatarFile 删除用户头像文件
 
@@ -250,15 +274,163 @@ UpdateUserPassword 更新用户密码
   - error：错误信息
 */
 func (store *UserStorage) UpdateUserPassword(sessionContext mongo.SessionContext, userID primitive.ObjectID, hashedPassword string) error {
-	authInfo := models.UserAuthInfo{PasswordHash: hashedPassword}
 	_, err := store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
 		sessionContext,
 		bson.M{"_id": userID},
-		authInfo,
+		bson.M{"$set": bson.M{"psw_hash": hashedPassword}, "$unset": bson.M{"salt": ""}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+UpdateUserAuthority 更新用户角色
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - authority：角色
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) UpdateUserAuthority(sessionContext mongo.SessionContext, userID primitive.ObjectID, authority uint64) error {
+	_, err := store.mongo.Collection(models.USER_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"authority": authority}},
+	)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+GetUsersByIDs 批量获取用户信息
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userIDs：用户ID列表
+
+返回：
+  - []models.UserInfo：用户信息列表
+  - error：错误信息
+*/
+func (store *UserStorage) GetUsersByIDs(sessionContext mongo.SessionContext, userIDs []primitive.ObjectID) ([]models.UserInfo, error) {
+	users := []models.UserInfo{}
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+
+	result, err := store.mongo.Collection(models.USER_INFO_COLLECTION).Find(sessionContext, bson.M{"_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	err = result.All(sessionContext, &users)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return users, nil
+}
+
+/*
+IncreaseFollowCounts 更新关注双方的关注数与粉丝数
+
+参数：
+  - sessionContext：数据库会话上下文
+  - followerID：关注者ID
+  - followeeID：被关注者ID
+  - delta：增量
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) IncreaseFollowCounts(sessionContext mongo.SessionContext, followerID primitive.ObjectID, followeeID primitive.ObjectID, delta int64) error {
+	collection := store.mongo.Collection(models.USER_INFO_COLLECTION)
+
+	_, err := collection.UpdateOne(sessionContext, bson.M{"_id": followerID}, bson.M{"$inc": bson.M{"following_count": delta}})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+	_, err = collection.UpdateOne(sessionContext, bson.M{"_id": followeeID}, bson.M{"$inc": bson.M{"follower_count": delta}})
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+FilterPopularUsers 从给定用户中筛选出粉丝数不低于阈值的用户
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userIDs：用户ID列表
+  - threshold：粉丝数阈值
+
+返回：
+  - []primitive.ObjectID：符合条件的用户ID列表
+  - error：错误信息
+*/
+func (store *UserStorage) FilterPopularUsers(sessionContext mongo.SessionContext, userIDs []primitive.ObjectID, threshold int64) ([]primitive.ObjectID, error) {
+	ids := []primitive.ObjectID{}
+	if len(userIDs) == 0 {
+		return ids, nil
+	}
+
+	result, err := store.mongo.Collection(models.USER_INFO_COLLECTION).Find(
+		sessionContext,
+		bson.M{
+			"_id":            bson.M{"$in": userIDs},
+			"follower_count": bson.M{"$gte": threshold},
+		},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	users := []models.UserInfo{}
+	err = result.All(sessionContext, &users)
+	if err != nil {
+		return nil, types.NewError(types.ErrServerError, err.Error())
+	}
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+
+	return ids, nil
+}
+
+/*
+SetEmailVerified 将用户邮箱标记为已验证 仅当用户当前邮箱与待验证邮箱一致时生效
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - email：待验证的邮箱
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) SetEmailVerified(sessionContext mongo.SessionContext, userID primitive.ObjectID, email string) error {
+	result, err := store.mongo.Collection(models.USER_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID, "email": email},
+		bson.M{"$set": bson.M{"email_verified": true}},
 	)
 	if err != nil {
 		return types.NewError(types.ErrServerError, err.Error())
 	}
+	if result.MatchedCount == 0 {
+		return types.NewError(types.ErrInvalidParams, "邮箱已变更 请重新验证")
+	}
 
 	return nil
 }
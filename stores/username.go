@@ -0,0 +1,135 @@
+/*
+Package stores - ZeWise 后端服务器数据访问层
+该文件用于实现修改用户名相关存储
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package stores
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"zewise.space/backend/models"
+	"zewise.space/backend/types"
+)
+
+/*
+ChangeUsername 同时修改用户信息与认证信息中的用户名
+
+参数：
+  - sessionContext：数据库会话上下文
+  - userID：用户ID
+  - username：新用户名
+  - changedAt：修改时间
+
+返回：
+  - error：错误信息 用户名已被占用时返回 types.ErrInvalidParams 错误
+*/
+func (store *UserStorage) ChangeUsername(sessionContext mongo.SessionContext, userID primitive.ObjectID, username string, changedAt time.Time) error {
+	_, err := store.mongo.Collection(models.USER_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"username": username, "username_changed_at": changedAt}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.NewError(types.ErrInvalidParams, "用户名已被占用")
+	}
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	_, err = store.mongo.Collection(models.USER_AUTH_INFO_COLLECTION).UpdateOne(
+		sessionContext,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"username": username}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return types.NewError(types.ErrInvalidParams, "用户名已被占用")
+	}
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+CreateUsernameHistory 保存用户名修改记录
+
+参数：
+  - sessionContext：数据库会话上下文
+  - history：修改记录
+
+返回：
+  - error：错误信息
+*/
+func (store *UserStorage) CreateUsernameHistory(sessionContext mongo.SessionContext, history models.UsernameHistory) error {
+	_, err := store.mongo.Collection(models.USERNAME_HISTORY_COLLECTION).InsertOne(sessionContext, history)
+	if err != nil {
+		return types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return nil
+}
+
+/*
+IsUsernameReserved 检查用户名是否作为他人的旧用户名处于保留期内
+
+参数：
+  - sessionContext：数据库会话上下文
+  - username：用户名
+  - userID：当前用户ID 本人的旧用户名不视为保留 注册时为空
+  - now：当前时间
+
+返回：
+  - bool：是否保留
+  - error：错误信息
+*/
+func (store *UserStorage) IsUsernameReserved(sessionContext mongo.SessionContext, username string, userID primitive.ObjectID, now time.Time) (bool, error) {
+	count, err := store.mongo.Collection(models.USERNAME_HISTORY_COLLECTION).CountDocuments(sessionContext, bson.M{
+		"old_name":       username,
+		"uid":            bson.M{"$ne": userID},
+		"reserved_until": bson.M{"$gt": now},
+	})
+	if err != nil {
+		return false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return count > 0, nil
+}
+
+/*
+GetUsernameRedirect 获取旧用户名当前跳转到的用户
+
+参数：
+  - sessionContext：数据库会话上下文
+  - username：旧用户名
+  - now：当前时间
+
+返回：
+  - primitive.ObjectID：用户ID
+  - bool：是否存在有效的跳转
+  - error：错误信息
+*/
+func (store *UserStorage) GetUsernameRedirect(sessionContext mongo.SessionContext, username string, now time.Time) (primitive.ObjectID, bool, error) {
+	var history models.UsernameHistory
+	err := store.mongo.Collection(models.USERNAME_HISTORY_COLLECTION).FindOne(
+		sessionContext,
+		bson.M{"old_name": username, "redirect_until": bson.M{"$gt": now}},
+		options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}}),
+	).Decode(&history)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, false, nil
+	}
+	if err != nil {
+		return primitive.NilObjectID, false, types.NewError(types.ErrServerError, err.Error())
+	}
+
+	return history.UID, true, nil
+}
@@ -24,6 +24,12 @@ var (
 	// ErrAuthFailed 认证错误
 	ErrAuthFailed ErrorType = errors.New("AuthFailed")
 
+	// ErrAccountSuspended 账号已被暂停使用或封禁
+	ErrAccountSuspended ErrorType = errors.New("AccountSuspended")
+
+	// ErrCaptchaRequired 需要通过图片验证码
+	ErrCaptchaRequired ErrorType = errors.New("CaptchaRequired")
+
 	// ErrNetworkError 网络错误
 	ErrNetworkError ErrorType = errors.New("NetworkError")
 
@@ -1,56 +1,197 @@
 /*
 Package encryptors - ZeWise 后端服务器加密器包
 该文件用于实现密码加密器
+密码哈希以 PHC 字符串格式保存 新哈希使用 argon2id 旧版 bcrypt 哈希仍可验证
 Copyright (c) [2024], Author(s):
 - WhitePaper233<baizhiwp@gmail.com>
 */
 package encryptors
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"zewise.space/backend/consts"
+)
+
+var (
+	// ErrPasswordMismatch 密码错误
+	ErrPasswordMismatch = errors.New("密码错误")
+	// ErrUnsupportedPasswordHash 不支持的密码哈希格式
+	ErrUnsupportedPasswordHash = errors.New("不支持的密码哈希格式")
+)
+
+// Argon2Params argon2id 参数
+type Argon2Params struct {
+	Memory      uint32 // 内存开销（KiB）
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+}
+
+// passwordHashParams 当前使用的 argon2id 参数
+var passwordHashParams = Argon2Params{
+	Memory:      consts.ARGON2_MEMORY,
+	Iterations:  consts.ARGON2_ITERATIONS,
+	Parallelism: consts.ARGON2_PARALLELISM,
+}
+
+/*
+Valid 判断 argon2id 参数是否在允许范围内
+
+返回：
+  - bool：参数是否有效
+*/
+func (params Argon2Params) Valid() bool {
+	return params.Memory >= 8*uint32(params.Parallelism) &&
+		params.Memory <= consts.ARGON2_MAX_MEMORY &&
+		params.Iterations >= 1 &&
+		params.Iterations <= consts.ARGON2_MAX_ITERATIONS &&
+		params.Parallelism >= 1 &&
+		params.Parallelism <= consts.ARGON2_MAX_PARALLELISM
+}
 
 /*
-HashPassword 哈希密码
+SetPasswordHashParams 设置新哈希使用的 argon2id 参数 参数变化后旧哈希会在登录时重新计算
+
+参数：
+  - params：argon2id 参数
+
+返回：
+  - error：参数超出允许范围时返回错误
+*/
+func SetPasswordHashParams(params Argon2Params) error {
+	if !params.Valid() {
+		return fmt.Errorf(
+			"argon2id 参数超出允许范围: m=%d,t=%d,p=%d",
+			params.Memory,
+			params.Iterations,
+			params.Parallelism,
+		)
+	}
+	passwordHashParams = params
+	return nil
+}
+
+/*
+HashPassword 使用 argon2id 哈希密码
 
 参数：
   - password：密码
-  - salt：盐
 
 返回：
-  - string：哈希密码
+  - string：PHC 格式的哈希密码
   - error：错误信息
 */
-func HashPassword(password string, salt string) (string, error) {
-	// 将密码和盐拼接在一起
-	passwordWithSalt := append([]byte(password), []byte(salt)...)
-
-	// 生成哈希密码
-	hashedPassword, err := bcrypt.GenerateFromPassword(
-		passwordWithSalt,
-		bcrypt.DefaultCost,
-	)
+func HashPassword(password string) (string, error) {
+	// 生成盐
+	salt := make([]byte, consts.ARGON2_SALT_LENGTH)
+	_, err := rand.Read(salt)
 	if err != nil {
 		return "", err
 	}
 
-	// 返回哈希密码
-	return string(hashedPassword), nil
+	// 生成哈希密码
+	params := passwordHashParams
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, consts.ARGON2_KEY_LENGTH)
+
+	// 编码为 PHC 字符串
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
 /*
-CompareHashPassword 比较哈希密码
+VerifyPassword 校验密码 并判断哈希是否需要使用当前算法与参数重新计算
 
 参数：
   - hashedPassword：哈希密码
   - password：密码
-  - salt：盐
+  - legacySalt：旧版 bcrypt 哈希使用的盐 argon2id 哈希忽略该参数
+
+返回：
+  - bool：是否需要重新哈希
+  - error：错误信息 密码错误时返回 ErrPasswordMismatch
+*/
+func VerifyPassword(hashedPassword string, password string, legacySalt string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hashedPassword, "$argon2id$"):
+		return verifyArgon2Password(hashedPassword, password)
+
+	case strings.HasPrefix(hashedPassword, "$2a$"),
+		strings.HasPrefix(hashedPassword, "$2b$"),
+		strings.HasPrefix(hashedPassword, "$2y$"):
+		// 旧版哈希将密码与盐拼接后使用 bcrypt
+		passwordWithSalt := append([]byte(password), []byte(legacySalt)...)
+		err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), passwordWithSalt)
+		if err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, ErrPasswordMismatch
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, ErrUnsupportedPasswordHash
+}
+
+/*
+verifyArgon2Password 校验 argon2id 哈希密码
+
+参数：
+  - hashedPassword：PHC 格式的哈希密码
+  - password：密码
 
 返回：
+  - bool：参数与当前参数不一致 需要重新哈希
   - error：错误信息
 */
-func CompareHashPassword(hashedPassword string, password string, salt string) error {
-	// 将密码和盐拼接在一起
-	passwordWithSalt := append([]byte(password), []byte(salt)...)
+func verifyArgon2Password(hashedPassword string, password string) (bool, error) {
+	// 格式：$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return false, ErrUnsupportedPasswordHash
+	}
+
+	var version int
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return false, ErrUnsupportedPasswordHash
+	}
+
+	var params Argon2Params
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil || !params.Valid() {
+		return false, ErrUnsupportedPasswordHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrUnsupportedPasswordHash
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(expected) == 0 || len(expected) > consts.ARGON2_MAX_KEY_LENGTH {
+		return false, ErrUnsupportedPasswordHash
+	}
+
+	// 计算并比较哈希
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(expected)))
+	if subtle.ConstantTimeCompare(key, expected) != 1 {
+		return false, ErrPasswordMismatch
+	}
 
-	// 比较哈希密码
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), passwordWithSalt)
+	return params != passwordHashParams, nil
 }
@@ -0,0 +1,200 @@
+/*
+Package encryptors - ZeWise 后端服务器加密器包
+该文件用于测试密码加密器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package encryptors
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"zewise.space/backend/consts"
+)
+
+/*
+useTestHashParams 使用低开销的 argon2id 参数 测试结束后恢复原参数
+
+参数：
+  - t：测试对象
+
+返回：
+  - Argon2Params：测试使用的参数
+*/
+func useTestHashParams(t *testing.T) Argon2Params {
+	t.Helper()
+
+	previous := passwordHashParams
+	params := Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1}
+	if err := SetPasswordHashParams(params); err != nil {
+		t.Fatalf("SetPasswordHashParams() error = %v", err)
+	}
+	t.Cleanup(func() { passwordHashParams = previous })
+
+	return params
+}
+
+/*
+buildPHC 按指定参数构造 PHC 格式的 argon2id 哈希
+
+参数：
+  - password：密码
+  - paramsField：参数段 如 m=64,t=1,p=1
+  - params：计算哈希使用的参数
+  - keyLength：哈希长度
+
+返回：
+  - string：PHC 格式的哈希密码
+*/
+func buildPHC(password string, paramsField string, params Argon2Params, keyLength uint32) string {
+	salt := []byte("0123456789abcdef")
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, keyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$%s$%s$%s",
+		argon2.Version,
+		paramsField,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	useTestHashParams(t)
+
+	hashed, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	needsRehash, err := VerifyPassword(hashed, "correct horse", "")
+	if err != nil || needsRehash {
+		t.Fatalf("VerifyPassword() = %v, %v, want false, nil", needsRehash, err)
+	}
+
+	_, err = VerifyPassword(hashed, "wrong horse", "")
+	if !errors.Is(err, ErrPasswordMismatch) {
+		t.Fatalf("VerifyPassword() error = %v, want ErrPasswordMismatch", err)
+	}
+}
+
+func TestVerifyArgon2Password(t *testing.T) {
+	current := useTestHashParams(t)
+	older := Argon2Params{Memory: 64, Iterations: 2, Parallelism: 1}
+	valid := buildPHC("secret", "m=64,t=1,p=1", current, consts.ARGON2_KEY_LENGTH)
+
+	tests := []struct {
+		name       string
+		hashed     string
+		password   string
+		wantRehash bool
+		wantErr    error
+	}{
+		{"current params", valid, "secret", false, nil},
+		{"wrong password", valid, "public", false, ErrPasswordMismatch},
+		{"older params need rehash", buildPHC("secret", "m=64,t=2,p=1", older, consts.ARGON2_KEY_LENGTH), "secret", true, nil},
+		{"missing segment", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", "secret", false, ErrUnsupportedPasswordHash},
+		{"unknown version", strings.Replace(valid, fmt.Sprintf("v=%d", argon2.Version), "v=16", 1), "secret", false, ErrUnsupportedPasswordHash},
+		{"malformed params", buildPHC("secret", "m=x,t=1,p=1", current, 32), "secret", false, ErrUnsupportedPasswordHash},
+		{"zero parallelism", buildPHC("secret", "m=64,t=1,p=0", current, 32), "secret", false, ErrUnsupportedPasswordHash},
+		{"zero iterations", buildPHC("secret", "m=64,t=0,p=1", current, 32), "secret", false, ErrUnsupportedPasswordHash},
+		{"memory below parallelism", buildPHC("secret", "m=8,t=1,p=2", current, 32), "secret", false, ErrUnsupportedPasswordHash},
+		{"memory above cap", buildPHC("secret", fmt.Sprintf("m=%d,t=1,p=1", consts.ARGON2_MAX_MEMORY+1), current, 32), "secret", false, ErrUnsupportedPasswordHash},
+		{"iterations above cap", buildPHC("secret", fmt.Sprintf("m=64,t=%d,p=1", consts.ARGON2_MAX_ITERATIONS+1), current, 32), "secret", false, ErrUnsupportedPasswordHash},
+		{"empty key", "$argon2id$v=19$m=64,t=1,p=1$MDEyMzQ1Njc4OWFiY2RlZg$", "secret", false, ErrUnsupportedPasswordHash},
+		{"key above cap", buildPHC("secret", "m=64,t=1,p=1", current, consts.ARGON2_MAX_KEY_LENGTH+1), "secret", false, ErrUnsupportedPasswordHash},
+		{"invalid salt encoding", "$argon2id$v=19$m=64,t=1,p=1$!!!$MDEyMzQ1Njc4OWFiY2RlZg", "secret", false, ErrUnsupportedPasswordHash},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			needsRehash, err := VerifyPassword(test.hashed, test.password, "")
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("VerifyPassword() error = %v, want %v", err, test.wantErr)
+			}
+			if needsRehash != test.wantRehash {
+				t.Fatalf("VerifyPassword() needsRehash = %v, want %v", needsRehash, test.wantRehash)
+			}
+		})
+	}
+}
+
+func TestVerifyLegacyBcryptPassword(t *testing.T) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte("secret"+"legacy-salt"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		hashed     string
+		password   string
+		salt       string
+		wantRehash bool
+		wantErr    error
+	}{
+		{"password with salt", string(hashed), "secret", "legacy-salt", true, nil},
+		{"wrong password", string(hashed), "public", "legacy-salt", false, ErrPasswordMismatch},
+		{"wrong salt", string(hashed), "secret", "other-salt", false, ErrPasswordMismatch},
+		{"2b prefix", "$2b$" + string(hashed)[4:], "secret", "legacy-salt", true, nil},
+		{"unknown scheme", "$1$abc$def", "secret", "legacy-salt", false, ErrUnsupportedPasswordHash},
+		{"empty hash", "", "secret", "legacy-salt", false, ErrUnsupportedPasswordHash},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			needsRehash, err := VerifyPassword(test.hashed, test.password, test.salt)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("VerifyPassword() error = %v, want %v", err, test.wantErr)
+			}
+			if needsRehash != test.wantRehash {
+				t.Fatalf("VerifyPassword() needsRehash = %v, want %v", needsRehash, test.wantRehash)
+			}
+		})
+	}
+}
+
+func TestSetPasswordHashParams(t *testing.T) {
+	current := useTestHashParams(t)
+
+	tests := []struct {
+		name    string
+		params  Argon2Params
+		wantErr bool
+	}{
+		{"defaults", Argon2Params{Memory: consts.ARGON2_MEMORY, Iterations: consts.ARGON2_ITERATIONS, Parallelism: consts.ARGON2_PARALLELISM}, false},
+		{"upper bounds", Argon2Params{Memory: consts.ARGON2_MAX_MEMORY, Iterations: consts.ARGON2_MAX_ITERATIONS, Parallelism: consts.ARGON2_MAX_PARALLELISM}, false},
+		{"zero memory", Argon2Params{Memory: 0, Iterations: 1, Parallelism: 1}, true},
+		{"zero iterations", Argon2Params{Memory: 64, Iterations: 0, Parallelism: 1}, true},
+		{"zero parallelism", Argon2Params{Memory: 64, Iterations: 1, Parallelism: 0}, true},
+		{"memory above cap", Argon2Params{Memory: consts.ARGON2_MAX_MEMORY + 1, Iterations: 1, Parallelism: 1}, true},
+		{"parallelism above cap", Argon2Params{Memory: 1024, Iterations: 1, Parallelism: consts.ARGON2_MAX_PARALLELISM + 1}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			passwordHashParams = current
+
+			err := SetPasswordHashParams(test.params)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("SetPasswordHashParams() error = %v, wantErr %v", err, test.wantErr)
+			}
+
+			// 参数不合法时保留原参数
+			want := test.params
+			if test.wantErr {
+				want = current
+			}
+			if passwordHashParams != want {
+				t.Fatalf("passwordHashParams = %+v, want %+v", passwordHashParams, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+/*
+Package encryptors - ZeWise 后端服务器加密器包
+该文件用于实现令牌摘要
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package encryptors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+/*
+HashToken 计算令牌摘要 用于存储不透明令牌而不保存其明文
+
+参数：
+  - token：令牌
+
+返回：
+  - string：十六进制编码的 SHA-256 摘要
+*/
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+/*
+Package encryptors - ZeWise 后端服务器加密器包
+该文件用于实现 TOTP 验证码计算 参见 RFC 6238
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package encryptors
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"zewise.space/backend/consts"
+)
+
+/*
+GenerateTOTPCode 计算指定时间步的 TOTP 验证码
+
+参数：
+  - secret：base32 编码的密钥
+  - step：时间步
+
+返回：
+  - string：验证码
+  - error：错误信息
+*/
+func GenerateTOTPCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	// 计算 HMAC-SHA1
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	// 动态截断
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulo := uint32(1)
+	for i := 0; i < consts.TOTP_DIGITS; i++ {
+		modulo *= 10
+	}
+
+	return fmt.Sprintf("%0*d", consts.TOTP_DIGITS, value%modulo), nil
+}
+
+/*
+VerifyTOTPCode 校验 TOTP 验证码 允许前后若干时间步的偏移
+
+参数：
+  - secret：base32 编码的密钥
+  - code：验证码
+  - now：当前时间
+
+返回：
+  - int64：验证码对应的时间步 用于防止重放
+  - bool：是否有效
+*/
+func VerifyTOTPCode(secret string, code string, now time.Time) (int64, bool) {
+	if len(code) != consts.TOTP_DIGITS {
+		return 0, false
+	}
+
+	current := now.Unix() / consts.TOTP_PERIOD
+	for offset := int64(-consts.TOTP_SKEW); offset <= consts.TOTP_SKEW; offset++ {
+		expected, err := GenerateTOTPCode(secret, current+offset)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return current + offset, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,97 @@
+/*
+Package encryptors - ZeWise 后端服务器加密器包
+该文件用于测试 TOTP 验证码计算
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package encryptors
+
+import (
+	"testing"
+	"time"
+
+	"zewise.space/backend/consts"
+)
+
+// rfc6238Secret RFC 6238 附录 B 中 SHA1 测试向量使用的密钥 "12345678901234567890" 的 base32 编码
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPCode(t *testing.T) {
+	// RFC 6238 附录 B 的 8 位验证码取后 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, test := range tests {
+		code, err := GenerateTOTPCode(rfc6238Secret, test.unix/consts.TOTP_PERIOD)
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode(%d) error = %v", test.unix, err)
+		}
+		if code != test.want {
+			t.Errorf("GenerateTOTPCode(%d) = %s, want %s", test.unix, code, test.want)
+		}
+	}
+}
+
+func TestVerifyTOTPCode(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := now.Unix() / consts.TOTP_PERIOD
+
+	codeAt := func(step int64) string {
+		code, err := GenerateTOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatalf("GenerateTOTPCode(%d) error = %v", step, err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfc6238Secret, codeAt(current), current, true},
+		{"previous step within skew", rfc6238Secret, codeAt(current - consts.TOTP_SKEW), current - consts.TOTP_SKEW, true},
+		{"next step within skew", rfc6238Secret, codeAt(current + consts.TOTP_SKEW), current + consts.TOTP_SKEW, true},
+		{"expired step", rfc6238Secret, codeAt(current - consts.TOTP_SKEW - 1), 0, false},
+		{"future step", rfc6238Secret, codeAt(current + consts.TOTP_SKEW + 1), 0, false},
+		{"too short", rfc6238Secret, codeAt(current)[1:], 0, false},
+		{"too long", rfc6238Secret, codeAt(current) + "0", 0, false},
+		{"invalid secret", "not base32!", "000000", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			step, ok := VerifyTOTPCode(test.secret, test.code, now)
+			if ok != test.wantOK || step != test.wantStep {
+				t.Fatalf("VerifyTOTPCode() = %d, %v, want %d, %v", step, ok, test.wantStep, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestVerifyTOTPCodeReplayStep(t *testing.T) {
+	// 防重放按时间步记录 同一验证码在有效窗口内任意时刻校验都应返回相同的时间步
+	issuedAt := time.Unix(1234567890, 0)
+	step := issuedAt.Unix() / consts.TOTP_PERIOD
+	code, err := GenerateTOTPCode(rfc6238Secret, step)
+	if err != nil {
+		t.Fatalf("GenerateTOTPCode() error = %v", err)
+	}
+
+	for _, elapsed := range []time.Duration{0, 10 * time.Second, consts.TOTP_PERIOD * time.Second} {
+		got, ok := VerifyTOTPCode(rfc6238Secret, code, issuedAt.Add(elapsed))
+		if !ok || got != step {
+			t.Errorf("VerifyTOTPCode() after %v = %d, %v, want %d, true", elapsed, got, ok, step)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Package generators - ZeWise 后端服务器生成器包
+该文件用于生成图片验证码答案
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package generators
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"zewise.space/backend/consts"
+)
+
+/*
+GenerateCaptchaAnswer 从验证码字符集中随机生成验证码答案
+
+返回：
+  - string：验证码答案
+  - error：错误信息
+*/
+func GenerateCaptchaAnswer() (string, error) {
+	charsetSize := big.NewInt(int64(len(consts.CAPTCHA_CHARSET)))
+	answer := make([]byte, consts.CAPTCHA_LENGTH)
+	for i := range answer {
+		index, err := rand.Int(rand.Reader, charsetSize)
+		if err != nil {
+			return "", err
+		}
+		answer[i] = consts.CAPTCHA_CHARSET[index.Int64()]
+	}
+
+	return string(answer), nil
+}
+
+/*
+NormalizeCaptchaAnswer 规范化用户输入的验证码答案 去除空白并转为大写
+
+参数：
+  - answer：验证码答案
+
+返回：
+  - string：规范化后的验证码答案
+*/
+func NormalizeCaptchaAnswer(answer string) string {
+	return strings.ToUpper(strings.TrimSpace(answer))
+}
@@ -0,0 +1,45 @@
+/*
+Package generators - ZeWise 后端服务器生成器包
+该文件用于生成邀请码
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package generators
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+
+	"zewise.space/backend/consts"
+)
+
+/*
+GenerateInviteCode 生成由大写字母与数字组成的邀请码
+
+返回：
+  - string：邀请码
+  - error：错误信息
+*/
+func GenerateInviteCode() (string, error) {
+	randomBytes := make([]byte, consts.INVITE_CODE_LENGTH)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes)[:consts.INVITE_CODE_LENGTH], nil
+}
+
+/*
+NormalizeInviteCode 规范化用户输入的邀请码 去除空白并转为大写
+
+参数：
+  - code：邀请码
+
+返回：
+  - string：规范化后的邀请码
+*/
+func NormalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
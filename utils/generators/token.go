@@ -15,6 +15,7 @@ import (
 
 	"zewise.space/backend/consts"
 	"zewise.space/backend/utils/parsers"
+	"zewise.space/backend/utils/signers"
 )
 
 /*
@@ -23,33 +24,70 @@ GenerateToken 生成 Token
 参数：
   - uid：用户 ID
   - username：用户名
+  - sessionID：会话 ID
 
 返回：
   - string：Token
   - *BearerTokenClaims：Token Claims
   - error：错误信息
 */
-func GenerateToken(uid primitive.ObjectID, username string) (string, parsers.BearerTokenClaims, error) {
+func GenerateToken(uid primitive.ObjectID, username string, sessionID string) (string, parsers.BearerTokenClaims, error) {
 	// 构造 Token 的 Claims
 	claims := parsers.BearerTokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(consts.TOKEN_EXPIRE_DURATION * time.Second)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(consts.ACCESS_TOKEN_EXPIRE_DURATION * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    consts.TOKEN_ISSUER,
 			Subject:   "BearerToken",
 			ID:        uuid.New().String(),
 		},
-		UID:      uid.Hex(),
-		UserName: username,
+		UID:       uid.Hex(),
+		UserName:  username,
+		SessionID: sessionID,
 	}
 
+	// 获取当前签名密钥
+	keySet, err := signers.Default()
+	if err != nil {
+		return "", claims, err
+	}
+	key := keySet.Active()
+
 	// 生成 Token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.KID
 
 	// 签名 Token
-	tokenString, err := token.SignedString([]byte(consts.TOKEN_SECRET))
+	tokenString, err := token.SignedString(key.SignKey)
 
 	// 返回 Token 和 Claims
 	return tokenString, claims, err
 }
+
+/*
+GenerateRefreshToken 生成不透明的刷新令牌
+
+返回：
+  - string：刷新令牌
+  - error：错误信息
+*/
+func GenerateRefreshToken() (string, error) {
+	return GenerateSalt(consts.REFRESH_TOKEN_LENGTH)
+}
+
+/*
+GeneratePersonalAccessToken 生成带前缀的个人访问令牌
+
+返回：
+  - string：个人访问令牌
+  - error：错误信息
+*/
+func GeneratePersonalAccessToken() (string, error) {
+	token, err := GenerateSalt(consts.PERSONAL_ACCESS_TOKEN_LENGTH)
+	if err != nil {
+		return "", err
+	}
+
+	return consts.PERSONAL_ACCESS_TOKEN_PREFIX + token, nil
+}
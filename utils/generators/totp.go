@@ -0,0 +1,103 @@
+/*
+Package generators - ZeWise 后端服务器生成器包
+该文件用于生成两步验证密钥与恢复码
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package generators
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"zewise.space/backend/consts"
+)
+
+// recoveryCodeEncoding 恢复码编码 使用小写 base32 字母表
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+/*
+GenerateTOTPSecret 生成 base32 编码的 TOTP 密钥
+
+返回：
+  - string：密钥
+  - error：错误信息
+*/
+func GenerateTOTPSecret() (string, error) {
+	randomBytes := make([]byte, consts.TOTP_SECRET_SIZE)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(randomBytes), nil
+}
+
+/*
+GenerateTOTPURI 生成验证器应用使用的 otpauth 配置 URI
+
+参数：
+  - secret：base32 编码的密钥
+  - account：账号名称
+
+返回：
+  - string：配置 URI
+*/
+func GenerateTOTPURI(secret string, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", consts.TOTP_ISSUER)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(consts.TOTP_DIGITS))
+	query.Set("period", fmt.Sprint(consts.TOTP_PERIOD))
+
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + consts.TOTP_ISSUER + ":" + account,
+		RawQuery: query.Encode(),
+	}).String()
+}
+
+/*
+GenerateRecoveryCodes 生成一组一次性恢复码 格式为 xxxxx-xxxxx
+
+参数：
+  - count：数量
+
+返回：
+  - []string：恢复码列表
+  - error：错误信息
+*/
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		randomBytes := make([]byte, consts.RECOVERY_CODE_LENGTH)
+		_, err := rand.Read(randomBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		code := recoveryCodeEncoding.EncodeToString(randomBytes)[:consts.RECOVERY_CODE_LENGTH]
+		half := consts.RECOVERY_CODE_LENGTH / 2
+		codes = append(codes, code[:half]+"-"+code[half:])
+	}
+
+	return codes, nil
+}
+
+/*
+NormalizeRecoveryCode 规范化用户输入的恢复码 去除分隔符与空白并转为小写
+
+参数：
+  - code：恢复码
+
+返回：
+  - string：规范化后的恢复码
+*/
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -53,3 +53,56 @@ func ProcessImage(imageFile ImageFile, decoder ImageDecoder, encoder ImageEncode
 	// 编码图片
 	return encoder.Encode(imageObject, imageConfig)
 }
+
+// ImageVariant 图片变体 描述同一张图片的一种输出方式
+type ImageVariant struct {
+	Encoder         ImageEncoder          // 图片编码器
+	ProcessHandlers []ImageProcessHandler // 图片处理器
+}
+
+// ProcessedImage 处理后的图片
+type ProcessedImage struct {
+	Data   []byte       // 图片数据
+	Config image.Config // 处理后的图片配置
+}
+
+/*
+ProcessImageVariants 解码一次图片并依次生成多个变体
+
+参数：
+  - imageFile：图片文件
+  - decoder：图片解码器
+  - variants：图片变体
+
+返回：
+  - image.Config：原始图片配置
+  - []ProcessedImage：与变体一一对应的处理结果
+  - error：错误
+*/
+func ProcessImageVariants(imageFile ImageFile, decoder ImageDecoder, variants ...ImageVariant) (image.Config, []ProcessedImage, error) {
+	// 解码图片
+	originalObject, originalConfig, err := decoder.Decode(&imageFile)
+	if err != nil {
+		return image.Config{}, nil, err
+	}
+
+	results := make([]ProcessedImage, 0, len(variants))
+	for _, variant := range variants {
+		// 处理图片
+		imageObject, imageConfig := originalObject, originalConfig
+		for _, handler := range variant.ProcessHandlers {
+			imageObject, imageConfig, err = handler.Process(imageObject, imageConfig)
+			if err != nil {
+				return originalConfig, nil, err
+			}
+		}
+		// 编码图片
+		data, err := variant.Encoder.Encode(imageObject, imageConfig)
+		if err != nil {
+			return originalConfig, nil, err
+		}
+		results = append(results, ProcessedImage{Data: data, Config: imageConfig})
+	}
+
+	return originalConfig, results, nil
+}
@@ -0,0 +1,26 @@
+/*
+Package image tools - ZeWise 图片工具
+该文件用于生成二维码图片
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package imagetools
+
+import (
+	"github.com/skip2/go-qrcode"
+)
+
+/*
+EncodeQRCode 将内容编码为 PNG 格式的二维码图片
+
+参数：
+  - content：二维码内容
+  - size：图片边长
+
+返回：
+  - []byte：PNG 图片数据
+  - error：错误信息
+*/
+func EncodeQRCode(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
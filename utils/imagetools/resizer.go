@@ -28,8 +28,8 @@ type ResizeProcessor interface {
 type ScallingDownProcessor struct{}
 
 func (processor *ScallingDownProcessor) Resize(imageObject image.Image, imageConfig image.Config, width int, height int) (image.Image, ImageSize) {
-	// 如果图片宽度大于高度且宽度大于目标宽度
-	if imageConfig.Width > imageConfig.Height && imageConfig.Width > width {
+	// 如果图片宽度不小于高度且宽度大于目标宽度
+	if imageConfig.Width >= imageConfig.Height && imageConfig.Width > width {
 		targetHeight := imageConfig.Height * width / imageConfig.Width
 		return resize.Resize(uint(width), uint(targetHeight), imageObject, resize.Lanczos3), ImageSize{Width: width, Height: targetHeight}
 	}
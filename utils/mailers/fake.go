@@ -0,0 +1,71 @@
+/*
+Package mailers - ZeWise 后端服务器邮件发送包
+该文件用于实现进程内的邮件发送器 供开发与测试使用
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package mailers
+
+import (
+	"log"
+	"sync"
+)
+
+// Mail 已发送的邮件
+type Mail struct {
+	To      string // 收件人地址
+	Subject string // 邮件主题
+	Body    string // 邮件正文
+}
+
+// FakeMailer 进程内邮件发送器 不实际发送邮件 仅记录并打印到日志
+type FakeMailer struct {
+	mutex sync.Mutex
+	mails []Mail
+}
+
+/*
+NewFakeMailer 新建进程内邮件发送器
+
+返回：
+  - *FakeMailer：进程内邮件发送器
+*/
+func NewFakeMailer() *FakeMailer {
+	return &FakeMailer{}
+}
+
+/*
+SendMail 记录邮件
+
+参数：
+  - to：收件人地址
+  - subject：邮件主题
+  - body：邮件正文
+
+返回：
+  - error：错误信息
+*/
+func (mailer *FakeMailer) SendMail(to string, subject string, body string) error {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	mailer.mails = append(mailer.mails, Mail{To: to, Subject: subject, Body: body})
+	log.Printf("[FakeMailer] To: %s Subject: %s\n%s", to, subject, body)
+
+	return nil
+}
+
+/*
+Mails 获取已记录的邮件
+
+返回：
+  - []Mail：邮件列表
+*/
+func (mailer *FakeMailer) Mails() []Mail {
+	mailer.mutex.Lock()
+	defer mailer.mutex.Unlock()
+
+	mails := make([]Mail, len(mailer.mails))
+	copy(mails, mailer.mails)
+	return mails
+}
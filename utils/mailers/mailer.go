@@ -0,0 +1,23 @@
+/*
+Package mailers - ZeWise 后端服务器邮件发送包
+该文件用于声明邮件发送接口
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package mailers
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	/*
+		SendMail 发送纯文本邮件
+
+		参数：
+		  - to：收件人地址
+		  - subject：邮件主题
+		  - body：邮件正文
+
+		返回：
+		  - error：错误信息
+	*/
+	SendMail(to string, subject string, body string) error
+}
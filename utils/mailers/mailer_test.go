@@ -0,0 +1,128 @@
+/*
+Package mailers - ZeWise 后端服务器邮件发送包
+该文件用于测试邮件发送器
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package mailers
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestFakeMailer(t *testing.T) {
+	tests := []struct {
+		name  string
+		mails []Mail
+	}{
+		{"no mail", nil},
+		{"single mail", []Mail{{To: "alice@example.com", Subject: "验证", Body: "token"}}},
+		{"keeps order", []Mail{
+			{To: "alice@example.com", Subject: "first", Body: "1"},
+			{To: "bob@example.com", Subject: "second", Body: "2"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := NewFakeMailer()
+			var mailer Mailer = fake
+			for _, mail := range test.mails {
+				if err := mailer.SendMail(mail.To, mail.Subject, mail.Body); err != nil {
+					t.Fatalf("SendMail() error = %v", err)
+				}
+			}
+
+			got := fake.Mails()
+			if len(got) != len(test.mails) {
+				t.Fatalf("Mails() returned %d mails, want %d", len(got), len(test.mails))
+			}
+			for i := range got {
+				if got[i] != test.mails[i] {
+					t.Errorf("Mails()[%d] = %+v, want %+v", i, got[i], test.mails[i])
+				}
+			}
+
+			// 返回的切片为副本 修改不影响已记录的邮件
+			if len(got) > 0 {
+				got[0].Body = "changed"
+				if fake.Mails()[0].Body != test.mails[0].Body {
+					t.Errorf("Mails() returned a slice sharing the recorded mails")
+				}
+			}
+		})
+	}
+}
+
+func TestNewSMTPMailer(t *testing.T) {
+	tests := []struct {
+		name         string
+		from         string
+		wantErr      bool
+		wantEnvelope string
+		wantHeader   string
+	}{
+		{"bare address", "noreply@zewise.space", false, "noreply@zewise.space", "<noreply@zewise.space>"},
+		{"display name", "ZeWise <noreply@zewise.space>", false, "noreply@zewise.space", `"ZeWise" <noreply@zewise.space>`},
+		{"missing address", "ZeWise", true, "", ""},
+		{"empty", "", true, "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mailer, err := NewSMTPMailer("localhost", 25, "", "", test.from)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("NewSMTPMailer() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+
+			// 信封发件人使用纯地址 邮件头使用带显示名称的格式
+			if mailer.from.Address != test.wantEnvelope {
+				t.Errorf("envelope sender = %q, want %q", mailer.from.Address, test.wantEnvelope)
+			}
+			if mailer.from.String() != test.wantHeader {
+				t.Errorf("From header = %q, want %q", mailer.from.String(), test.wantHeader)
+			}
+		})
+	}
+}
+
+func TestBuildMessage(t *testing.T) {
+	body := strings.Repeat("你好，ZeWise。", 20)
+	message := string(buildMessage(`"ZeWise" <noreply@zewise.space>`, "alice@example.com", "邮箱验证", body))
+
+	header, encoded, found := strings.Cut(message, "\r\n\r\n")
+	if !found {
+		t.Fatalf("message has no header separator")
+	}
+	header += "\r\n"
+	for _, want := range []string{
+		`From: "ZeWise" <noreply@zewise.space>`,
+		"To: alice@example.com",
+		"Subject: =?UTF-8?b?6YKu566x6aqM6K+B?=",
+		"Content-Transfer-Encoding: base64",
+	} {
+		if !strings.Contains(header, want+"\r\n") {
+			t.Errorf("header missing %q", want)
+		}
+	}
+
+	// 正文按每行 76 个字符折行 且解码后与原文一致
+	lines := strings.Split(strings.TrimSuffix(encoded, "\r\n"), "\r\n")
+	for i, line := range lines {
+		if len(line) > 76 {
+			t.Errorf("body line %d has %d characters", i, len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(lines, ""))
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	if string(decoded) != body {
+		t.Errorf("decoded body = %q, want %q", decoded, body)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Package mailers - ZeWise 后端服务器邮件发送包
+该文件用于实现基于 SMTP 的邮件发送
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package mailers
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP_IMPLICIT_TLS_PORT 使用隐式 TLS 的 SMTP 端口 其余端口在服务器支持时使用 STARTTLS
+const SMTP_IMPLICIT_TLS_PORT = 465
+
+// SMTPMailer SMTP 邮件发送器
+type SMTPMailer struct {
+	host     string        // SMTP 服务器地址
+	port     int           // SMTP 服务器端口
+	username string        // 用户名
+	password string        // 密码
+	from     *mail.Address // 发件人
+}
+
+/*
+NewSMTPMailer 新建 SMTP 邮件发送器
+
+参数：
+  - host：SMTP 服务器地址
+  - port：SMTP 服务器端口
+  - username：用户名 为空时不进行认证
+  - password：密码
+  - from：发件人 可带显示名称 如 "ZeWise <noreply@zewise.space>"
+
+返回：
+  - *SMTPMailer：SMTP 邮件发送器
+  - error：发件人格式不合法时返回错误
+*/
+func NewSMTPMailer(host string, port int, username string, password string, from string) (*SMTPMailer, error) {
+	// 信封发件人只能是纯地址 显示名称仅用于邮件头
+	address, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     address,
+	}, nil
+}
+
+/*
+SendMail 发送纯文本邮件
+
+参数：
+  - to：收件人地址
+  - subject：邮件主题
+  - body：邮件正文
+
+返回：
+  - error：错误信息
+*/
+func (mailer *SMTPMailer) SendMail(to string, subject string, body string) error {
+	addr := net.JoinHostPort(mailer.host, strconv.Itoa(mailer.port))
+	message := buildMessage(mailer.from.String(), to, subject, body)
+
+	var auth smtp.Auth
+	if mailer.username != "" {
+		auth = smtp.PlainAuth("", mailer.username, mailer.password, mailer.host)
+	}
+
+	if mailer.port != SMTP_IMPLICIT_TLS_PORT {
+		return smtp.SendMail(addr, auth, mailer.from.Address, []string{to}, message)
+	}
+
+	// 隐式 TLS 需要先建立 TLS 连接
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: mailer.host})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, mailer.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err = client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(mailer.from.Address); err != nil {
+		return err
+	}
+	if err = client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(message); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+/*
+buildMessage 构造 UTF-8 编码的纯文本邮件
+
+参数：
+  - from：邮件头中的发件人 含显示名称
+  - to：收件人地址
+  - subject：邮件主题
+  - body：邮件正文
+
+返回：
+  - []byte：邮件内容
+*/
+func buildMessage(from string, to string, subject string, body string) []byte {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "From: %s\r\n", from)
+	fmt.Fprintf(&buffer, "To: %s\r\n", to)
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	buffer.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buffer.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buffer.WriteString("\r\n")
+
+	// 正文按每行 76 个字符折行
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		buffer.WriteString(encoded[:76])
+		buffer.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buffer.WriteString(encoded)
+	buffer.WriteString("\r\n")
+
+	return buffer.Bytes()
+}
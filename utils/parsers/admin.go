@@ -0,0 +1,33 @@
+/*
+Package parsers - ZeWise 解析器包
+该文件声明了管理相关的解析结构
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+// RoleGrantBody 授予角色请求体
+type RoleGrantBody struct {
+	UID    string `json:"uid"`    // 被操作用户ID
+	Role   string `json:"role"`   // 角色名称
+	Reason string `json:"reason"` // 操作原因
+}
+
+// RoleRevokeBody 撤销角色请求体
+type RoleRevokeBody struct {
+	UID    string `json:"uid"`    // 被操作用户ID
+	Reason string `json:"reason"` // 操作原因
+}
+
+// UserSuspendBody 暂停使用账号请求体
+type UserSuspendBody struct {
+	UID      string `json:"uid"`      // 被操作用户ID
+	Duration int64  `json:"duration"` // 暂停使用时长（秒）
+	Reason   string `json:"reason"`   // 操作原因
+}
+
+// UserModerateBody 处理违规用户请求体
+type UserModerateBody struct {
+	UID    string `json:"uid"`    // 被操作用户ID
+	Reason string `json:"reason"` // 操作原因
+}
@@ -8,7 +8,80 @@ package parsers
 
 // UserLoginBody 用户登录请求体
 type UserLoginBody struct {
-	Email    string `json:"email"`    // 邮箱
-	UserName string `json:"username"` // 用户名
+	Email         string `json:"email"`          // 邮箱
+	UserName      string `json:"username"`       // 用户名
+	Password      string `json:"password"`       // 密码
+	CaptchaID     string `json:"captcha_id"`     // 图片验证码ID 登录失败次数过多时需要提供
+	CaptchaAnswer string `json:"captcha_answer"` // 图片验证码答案
+}
+
+// RefreshTokenBody 刷新令牌请求体
+type RefreshTokenBody struct {
+	RefreshToken string `json:"refresh_token"` // 刷新令牌
+}
+
+// MailVerifyConfirmBody 邮箱验证确认请求体
+type MailVerifyConfirmBody struct {
+	Token string `json:"token"` // 验证令牌
+}
+
+// EmailChangeRequestBody 申请修改邮箱请求体
+type EmailChangeRequestBody struct {
 	Password string `json:"password"` // 密码
+	Email    string `json:"email"`    // 新邮箱
+}
+
+// EmailChangeConfirmBody 确认修改邮箱请求体
+type EmailChangeConfirmBody struct {
+	Token string `json:"token"` // 确认令牌
+}
+
+// PasswordResetRequestBody 申请密码重置请求体
+type PasswordResetRequestBody struct {
+	Email string `json:"email"` // 邮箱
+}
+
+// PasswordResetConfirmBody 确认密码重置请求体
+type PasswordResetConfirmBody struct {
+	Token       string `json:"token"`        // 重置令牌
+	NewPassword string `json:"new_password"` // 新密码
+}
+
+// SessionRevokeBody 吊销会话请求体
+type SessionRevokeBody struct {
+	ID string `json:"id"` // 会话ID
+}
+
+// AccountUnlockBody 账号解锁请求体
+type AccountUnlockBody struct {
+	Token string `json:"token"` // 解锁令牌
+}
+
+// TOTPCodeBody 两步验证验证码请求体
+type TOTPCodeBody struct {
+	Code string `json:"code"` // 验证码
+}
+
+// TOTPDisableBody 关闭两步验证请求体
+type TOTPDisableBody struct {
+	Password string `json:"password"` // 密码
+	Code     string `json:"code"`     // 验证码或恢复码
+}
+
+// TOTPChallengeBody 完成两步验证请求体
+type TOTPChallengeBody struct {
+	ChallengeToken string `json:"challenge_token"` // 登录时返回的挑战令牌
+	Code           string `json:"code"`            // 验证码或恢复码
+}
+
+// PersonalAccessTokenCreateBody 创建个人访问令牌请求体
+type PersonalAccessTokenCreateBody struct {
+	Name          string   `json:"name"`            // 令牌名称
+	Scopes        []string `json:"scopes"`          // 权限范围
+	ExpiresInDays int      `json:"expires_in_days"` // 有效天数
+}
+
+// PersonalAccessTokenRevokeBody 吊销个人访问令牌请求体
+type PersonalAccessTokenRevokeBody struct {
+	ID string `json:"id"` // 令牌ID
 }
@@ -0,0 +1,25 @@
+/*
+Package parsers - ZeWise 解析器包
+该文件声明了评论相关的解析结构
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+// CommentCreateBody 发表评论请求体
+type CommentCreateBody struct {
+	PostID  string `json:"post_id"` // 博文ID
+	Content string `json:"content"` // 内容
+}
+
+// ReplyCreateBody 发表回复请求体
+type ReplyCreateBody struct {
+	CommentID     string `json:"comment_id"`      // 评论ID
+	ParentReplyID string `json:"parent_reply_id"` // 父回复ID 直接回复评论时为空
+	Content       string `json:"content"`         // 内容
+}
+
+// CommentDeleteBody 删除评论或回复请求体
+type CommentDeleteBody struct {
+	ID string `json:"id"` // 评论或回复ID
+}
@@ -0,0 +1,12 @@
+/*
+Package parsers - ZeWise 解析器包
+该文件声明了关注关系相关的解析结构
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+// FollowBody 关注或取消关注请求体
+type FollowBody struct {
+	UID string `json:"uid"` // 目标用户ID
+}
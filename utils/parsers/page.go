@@ -0,0 +1,44 @@
+/*
+Package parsers - ZeWise 解析器包
+该文件用于解析分页参数
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/types"
+)
+
+/*
+ParsePagination 解析游标分页参数
+
+参数：
+  - ctx：Fiber 上下文
+
+返回：
+  - primitive.ObjectID：游标 未提供时为空
+  - int64：分页大小
+  - error：错误信息
+*/
+func ParsePagination(ctx *fiber.Ctx) (primitive.ObjectID, int64, error) {
+	cursor := primitive.NilObjectID
+	if rawCursor := ctx.Query("cursor"); rawCursor != "" {
+		var err error
+		cursor, err = primitive.ObjectIDFromHex(rawCursor)
+		if err != nil {
+			return cursor, 0, types.NewError(types.ErrInvalidParams, "不合法的游标")
+		}
+	}
+
+	limit := ctx.QueryInt("limit", consts.PAGE_SIZE_DEFAULT)
+	if limit <= 0 || limit > consts.PAGE_SIZE_MAX {
+		return cursor, 0, types.NewError(types.ErrInvalidParams, "不合法的分页大小")
+	}
+
+	return cursor, int64(limit), nil
+}
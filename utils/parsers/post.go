@@ -0,0 +1,43 @@
+/*
+Package parsers - ZeWise 解析器包
+该文件声明了博文相关的解析结构
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package parsers
+
+// PostCreateBody 发布博文请求体
+type PostCreateBody struct {
+	Title    string   `json:"title"`     // 标题
+	Content  string   `json:"content"`   // 内容
+	MediaIDs []string `json:"media_ids"` // 媒体ID
+	IsPublic bool     `json:"is_public"` // 是否公开
+}
+
+// PostUpdateBody 更新博文请求体 未提供的字段不做修改
+type PostUpdateBody struct {
+	ID       string    `json:"id"`        // 博文ID
+	Title    *string   `json:"title"`     // 标题
+	Content  *string   `json:"content"`   // 内容
+	MediaIDs *[]string `json:"media_ids"` // 媒体ID
+	IsPublic *bool     `json:"is_public"` // 是否公开
+}
+
+// PostDeleteBody 删除博文请求体
+type PostDeleteBody struct {
+	ID string `json:"id"` // 博文ID
+}
+
+// PostRepostBody 转发或取消转发请求体
+type PostRepostBody struct {
+	ID string `json:"id"` // 被转发的博文ID
+}
+
+// PostQuoteBody 引用转发请求体
+type PostQuoteBody struct {
+	ID       string   `json:"id"`        // 被引用的博文ID
+	Title    string   `json:"title"`     // 标题
+	Content  string   `json:"content"`   // 内容
+	MediaIDs []string `json:"media_ids"` // 媒体ID
+	IsPublic bool     `json:"is_public"` // 是否公开
+}
@@ -8,12 +8,13 @@ package parsers
 
 import (
 	"errors"
+	"slices"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
-	"zewise.space/backend/consts"
+	"zewise.space/backend/utils/signers"
 )
 
 var (
@@ -21,6 +22,10 @@ var (
 	ErrNoBearerToken = errors.New("未提供 Bearer Token")
 	// ErrInvalidBearerTokenFormat Bearer Token 格式错误
 	ErrInvalidBearerTokenFormat = errors.New("Bearer Token 格式错误")
+	// ErrUnknownSigningKey 未知的签名密钥
+	ErrUnknownSigningKey = errors.New("未知的签名密钥")
+	// ErrSigningMethodMismatch 签名算法与密钥不匹配
+	ErrSigningMethodMismatch = errors.New("签名算法与密钥不匹配")
 )
 
 // BeaerTokenClaims Bearer Token 声明
@@ -28,6 +33,33 @@ type BearerTokenClaims struct {
 	jwt.RegisteredClaims        // JWT 注册声明
 	UID                  string `json:"uid"`      // 用户 ID
 	UserName             string `json:"username"` // 用户名
+	SessionID            string `json:"sid"`      // 会话 ID 即刷新令牌族 ID
+
+	PersonalAccessToken bool     `json:"-"` // 是否为个人访问令牌
+	Scopes              []string `json:"-"` // 个人访问令牌的权限范围
+}
+
+/*
+HasScopes 判断令牌是否拥有全部指定权限范围 会话令牌拥有全部权限
+
+参数：
+  - scopes：权限范围列表
+
+返回：
+  - bool：是否拥有全部权限范围
+*/
+func (claims *BearerTokenClaims) HasScopes(scopes ...string) bool {
+	if !claims.PersonalAccessToken {
+		return true
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(claims.Scopes, scope) {
+			return false
+		}
+	}
+
+	return true
 }
 
 /*
@@ -79,10 +111,36 @@ ParseToken 解析令牌
 func ParseToken(token string) (BearerTokenClaims, error) {
 	// 解析令牌
 	claims := BearerTokenClaims{}
-	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(consts.TOKEN_SECRET), nil
-	})
+	_, err := jwt.ParseWithClaims(token, &claims, verifyKeyFunc)
 
 	// 返回结果
 	return claims, err
 }
+
+/*
+verifyKeyFunc 按令牌头部的 kid 选择验证密钥 并要求签名算法与密钥一致
+
+参数：
+  - token：待验证的令牌
+
+返回：
+  - interface{}：验证密钥
+  - error：错误信息
+*/
+func verifyKeyFunc(token *jwt.Token) (interface{}, error) {
+	keySet, err := signers.Default()
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keySet.Lookup(kid)
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrSigningMethodMismatch
+	}
+
+	return key.VerifyKey, nil
+}
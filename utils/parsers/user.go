@@ -8,9 +8,12 @@ package parsers
 
 // UserLoginBody 用户登录请求体
 type UserRegisterBody struct {
-	Email    string `json:"email"`    // 邮箱
-	Username string `json:"username"` // 用户名
-	Password string `json:"password"` // 密码
+	Email         string `json:"email"`          // 邮箱
+	Username      string `json:"username"`       // 用户名
+	Password      string `json:"password"`       // 密码
+	InviteCode    string `json:"invite_code"`    // 邀请码
+	CaptchaID     string `json:"captcha_id"`     // 图片验证码ID
+	CaptchaAnswer string `json:"captcha_answer"` // 图片验证码答案
 }
 
 // UserUpdateProfileBody 用户更新资料请求体
@@ -26,3 +29,24 @@ type UserUpdatePasswordBody struct {
 	OldPassword string `json:"old_password"` // 旧密码
 	NewPassword string `json:"new_password"` // 新密码
 }
+
+// UserUpdateUsernameBody 修改用户名请求体
+type UserUpdateUsernameBody struct {
+	Username string `json:"username"` // 新用户名
+}
+
+// AccountDeleteBody 申请注销账号请求体
+type AccountDeleteBody struct {
+	Password string `json:"password"` // 密码
+}
+
+// InviteCodeCreateBody 生成邀请码请求体
+type InviteCodeCreateBody struct {
+	MaxUses       int64 `json:"max_uses"`        // 可使用次数
+	ExpiresInDays int   `json:"expires_in_days"` // 有效天数
+}
+
+// InviteCodeRevokeBody 作废邀请码请求体
+type InviteCodeRevokeBody struct {
+	ID string `json:"id"` // 邀请码ID
+}
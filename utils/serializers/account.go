@@ -0,0 +1,29 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化账号注销信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"time"
+)
+
+// AccountDeletionResponse 申请注销账号响应
+type AccountDeletionResponse struct {
+	PurgeAt int64 `json:"purge_at"` // 计划清除账号数据的时间 此前登录即撤销注销
+}
+
+/*
+NewAccountDeletionResponse 创建申请注销账号响应
+
+参数：
+  - purgeAt：计划清除账号数据的时间
+
+返回：
+  - AccountDeletionResponse：申请注销账号响应
+*/
+func NewAccountDeletionResponse(purgeAt time.Time) AccountDeletionResponse {
+	return AccountDeletionResponse{PurgeAt: purgeAt.Unix()}
+}
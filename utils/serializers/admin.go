@@ -0,0 +1,105 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化管理信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"time"
+
+	"zewise.space/backend/models"
+)
+
+// AdminUserResponse 管理员视角的用户信息响应
+type AdminUserResponse struct {
+	UserProfileResponse
+	TOTPEnabled    bool   `json:"totp_enabled"`              // 是否开启两步验证
+	Banned         bool   `json:"banned"`                    // 是否被永久封禁
+	SuspendedUntil int64  `json:"suspended_until,omitempty"` // 暂停使用截止时间
+	SuspendReason  string `json:"suspend_reason,omitempty"`  // 封禁或暂停使用原因
+	InvitedBy      string `json:"invited_by,omitempty"`      // 邀请人ID
+}
+
+/*
+NewAdminUserResponse 创建管理员视角的用户信息响应
+
+参数：
+  - userInfo：用户信息
+  - authInfo：用户认证信息
+
+返回：
+  - AdminUserResponse：用户信息响应
+*/
+func NewAdminUserResponse(userInfo models.UserInfo, authInfo models.UserAuthInfo) AdminUserResponse {
+	response := AdminUserResponse{
+		UserProfileResponse: NewUserProfileResponse(userInfo),
+		TOTPEnabled:         authInfo.TOTPEnabled,
+		Banned:              authInfo.Banned,
+	}
+	if !userInfo.InvitedBy.IsZero() {
+		response.InvitedBy = userInfo.InvitedBy.Hex()
+	}
+	if authInfo.Banned || time.Now().Before(authInfo.SuspendedUntil) {
+		response.SuspendReason = authInfo.SuspendReason
+	}
+	if time.Now().Before(authInfo.SuspendedUntil) {
+		response.SuspendedUntil = authInfo.SuspendedUntil.Unix()
+	}
+
+	return response
+}
+
+// AuditLogResponse 审计日志响应
+type AuditLogResponse struct {
+	ID         string `json:"id"`               // 日志ID
+	OperatorID string `json:"operator_id"`      // 操作者ID
+	TargetID   string `json:"target_id"`        // 被操作用户ID
+	Action     string `json:"action"`           // 操作类型
+	Before     string `json:"before,omitempty"` // 操作前的值
+	After      string `json:"after,omitempty"`  // 操作后的值
+	Reason     string `json:"reason,omitempty"` // 操作原因
+	IP         string `json:"ip"`               // 操作者 IP 地址
+	Time       int64  `json:"time"`             // 操作时间
+}
+
+// AuditLogListResponse 审计日志列表响应
+type AuditLogListResponse struct {
+	Logs       []AuditLogResponse `json:"logs"`                  // 审计日志列表
+	NextCursor string             `json:"next_cursor,omitempty"` // 下一页游标
+}
+
+/*
+NewAuditLogListResponse 创建审计日志列表响应
+
+参数：
+  - data：审计日志列表
+  - limit：分页大小 返回数量达到分页大小时才生成下一页游标
+
+返回：
+  - AuditLogListResponse：审计日志列表响应
+*/
+func NewAuditLogListResponse(data []models.AuditLog, limit int64) AuditLogListResponse {
+	logs := make([]AuditLogResponse, 0, len(data))
+	for _, log := range data {
+		logs = append(logs, AuditLogResponse{
+			ID:         log.ID.Hex(),
+			OperatorID: log.OperatorID.Hex(),
+			TargetID:   log.TargetID.Hex(),
+			Action:     log.Action,
+			Before:     log.Before,
+			After:      log.After,
+			Reason:     log.Reason,
+			IP:         log.IP,
+			Time:       log.Time.Unix(),
+		})
+	}
+
+	response := AuditLogListResponse{Logs: logs}
+	if len(data) > 0 && int64(len(data)) == limit {
+		response.NextCursor = data[len(data)-1].ID.Hex()
+	}
+
+	return response
+}
@@ -6,22 +6,193 @@ Copyright (c) [2024], Author(s):
 */
 package serializers
 
+import (
+	"encoding/base64"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+)
+
 // AuthLoginResponse 认证登录响应
 type AuthLoginResponse struct {
-	Token string `json:"token,omitempty"` // Token
+	Token             string `json:"token,omitempty"`               // 访问令牌
+	RefreshToken      string `json:"refresh_token,omitempty"`       // 刷新令牌
+	ExpiresIn         int64  `json:"expires_in,omitempty"`          // 访问令牌有效期（秒）
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"` // 是否需要两步验证
+	ChallengeToken    string `json:"challenge_token,omitempty"`     // 两步验证挑战令牌
 }
 
 /*
 NewAuthLoginResponse 创建认证登录响应
 
 参数：
-  - token：Token
+  - token：访问令牌
+  - refreshToken：刷新令牌
+
+返回：
+  - AuthLoginResponse：认证登录响应
+*/
+func NewAuthLoginResponse(token string, refreshToken string) AuthLoginResponse {
+	return AuthLoginResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    consts.ACCESS_TOKEN_EXPIRE_DURATION,
+	}
+}
+
+/*
+NewTwoFactorChallengeResponse 创建需要两步验证的登录响应
+
+参数：
+  - challengeToken：挑战令牌
 
 返回：
   - AuthLoginResponse：认证登录响应
 */
-func NewAuthLoginResponse(token string) AuthLoginResponse {
+func NewTwoFactorChallengeResponse(challengeToken string) AuthLoginResponse {
 	return AuthLoginResponse{
-		Token: token,
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+		ExpiresIn:         consts.TOTP_CHALLENGE_EXPIRE_DURATION,
+	}
+}
+
+// TOTPSetupResponse 两步验证设置响应
+type TOTPSetupResponse struct {
+	Secret string `json:"secret"`  // TOTP 密钥 供无法扫码时手动输入
+	URI    string `json:"uri"`     // otpauth 配置 URI
+	QRCode string `json:"qr_code"` // data URI 格式的配置二维码
+}
+
+/*
+NewTOTPSetupResponse 创建两步验证设置响应
+
+参数：
+  - secret：TOTP 密钥
+  - uri：otpauth 配置 URI
+  - qrCode：PNG 格式的配置二维码
+
+返回：
+  - TOTPSetupResponse：两步验证设置响应
+*/
+func NewTOTPSetupResponse(secret string, uri string, qrCode []byte) TOTPSetupResponse {
+	return TOTPSetupResponse{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(qrCode),
+	}
+}
+
+// RecoveryCodesResponse 恢复码响应
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"` // 恢复码 仅显示一次
+}
+
+/*
+NewRecoveryCodesResponse 创建恢复码响应
+
+参数：
+  - codes：恢复码
+
+返回：
+  - RecoveryCodesResponse：恢复码响应
+*/
+func NewRecoveryCodesResponse(codes []string) RecoveryCodesResponse {
+	return RecoveryCodesResponse{RecoveryCodes: codes}
+}
+
+// SessionResponse 会话信息响应
+type SessionResponse struct {
+	ID          string `json:"id"`                    // 会话ID
+	IP          string `json:"ip,omitempty"`          // 登录 IP 地址
+	Location    string `json:"location,omitempty"`    // 登录地理位置
+	Device      string `json:"device,omitempty"`      // 设备
+	Application string `json:"application,omitempty"` // 应用
+	IssuedAt    int64  `json:"issued_at"`             // 签发时间
+	LastUsedAt  int64  `json:"last_used_at"`          // 最后使用时间
+	IsCurrent   bool   `json:"is_current"`            // 是否为当前会话
+}
+
+// SessionListResponse 会话列表响应
+type SessionListResponse struct {
+	Sessions []SessionResponse `json:"sessions"` // 会话列表
+}
+
+/*
+NewSessionListResponse 创建会话列表响应
+
+参数：
+  - data：会话列表
+  - currentID：当前会话ID
+
+返回：
+  - SessionListResponse：会话列表响应
+*/
+func NewSessionListResponse(data []models.SessionInfo, currentID string) SessionListResponse {
+	sessions := make([]SessionResponse, 0, len(data))
+	for _, session := range data {
+		sessions = append(sessions, SessionResponse{
+			ID:          session.ID,
+			IP:          session.IP,
+			Location:    session.Location,
+			Device:      session.Device,
+			Application: session.Application,
+			IssuedAt:    session.IssuedAt.Unix(),
+			LastUsedAt:  session.LastUsedAt.Unix(),
+			IsCurrent:   session.ID == currentID,
+		})
+	}
+
+	return SessionListResponse{Sessions: sessions}
+}
+
+// LoginLogResponse 登录日志响应
+type LoginLogResponse struct {
+	ID          string `json:"id"`                    // 日志ID
+	IP          string `json:"ip"`                    // IP 地址
+	Location    string `json:"location,omitempty"`    // 地理位置
+	Device      string `json:"device,omitempty"`      // 设备
+	Application string `json:"application,omitempty"` // 应用
+	Time        int64  `json:"time"`                  // 登录时间
+	Succeed     bool   `json:"succeed"`               // 是否成功
+	Locked      bool   `json:"locked"`                // 是否因账号锁定被拒绝或触发锁定
+}
+
+// LoginHistoryResponse 登录历史响应
+type LoginHistoryResponse struct {
+	Logs       []LoginLogResponse `json:"logs"`                  // 登录日志列表
+	NextCursor string             `json:"next_cursor,omitempty"` // 下一页游标
+}
+
+/*
+NewLoginHistoryResponse 创建登录历史响应
+
+参数：
+  - data：登录日志列表
+  - limit：分页大小 返回数量达到分页大小时才生成下一页游标
+
+返回：
+  - LoginHistoryResponse：登录历史响应
+*/
+func NewLoginHistoryResponse(data []models.UserLoginLog, limit int64) LoginHistoryResponse {
+	logs := make([]LoginLogResponse, 0, len(data))
+	for _, log := range data {
+		logs = append(logs, LoginLogResponse{
+			ID:          log.ID.Hex(),
+			IP:          log.IP,
+			Location:    log.Location,
+			Device:      log.Device,
+			Application: log.Application,
+			Time:        log.Time.Unix(),
+			Succeed:     log.IfSucceed,
+			Locked:      log.IfLocked,
+		})
+	}
+
+	response := LoginHistoryResponse{Logs: logs}
+	if len(data) > 0 && int64(len(data)) == limit {
+		response.NextCursor = data[len(data)-1].ID.Hex()
 	}
+
+	return response
 }
@@ -0,0 +1,34 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化图片验证码
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"encoding/base64"
+)
+
+// CaptchaResponse 图片验证码响应
+type CaptchaResponse struct {
+	CaptchaID string `json:"captcha_id"` // 验证码ID
+	Image     string `json:"image"`      // data URI 格式的验证码图片
+}
+
+/*
+NewCaptchaResponse 创建图片验证码响应
+
+参数：
+  - captchaID：验证码ID
+  - image：WebP 格式的验证码图片
+
+返回：
+  - CaptchaResponse：图片验证码响应
+*/
+func NewCaptchaResponse(captchaID string, image []byte) CaptchaResponse {
+	return CaptchaResponse{
+		CaptchaID: captchaID,
+		Image:     "data:image/webp;base64," + base64.StdEncoding.EncodeToString(image),
+	}
+}
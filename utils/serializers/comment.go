@@ -0,0 +1,163 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化评论与回复信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"zewise.space/backend/models"
+)
+
+// CommentResponse 评论信息响应
+type CommentResponse struct {
+	ID         string `json:"id"`          // 评论ID
+	PostID     string `json:"post_id"`     // 博文ID
+	UID        string `json:"uid"`         // 用户ID
+	Username   string `json:"username"`    // 用户名
+	Content    string `json:"content"`     // 内容
+	ReplyCount int64  `json:"reply_count"` // 回复总数
+	CreatedAt  int64  `json:"created_at"`  // 创建时间
+}
+
+/*
+NewCommentResponse 创建评论信息响应
+
+参数：
+  - data：评论信息
+
+返回：
+  - CommentResponse：评论信息响应
+*/
+func NewCommentResponse(data models.CommentInfo) CommentResponse {
+	return CommentResponse{
+		ID:         data.ID.Hex(),
+		PostID:     data.PostID.Hex(),
+		UID:        data.UID.Hex(),
+		Username:   data.Username,
+		Content:    data.Content,
+		ReplyCount: data.ReplyCount,
+		CreatedAt:  data.CreatedAt.Unix(),
+	}
+}
+
+// CommentListResponse 评论列表响应
+type CommentListResponse struct {
+	Comments   []CommentResponse `json:"comments"`              // 评论列表
+	NextCursor string            `json:"next_cursor,omitempty"` // 下一页游标
+}
+
+/*
+NewCommentListResponse 创建评论列表响应
+
+参数：
+  - data：评论列表
+  - limit：分页大小 返回数量达到分页大小时才生成下一页游标
+
+返回：
+  - CommentListResponse：评论列表响应
+*/
+func NewCommentListResponse(data []models.CommentInfo, limit int64) CommentListResponse {
+	comments := make([]CommentResponse, 0, len(data))
+	for _, comment := range data {
+		comments = append(comments, NewCommentResponse(comment))
+	}
+
+	response := CommentListResponse{Comments: comments}
+	if len(data) > 0 && int64(len(data)) == limit {
+		response.NextCursor = data[len(data)-1].ID.Hex()
+	}
+
+	return response
+}
+
+// ReplyResponse 回复信息响应 Replies 为该回复下的子回复
+type ReplyResponse struct {
+	ID            string           `json:"id"`                        // 回复ID
+	CommentID     string           `json:"comment_id"`                // 评论ID
+	ParentReplyID string           `json:"parent_reply_id,omitempty"` // 父回复ID
+	UID           string           `json:"uid"`                       // 用户ID
+	Username      string           `json:"username"`                  // 用户名
+	Content       string           `json:"content"`                   // 内容
+	IsDeleted     bool             `json:"is_deleted"`                // 是否已删除
+	ReplyCount    int64            `json:"reply_count,omitempty"`     // 楼层内回复数 仅顶层回复返回
+	CreatedAt     int64            `json:"created_at"`                // 创建时间
+	Replies       []*ReplyResponse `json:"replies"`                   // 子回复
+}
+
+/*
+NewReplyResponse 创建回复信息响应 已删除的回复不返回作者与内容
+
+参数：
+  - data：回复信息
+
+返回：
+  - *ReplyResponse：回复信息响应
+*/
+func NewReplyResponse(data models.ReplyInfo) *ReplyResponse {
+	response := &ReplyResponse{
+		ID:         data.ID.Hex(),
+		CommentID:  data.CommentID.Hex(),
+		IsDeleted:  data.IsDeleted,
+		ReplyCount: data.ReplyCount,
+		CreatedAt:  data.CreatedAt.Unix(),
+		Replies:    []*ReplyResponse{},
+	}
+	if !data.ParentReplyID.IsZero() {
+		response.ParentReplyID = data.ParentReplyID.Hex()
+	}
+	if !data.IsDeleted {
+		response.UID = data.UID.Hex()
+		response.Username = data.Username
+		response.Content = data.Content
+	}
+
+	return response
+}
+
+// ReplyThreadResponse 回复楼层树响应
+type ReplyThreadResponse struct {
+	Replies    []*ReplyResponse `json:"replies"`               // 顶层回复列表
+	NextCursor string           `json:"next_cursor,omitempty"` // 下一页游标
+}
+
+/*
+NewReplyThreadResponse 创建回复楼层树响应
+
+参数：
+  - roots：当前页的顶层回复
+  - descendants：顶层回复下的全部子回复 需按发布时间正序排列
+  - limit：分页大小 返回数量达到分页大小时才生成下一页游标
+
+返回：
+  - ReplyThreadResponse：回复楼层树响应
+*/
+func NewReplyThreadResponse(roots []models.ReplyInfo, descendants []models.ReplyInfo, limit int64) ReplyThreadResponse {
+	nodes := make(map[primitive.ObjectID]*ReplyResponse, len(roots)+len(descendants))
+
+	response := ReplyThreadResponse{Replies: make([]*ReplyResponse, 0, len(roots))}
+	for _, root := range roots {
+		node := NewReplyResponse(root)
+		nodes[root.ID] = node
+		response.Replies = append(response.Replies, node)
+	}
+
+	// 子回复按时间正序排列 父回复总是先于子回复出现
+	for _, reply := range descendants {
+		node := NewReplyResponse(reply)
+		node.ReplyCount = 0
+		nodes[reply.ID] = node
+		if parent, ok := nodes[reply.ParentReplyID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	if len(roots) > 0 && int64(len(roots)) == limit {
+		response.NextCursor = roots[len(roots)-1].ID.Hex()
+	}
+
+	return response
+}
@@ -43,6 +43,12 @@ func NewErrorResponse(raw_error error) any {
 	if errors.Is(err, types.ErrAuthFailed) {
 		code = AUTH_ERROR
 	}
+	if errors.Is(err, types.ErrAccountSuspended) {
+		code = ACCOUNT_SUSPENDED
+	}
+	if errors.Is(err, types.ErrCaptchaRequired) {
+		code = CAPTCHA_REQUIRED
+	}
 	if errors.Is(err, types.ErrNetworkError) {
 		code = NETWORK_ERROR
 	}
@@ -0,0 +1,98 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化个人数据导出信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+)
+
+// DataExportResponse 个人数据导出状态响应
+type DataExportResponse struct {
+	ID          string `json:"id"`                     // 导出记录ID
+	Status      string `json:"status"`                 // 状态 pending, ready, failed, expired
+	Size        int64  `json:"size,omitempty"`         // 归档大小
+	CreatedAt   int64  `json:"created_at"`             // 申请时间
+	CompletedAt int64  `json:"completed_at,omitempty"` // 生成完成时间
+	ExpiresAt   int64  `json:"expires_at,omitempty"`   // 归档过期时间
+	DownloadURL string `json:"download_url,omitempty"` // 限时下载链接
+}
+
+/*
+NewDataExportResponse 创建个人数据导出状态响应
+
+参数：
+  - data：导出记录
+  - downloadURL：限时下载链接 仅在归档可下载时提供
+
+返回：
+  - DataExportResponse：个人数据导出状态响应
+*/
+func NewDataExportResponse(data models.DataExport, downloadURL string) DataExportResponse {
+	response := DataExportResponse{
+		ID:        data.ID.Hex(),
+		Status:    data.Status,
+		CreatedAt: data.CreatedAt.Unix(),
+	}
+	if data.Status == consts.DATA_EXPORT_STATUS_READY {
+		response.Size = data.Size
+		response.CompletedAt = data.CompletedAt.Unix()
+		response.ExpiresAt = data.ExpiresAt.Unix()
+		response.DownloadURL = downloadURL
+	}
+
+	return response
+}
+
+// DataExportArchive 个人数据导出归档内容 每个字段对应归档中的一个 JSON 文件
+type DataExportArchive struct {
+	Profile      UserProfileResponse // 用户资料
+	LoginHistory []LoginLogResponse  // 登录历史
+	Posts        []PostResponse      // 博文
+	Comments     []CommentResponse   // 评论
+	Replies      []*ReplyResponse    // 回复
+	Media        []MediaResponse     // 媒体文件信息
+}
+
+/*
+NewDataExportArchive 创建个人数据导出归档内容
+
+参数：
+  - userInfo：用户信息
+  - logs：登录日志
+  - posts：博文
+  - comments：评论
+  - replies：回复
+  - media：媒体文件信息
+
+返回：
+  - DataExportArchive：个人数据导出归档内容
+*/
+func NewDataExportArchive(userInfo models.UserInfo, logs []models.UserLoginLog, posts []models.PostInfo, comments []models.CommentInfo, replies []models.ReplyInfo, media []models.MediaInfo) DataExportArchive {
+	archive := DataExportArchive{
+		Profile:      NewUserProfileResponse(userInfo),
+		LoginHistory: NewLoginHistoryResponse(logs, 0).Logs,
+		Posts:        make([]PostResponse, 0, len(posts)),
+		Comments:     make([]CommentResponse, 0, len(comments)),
+		Replies:      make([]*ReplyResponse, 0, len(replies)),
+		Media:        make([]MediaResponse, 0, len(media)),
+	}
+	for _, post := range posts {
+		archive.Posts = append(archive.Posts, NewPostResponse(post))
+	}
+	for _, comment := range comments {
+		archive.Comments = append(archive.Comments, NewCommentResponse(comment))
+	}
+	for _, reply := range replies {
+		archive.Replies = append(archive.Replies, NewReplyResponse(reply))
+	}
+	for _, mediaInfo := range media {
+		archive.Media = append(archive.Media, NewMediaResponse(mediaInfo))
+	}
+
+	return archive
+}
@@ -0,0 +1,103 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化关注关系信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/utils/functools"
+)
+
+// FollowUserResponse 关注列表中的用户信息响应
+type FollowUserResponse struct {
+	ID       string `json:"id"`                 // 用户ID
+	Username string `json:"username"`           // 用户名
+	Nickname string `json:"nickname,omitempty"` // 昵称
+	Avatar   string `json:"avatar,omitempty"`   // 头像
+	Sign     string `json:"sign,omitempty"`     // 签名
+	IsMutual bool   `json:"is_mutual"`          // 是否互相关注
+}
+
+/*
+NewFollowUserResponse 创建关注列表中的用户信息响应
+
+参数：
+  - data：用户信息
+  - isMutual：是否互相关注
+
+返回：
+  - FollowUserResponse：用户信息响应
+*/
+func NewFollowUserResponse(data models.UserInfo, isMutual bool) FollowUserResponse {
+	return FollowUserResponse{
+		ID:       data.ID.Hex(),
+		Username: data.UserName,
+		Nickname: data.NickName,
+		Avatar:   functools.JoinStrings(consts.AVATAR_URL_PREFIX, data.Avatar, ".webp"),
+		Sign:     data.Sign,
+		IsMutual: isMutual,
+	}
+}
+
+// FollowListResponse 粉丝或关注列表响应
+type FollowListResponse struct {
+	Users      []FollowUserResponse `json:"users"`                 // 用户列表
+	NextCursor string               `json:"next_cursor,omitempty"` // 下一页游标
+}
+
+/*
+NewFollowListResponse 创建粉丝或关注列表响应
+
+参数：
+  - follows：当前页的关注关系 用于生成下一页游标
+  - users：对方用户信息
+  - mutual：互相关注的用户集合
+  - limit：分页大小 返回数量达到分页大小时才生成下一页游标
+
+返回：
+  - FollowListResponse：粉丝或关注列表响应
+*/
+func NewFollowListResponse(follows []models.FollowInfo, users []models.UserInfo, mutual map[primitive.ObjectID]bool, limit int64) FollowListResponse {
+	list := make([]FollowUserResponse, 0, len(users))
+	for _, user := range users {
+		list = append(list, NewFollowUserResponse(user, mutual[user.ID]))
+	}
+
+	response := FollowListResponse{Users: list}
+	if len(follows) > 0 && int64(len(follows)) == limit {
+		response.NextCursor = follows[len(follows)-1].ID.Hex()
+	}
+
+	return response
+}
+
+// RelationResponse 关注关系响应
+type RelationResponse struct {
+	Following  bool `json:"following"`   // 是否已关注对方
+	FollowedBy bool `json:"followed_by"` // 是否被对方关注
+	IsMutual   bool `json:"is_mutual"`   // 是否互相关注
+}
+
+/*
+NewRelationResponse 创建关注关系响应
+
+参数：
+  - following：是否已关注对方
+  - followedBy：是否被对方关注
+
+返回：
+  - RelationResponse：关注关系响应
+*/
+func NewRelationResponse(following bool, followedBy bool) RelationResponse {
+	return RelationResponse{
+		Following:  following,
+		FollowedBy: followedBy,
+		IsMutual:   following && followedBy,
+	}
+}
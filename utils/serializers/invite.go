@@ -0,0 +1,96 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化邀请码信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"zewise.space/backend/models"
+)
+
+// InviteCodeResponse 邀请码响应
+type InviteCodeResponse struct {
+	ID        string `json:"id"`         // 邀请码ID
+	Code      string `json:"code"`       // 邀请码
+	MaxUses   int64  `json:"max_uses"`   // 可使用次数
+	UsedCount int64  `json:"used_count"` // 已使用次数
+	Revoked   bool   `json:"revoked"`    // 是否已作废
+	CreatedAt int64  `json:"created_at"` // 创建时间
+	ExpiresAt int64  `json:"expires_at"` // 过期时间
+}
+
+/*
+NewInviteCodeResponse 创建邀请码响应
+
+参数：
+  - data：邀请码信息
+
+返回：
+  - InviteCodeResponse：邀请码响应
+*/
+func NewInviteCodeResponse(data models.InviteCode) InviteCodeResponse {
+	return InviteCodeResponse{
+		ID:        data.ID.Hex(),
+		Code:      data.Code,
+		MaxUses:   data.MaxUses,
+		UsedCount: data.UsedCount,
+		Revoked:   data.Revoked,
+		CreatedAt: data.CreatedAt.Unix(),
+		ExpiresAt: data.ExpiresAt.Unix(),
+	}
+}
+
+// InviteCodeListResponse 邀请码列表响应
+type InviteCodeListResponse struct {
+	InviteCodes []InviteCodeResponse `json:"invite_codes"` // 邀请码列表
+}
+
+/*
+NewInviteCodeListResponse 创建邀请码列表响应
+
+参数：
+  - data：邀请码列表
+
+返回：
+  - InviteCodeListResponse：邀请码列表响应
+*/
+func NewInviteCodeListResponse(data []models.InviteCode) InviteCodeListResponse {
+	inviteCodes := make([]InviteCodeResponse, 0, len(data))
+	for _, invite := range data {
+		inviteCodes = append(inviteCodes, NewInviteCodeResponse(invite))
+	}
+
+	return InviteCodeListResponse{InviteCodes: inviteCodes}
+}
+
+// InviteeListResponse 受邀用户列表响应
+type InviteeListResponse struct {
+	Users      []UserProfileResponse `json:"users"`                 // 用户列表
+	NextCursor string                `json:"next_cursor,omitempty"` // 下一页游标
+}
+
+/*
+NewInviteeListResponse 创建受邀用户列表响应
+
+参数：
+  - data：用户信息列表
+  - limit：分页大小 返回数量达到分页大小时才生成下一页游标
+
+返回：
+  - InviteeListResponse：受邀用户列表响应
+*/
+func NewInviteeListResponse(data []models.UserInfo, limit int64) InviteeListResponse {
+	users := make([]UserProfileResponse, 0, len(data))
+	for _, user := range data {
+		users = append(users, NewUserProfileResponse(user))
+	}
+
+	response := InviteeListResponse{Users: users}
+	if len(data) > 0 && int64(len(data)) == limit {
+		response.NextCursor = data[len(data)-1].ID.Hex()
+	}
+
+	return response
+}
@@ -0,0 +1,71 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化媒体文件信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"zewise.space/backend/consts"
+	"zewise.space/backend/models"
+	"zewise.space/backend/utils/functools"
+)
+
+// MediaURLResponse 媒体文件地址响应
+type MediaURLResponse struct {
+	ID           string `json:"id"`            // 媒体ID
+	URL          string `json:"url"`           // 展示图地址
+	ThumbnailURL string `json:"thumbnail_url"` // 缩略图地址
+}
+
+/*
+NewMediaURLResponse 创建媒体文件地址响应
+
+参数：
+  - mediaID：媒体ID
+
+返回：
+  - MediaURLResponse：媒体文件地址响应
+*/
+func NewMediaURLResponse(mediaID primitive.ObjectID) MediaURLResponse {
+	return MediaURLResponse{
+		ID:           mediaID.Hex(),
+		URL:          functools.JoinStrings(consts.MEDIA_URL_PREFIX, mediaID.Hex(), ".webp"),
+		ThumbnailURL: functools.JoinStrings(consts.MEDIA_URL_PREFIX, mediaID.Hex(), consts.MEDIA_THUMBNAIL_SUFFIX, ".webp"),
+	}
+}
+
+// MediaResponse 媒体文件信息响应
+type MediaResponse struct {
+	MediaURLResponse
+	MimeType        string `json:"mime_type"`        // 文件类型
+	Width           int    `json:"width"`            // 展示图宽度
+	Height          int    `json:"height"`           // 展示图高度
+	Size            int64  `json:"size"`             // 展示图大小
+	ThumbnailWidth  int    `json:"thumbnail_width"`  // 缩略图宽度
+	ThumbnailHeight int    `json:"thumbnail_height"` // 缩略图高度
+}
+
+/*
+NewMediaResponse 创建媒体文件信息响应
+
+参数：
+  - data：媒体文件信息
+
+返回：
+  - MediaResponse：媒体文件信息响应
+*/
+func NewMediaResponse(data models.MediaInfo) MediaResponse {
+	return MediaResponse{
+		MediaURLResponse: NewMediaURLResponse(data.ID),
+		MimeType:         data.MimeType,
+		Width:            data.Width,
+		Height:           data.Height,
+		Size:             data.Size,
+		ThumbnailWidth:   data.ThumbnailWidth,
+		ThumbnailHeight:  data.ThumbnailHeight,
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化博文信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"zewise.space/backend/models"
+)
+
+const (
+	// POST_KIND_ORIGINAL 原创博文
+	POST_KIND_ORIGINAL = "original"
+
+	// POST_KIND_REPOST 直接转发
+	POST_KIND_REPOST = "repost"
+
+	// POST_KIND_QUOTE 引用转发
+	POST_KIND_QUOTE = "quote"
+)
+
+// PostResponse 博文信息响应
+type PostResponse struct {
+	ID                string             `json:"id"`                           // 博文ID
+	UID               string             `json:"uid"`                          // 作者ID
+	Kind              string             `json:"kind"`                         // 博文类型
+	Title             string             `json:"title,omitempty"`              // 标题
+	Content           string             `json:"content,omitempty"`            // 内容
+	Media             []MediaURLResponse `json:"media,omitempty"`              // 媒体文件
+	IsPublic          bool               `json:"is_public"`                    // 是否公开
+	RepostCount       int64              `json:"repost_count"`                 // 转发数
+	ParentPostID      string             `json:"parent_post_id,omitempty"`     // 被转发的博文ID
+	ParentPost        *PostResponse      `json:"parent_post,omitempty"`        // 被转发的博文
+	ParentUnavailable bool               `json:"parent_unavailable,omitempty"` // 被转发的博文已删除或不可见
+	CreatedAt         int64              `json:"created_at"`                   // 创建时间
+	UpdatedAt         int64              `json:"updated_at,omitempty"`         // 更新时间
+}
+
+/*
+NewPostResponse 创建博文信息响应
+
+参数：
+  - data：博文信息
+
+返回：
+  - PostResponse：博文信息响应
+*/
+func NewPostResponse(data models.PostInfo) PostResponse {
+	response := PostResponse{
+		ID:          data.ID.Hex(),
+		UID:         data.UID.Hex(),
+		Kind:        POST_KIND_ORIGINAL,
+		Title:       data.Title,
+		Content:     data.Content,
+		IsPublic:    data.IsPublic,
+		RepostCount: data.RepostCount,
+		CreatedAt:   data.CreatedAt.Unix(),
+		UpdatedAt:   data.UpdatedAt.Unix(),
+	}
+	for _, mediaID := range data.MediaIDs {
+		response.Media = append(response.Media, NewMediaURLResponse(mediaID))
+	}
+	if !data.ParentPostID.IsZero() {
+		response.ParentPostID = data.ParentPostID.Hex()
+		if data.Content == "" {
+			response.Kind = POST_KIND_REPOST
+		} else {
+			response.Kind = POST_KIND_QUOTE
+		}
+	}
+
+	return response
+}
+
+/*
+NewPostResponseWithParent 创建附带原博文的博文信息响应
+
+参数：
+  - data：博文信息
+  - parents：原博文ID到原博文的映射 缺失的原博文视为已删除或不可见
+
+返回：
+  - PostResponse：博文信息响应
+*/
+func NewPostResponseWithParent(data models.PostInfo, parents map[primitive.ObjectID]models.PostInfo) PostResponse {
+	response := NewPostResponse(data)
+	if data.ParentPostID.IsZero() {
+		return response
+	}
+
+	if parent, ok := parents[data.ParentPostID]; ok {
+		parentResponse := NewPostResponse(parent)
+		response.ParentPost = &parentResponse
+	} else {
+		response.ParentUnavailable = true
+	}
+
+	return response
+}
+
+// PostListResponse 博文列表响应
+type PostListResponse struct {
+	Posts      []PostResponse `json:"posts"`                 // 博文列表
+	NextCursor string         `json:"next_cursor,omitempty"` // 下一页游标
+}
+
+/*
+NewPostListResponse 创建博文列表响应
+
+参数：
+  - data：博文列表
+  - parents：原博文ID到原博文的映射
+  - limit：分页大小 返回数量达到分页大小时才生成下一页游标
+
+返回：
+  - PostListResponse：博文列表响应
+*/
+func NewPostListResponse(data []models.PostInfo, parents map[primitive.ObjectID]models.PostInfo, limit int64) PostListResponse {
+	posts := make([]PostResponse, 0, len(data))
+	for _, post := range data {
+		posts = append(posts, NewPostResponseWithParent(post, parents))
+	}
+
+	response := PostListResponse{Posts: posts}
+	if len(data) > 0 && int64(len(data)) == limit {
+		response.NextCursor = data[len(data)-1].ID.Hex()
+	}
+
+	return response
+}
@@ -0,0 +1,38 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化时间线信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"zewise.space/backend/models"
+)
+
+/*
+NewTimelineResponse 创建时间线响应 时间线中已删除的博文会被过滤 因此游标由服务层给出
+
+参数：
+  - data：博文列表
+  - parents：原博文ID到原博文的映射
+  - nextCursor：下一页游标 为空时表示没有更多博文
+
+返回：
+  - PostListResponse：博文列表响应
+*/
+func NewTimelineResponse(data []models.PostInfo, parents map[primitive.ObjectID]models.PostInfo, nextCursor primitive.ObjectID) PostListResponse {
+	posts := make([]PostResponse, 0, len(data))
+	for _, post := range data {
+		posts = append(posts, NewPostResponseWithParent(post, parents))
+	}
+
+	response := PostListResponse{Posts: posts}
+	if !nextCursor.IsZero() {
+		response.NextCursor = nextCursor.Hex()
+	}
+
+	return response
+}
@@ -0,0 +1,93 @@
+/*
+Package serializers - ZeWise 序列化器包
+该文件用于序列化个人访问令牌信息
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package serializers
+
+import (
+	"zewise.space/backend/models"
+)
+
+// PersonalAccessTokenResponse 个人访问令牌响应 不包含令牌明文
+type PersonalAccessTokenResponse struct {
+	ID         string   `json:"id"`                     // 令牌ID
+	Name       string   `json:"name"`                   // 令牌名称
+	Hint       string   `json:"hint"`                   // 令牌末尾字符
+	Scopes     []string `json:"scopes"`                 // 权限范围
+	CreatedAt  int64    `json:"created_at"`             // 创建时间
+	ExpiresAt  int64    `json:"expires_at"`             // 过期时间
+	LastUsedAt int64    `json:"last_used_at,omitempty"` // 最后使用时间
+}
+
+/*
+NewPersonalAccessTokenResponse 创建个人访问令牌响应
+
+参数：
+  - data：令牌信息
+
+返回：
+  - PersonalAccessTokenResponse：个人访问令牌响应
+*/
+func NewPersonalAccessTokenResponse(data models.PersonalAccessTokenInfo) PersonalAccessTokenResponse {
+	response := PersonalAccessTokenResponse{
+		ID:        data.ID.Hex(),
+		Name:      data.Name,
+		Hint:      data.Hint,
+		Scopes:    data.Scopes,
+		CreatedAt: data.CreatedAt.Unix(),
+		ExpiresAt: data.ExpiresAt.Unix(),
+	}
+	if !data.LastUsedAt.IsZero() {
+		response.LastUsedAt = data.LastUsedAt.Unix()
+	}
+
+	return response
+}
+
+// PersonalAccessTokenCreatedResponse 创建个人访问令牌响应 令牌明文仅返回一次
+type PersonalAccessTokenCreatedResponse struct {
+	PersonalAccessTokenResponse
+	Token string `json:"token"` // 令牌明文
+}
+
+/*
+NewPersonalAccessTokenCreatedResponse 创建个人访问令牌创建结果响应
+
+参数：
+  - token：令牌明文
+  - data：令牌信息
+
+返回：
+  - PersonalAccessTokenCreatedResponse：创建结果响应
+*/
+func NewPersonalAccessTokenCreatedResponse(token string, data models.PersonalAccessTokenInfo) PersonalAccessTokenCreatedResponse {
+	return PersonalAccessTokenCreatedResponse{
+		PersonalAccessTokenResponse: NewPersonalAccessTokenResponse(data),
+		Token:                       token,
+	}
+}
+
+// PersonalAccessTokenListResponse 个人访问令牌列表响应
+type PersonalAccessTokenListResponse struct {
+	Tokens []PersonalAccessTokenResponse `json:"tokens"` // 令牌列表
+}
+
+/*
+NewPersonalAccessTokenListResponse 创建个人访问令牌列表响应
+
+参数：
+  - data：令牌列表
+
+返回：
+  - PersonalAccessTokenListResponse：个人访问令牌列表响应
+*/
+func NewPersonalAccessTokenListResponse(data []models.PersonalAccessTokenInfo) PersonalAccessTokenListResponse {
+	tokens := make([]PersonalAccessTokenResponse, 0, len(data))
+	for _, token := range data {
+		tokens = append(tokens, NewPersonalAccessTokenResponse(token))
+	}
+
+	return PersonalAccessTokenListResponse{Tokens: tokens}
+}
@@ -25,6 +25,12 @@ const (
 	// NETWORK_ERROR 网络错误
 	NETWORK_ERROR ResponseCode = 4
 
+	// ACCOUNT_SUSPENDED 账号已被暂停使用或封禁
+	ACCOUNT_SUSPENDED ResponseCode = 5
+
+	// CAPTCHA_REQUIRED 需要通过图片验证码 客户端应获取新的验证码后重试
+	CAPTCHA_REQUIRED ResponseCode = 6
+
 	// UNKNOWN_ERROR 未知错误
 	UNKNOWN_ERROR ResponseCode = -1
 )
@@ -23,6 +23,11 @@ type UserProfileResponse struct {
 	Birth    int64  `json:"birth,omitempty"`    // 生日
 	Gender   string `json:"gender,omitempty"`   // 性别
 	Level    uint64 `json:"level,omitempty"`    // 等级
+	Role     string `json:"role"`               // 角色
+
+	EmailVerified  bool  `json:"email_verified"`  // 邮箱是否已验证
+	FollowerCount  int64 `json:"follower_count"`  // 粉丝数
+	FollowingCount int64 `json:"following_count"` // 关注数
 }
 
 /*
@@ -39,5 +44,10 @@ func NewUserProfileResponse(data models.UserInfo) UserProfileResponse {
 		Birth:    data.Birth.Unix(),
 		Gender:   data.Gender,
 		Level:    data.Level,
+		Role:     consts.ROLE_NAMES[data.Authority],
+
+		EmailVerified:  data.EmailVerified,
+		FollowerCount:  data.FollowerCount,
+		FollowingCount: data.FollowingCount,
 	}
 }
@@ -0,0 +1,85 @@
+/*
+Package signers - ZeWise 后端服务器令牌签名包
+该文件用于生成 JWKS 公钥集合
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package signers
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK JSON Web Key 公钥 参见 RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`           // 密钥类型
+	Use string `json:"use"`           // 用途
+	Alg string `json:"alg"`           // 算法
+	KID string `json:"kid"`           // 密钥 ID
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 指数
+	Crv string `json:"crv,omitempty"` // 曲线
+	X   string `json:"x,omitempty"`   // 公钥 X 坐标
+	Y   string `json:"y,omitempty"`   // 公钥 Y 坐标
+}
+
+// JWKSet JSON Web Key 集合
+type JWKSet struct {
+	Keys []JWK `json:"keys"` // 公钥列表
+}
+
+/*
+JWKS 生成公钥集合 仅包含非对称密钥 对称密钥不会公开
+
+返回：
+  - JWKSet：公钥集合
+*/
+func (set *KeySet) JWKS() JWKSet {
+	result := JWKSet{Keys: []JWK{}}
+	for _, kid := range set.order {
+		key := set.keys[kid]
+		if key.IsSymmetric() {
+			continue
+		}
+
+		jwk := JWK{Use: "sig", Alg: key.Method.Alg(), KID: key.KID}
+		switch publicKey := key.VerifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeBase64URL(publicKey.N.Bytes())
+			jwk.E = encodeBase64URL(big.NewInt(int64(publicKey.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (publicKey.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = publicKey.Curve.Params().Name
+			jwk.X = encodeBase64URL(publicKey.X.FillBytes(make([]byte, size)))
+			jwk.Y = encodeBase64URL(publicKey.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = encodeBase64URL(publicKey)
+		default:
+			continue
+		}
+		result.Keys = append(result.Keys, jwk)
+	}
+
+	return result
+}
+
+/*
+encodeBase64URL 以无填充的 base64url 编码字节
+
+参数：
+  - data：字节序列
+
+返回：
+  - string：编码结果
+*/
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
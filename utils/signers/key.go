@@ -0,0 +1,197 @@
+/*
+Package signers - ZeWise 后端服务器令牌签名包
+该文件用于加载令牌签名密钥
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package signers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// HMAC_SECRET_MIN_LENGTH HS256 密钥最小长度
+const HMAC_SECRET_MIN_LENGTH = 32
+
+// KeyConfig 签名密钥配置
+type KeyConfig struct {
+	// 密钥 ID 写入令牌头部的 kid
+	KID string `toml:"kid" mapstructure:"kid"`
+	// 签名算法 HS256, RS256, ES256, EdDSA
+	Algorithm string `toml:"algorithm" mapstructure:"algorithm"`
+	// PEM 格式私钥文件路径
+	PrivateKeyFile string `toml:"private_key_file" mapstructure:"private_key_file"`
+	// 存放 PEM 格式私钥的环境变量名 优先于私钥文件
+	PrivateKeyEnv string `toml:"private_key_env" mapstructure:"private_key_env"`
+	// PEM 格式公钥文件路径 未提供私钥时该密钥仅用于验证
+	PublicKeyFile string `toml:"public_key_file" mapstructure:"public_key_file"`
+	// 存放 HS256 密钥的环境变量名
+	SecretEnv string `toml:"secret_env" mapstructure:"secret_env"`
+}
+
+// Key 签名密钥
+type Key struct {
+	KID       string            // 密钥 ID
+	Method    jwt.SigningMethod // 签名算法
+	SignKey   interface{}       // 签名密钥 为空时仅用于验证
+	VerifyKey interface{}       // 验证密钥
+}
+
+/*
+CanSign 判断密钥是否可用于签名
+
+返回：
+  - bool：是否可用于签名
+*/
+func (key *Key) CanSign() bool {
+	return key.SignKey != nil
+}
+
+/*
+IsSymmetric 判断密钥是否为对称密钥 对称密钥不会公开
+
+返回：
+  - bool：是否为对称密钥
+*/
+func (key *Key) IsSymmetric() bool {
+	_, ok := key.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+/*
+LoadKey 按配置加载签名密钥
+
+参数：
+  - config：签名密钥配置
+
+返回：
+  - *Key：签名密钥
+  - error：错误信息
+*/
+func LoadKey(config KeyConfig) (*Key, error) {
+	if config.KID == "" {
+		return nil, errors.New("签名密钥缺少 kid")
+	}
+
+	key := &Key{KID: config.KID}
+
+	// 对称密钥
+	if config.Algorithm == jwt.SigningMethodHS256.Alg() {
+		secret := os.Getenv(config.SecretEnv)
+		if len(secret) < HMAC_SECRET_MIN_LENGTH {
+			return nil, fmt.Errorf("签名密钥 %s 的 HS256 密钥长度不能少于 %d", config.KID, HMAC_SECRET_MIN_LENGTH)
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.SignKey = []byte(secret)
+		key.VerifyKey = []byte(secret)
+		return key, nil
+	}
+
+	// 非对称密钥
+	privatePEM, err := readPEM(config.PrivateKeyEnv, config.PrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取签名密钥 %s 的私钥失败: %w", config.KID, err)
+	}
+	var publicPEM []byte
+	if privatePEM == nil {
+		publicPEM, err = readPEM("", config.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取签名密钥 %s 的公钥失败: %w", config.KID, err)
+		}
+		if publicPEM == nil {
+			return nil, fmt.Errorf("签名密钥 %s 未提供私钥或公钥", config.KID)
+		}
+	}
+
+	switch config.Algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+		if privatePEM != nil {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.SignKey, key.VerifyKey = privateKey, &privateKey.PublicKey
+		} else {
+			key.VerifyKey, err = jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+		}
+
+	case jwt.SigningMethodES256.Alg():
+		key.Method = jwt.SigningMethodES256
+		var publicKey *ecdsa.PublicKey
+		if privatePEM != nil {
+			privateKey, err := jwt.ParseECPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			key.SignKey, publicKey = privateKey, &privateKey.PublicKey
+		} else {
+			publicKey, err = jwt.ParseECPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if publicKey.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("签名密钥 %s 必须使用 P-256 曲线", config.KID)
+		}
+		key.VerifyKey = publicKey
+
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+		if privatePEM != nil {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(privatePEM)
+			if err != nil {
+				return nil, err
+			}
+			signer, ok := privateKey.(ed25519.PrivateKey)
+			if !ok {
+				return nil, fmt.Errorf("签名密钥 %s 不是 Ed25519 私钥", config.KID)
+			}
+			key.SignKey, key.VerifyKey = signer, signer.Public()
+		} else {
+			var publicKey crypto.PublicKey
+			publicKey, err = jwt.ParseEdPublicKeyFromPEM(publicPEM)
+			key.VerifyKey = publicKey
+		}
+
+	default:
+		return nil, fmt.Errorf("签名密钥 %s 使用了不支持的算法 %s", config.KID, config.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+/*
+readPEM 从环境变量或文件读取 PEM 内容 均未配置时返回空
+
+参数：
+  - env：环境变量名
+  - file：文件路径
+
+返回：
+  - []byte：PEM 内容
+  - error：错误信息
+*/
+func readPEM(env string, file string) ([]byte, error) {
+	if env != "" {
+		if value := os.Getenv(env); value != "" {
+			return []byte(value), nil
+		}
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,122 @@
+/*
+Package signers - ZeWise 后端服务器令牌签名包
+该文件用于管理签名密钥集合与密钥轮换
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package signers
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrKeySetNotReady 签名密钥集合未初始化
+var ErrKeySetNotReady = errors.New("令牌签名密钥未初始化")
+
+// KeySet 签名密钥集合
+// 当前密钥用于签发令牌 其余密钥在轮换过渡期内仍用于验证旧令牌
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+	order  []string
+}
+
+// defaultKeySet 全局签名密钥集合
+var defaultKeySet *KeySet
+
+/*
+NewKeySet 创建签名密钥集合
+
+参数：
+  - activeKID：当前用于签名的密钥 ID
+  - configs：签名密钥配置列表
+
+返回：
+  - *KeySet：签名密钥集合
+  - error：错误信息
+*/
+func NewKeySet(activeKID string, configs []KeyConfig) (*KeySet, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("未配置令牌签名密钥")
+	}
+
+	set := &KeySet{keys: make(map[string]*Key, len(configs))}
+	for _, config := range configs {
+		key, err := LoadKey(config)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := set.keys[key.KID]; ok {
+			return nil, fmt.Errorf("签名密钥 %s 重复", key.KID)
+		}
+		set.keys[key.KID] = key
+		set.order = append(set.order, key.KID)
+	}
+
+	// 设置当前密钥
+	active, ok := set.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("当前签名密钥 %s 不存在", activeKID)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("当前签名密钥 %s 缺少私钥", activeKID)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+/*
+Active 获取当前用于签名的密钥
+
+返回：
+  - *Key：签名密钥
+*/
+func (set *KeySet) Active() *Key {
+	return set.active
+}
+
+/*
+Lookup 按密钥 ID 查找验证密钥 未提供密钥 ID 时使用当前密钥
+
+参数：
+  - kid：密钥 ID
+
+返回：
+  - *Key：签名密钥
+  - bool：是否存在
+*/
+func (set *KeySet) Lookup(kid string) (*Key, bool) {
+	if kid == "" {
+		return set.active, true
+	}
+
+	key, ok := set.keys[kid]
+	return key, ok
+}
+
+/*
+Setup 设置全局签名密钥集合
+
+参数：
+  - set：签名密钥集合
+*/
+func Setup(set *KeySet) {
+	defaultKeySet = set
+}
+
+/*
+Default 获取全局签名密钥集合
+
+返回：
+  - *KeySet：签名密钥集合
+  - error：错误信息
+*/
+func Default() (*KeySet, error) {
+	if defaultKeySet == nil {
+		return nil, ErrKeySetNotReady
+	}
+
+	return defaultKeySet, nil
+}
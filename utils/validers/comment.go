@@ -0,0 +1,30 @@
+/*
+Package validers - ZeWise 工具函数包
+该文件用于定义评论验证器函数
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package validers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"zewise.space/backend/consts"
+)
+
+/*
+IsValidCommentContent 验证评论或回复内容是否合法
+
+参数：
+  - content：内容
+
+返回：
+  - bool：是否合法
+*/
+func IsValidCommentContent(content string) bool {
+	if strings.TrimSpace(content) == "" {
+		return false
+	}
+	return utf8.RuneCountInString(content) <= consts.COMMENT_CONTENT_MAX_LENGTH
+}
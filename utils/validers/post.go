@@ -0,0 +1,43 @@
+/*
+Package validers - ZeWise 工具函数包
+该文件用于定义博文验证器函数
+Copyright (c) [2024], Author(s):
+- WhitePaper233<baizhiwp@gmail.com>
+*/
+package validers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"zewise.space/backend/consts"
+)
+
+/*
+IsValidPostTitle 验证博文标题是否合法
+
+参数：
+  - title：标题
+
+返回：
+  - bool：是否合法
+*/
+func IsValidPostTitle(title string) bool {
+	return utf8.RuneCountInString(title) <= consts.POST_TITLE_MAX_LENGTH
+}
+
+/*
+IsValidPostContent 验证博文内容是否合法
+
+参数：
+  - content：内容
+
+返回：
+  - bool：是否合法
+*/
+func IsValidPostContent(content string) bool {
+	if strings.TrimSpace(content) == "" {
+		return false
+	}
+	return utf8.RuneCountInString(content) <= consts.POST_CONTENT_MAX_LENGTH
+}